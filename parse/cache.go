@@ -0,0 +1,48 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+
+	"code.google.com/p/go.text/unicode/norm"
+)
+
+// Cache stores parse trees by key. ParseCached uses a Cache to avoid parsing
+// the same input again. Implementations must be safe for use by the callers of
+// ParseCached, for example by using a mutex if ParseCached is called from
+// several goroutines.
+type Cache interface {
+	// Get returns the tree stored for key and true, or false if there is
+	// no tree for key.
+	Get(key string) (*Tree, bool)
+
+	// Set stores the tree t for key.
+	Set(key string, t *Tree)
+}
+
+// ParseCached is like Parse, but the parse tree is looked up in cache using a
+// hash of the NFC normalized text as the key. If the tree is not found, the
+// text is parsed and a copy of the tree is stored in cache. The trees returned
+// by ParseCached are always copies made with Tree.Clone, so changes made to a
+// returned tree do not affect the cache.
+func ParseCached(cache Cache, name, text string) (t *Tree, errors NodeList) {
+	key := cacheKey(text)
+	if ct, ok := cache.Get(key); ok {
+		t = ct.Clone()
+		t.Name = name
+		return t, t.Messages
+	}
+	t, errors = Parse(name, text)
+	cache.Set(key, t.Clone())
+	return
+}
+
+// cacheKey returns the hex encoded SHA-1 hash of the NFC normalized text.
+func cacheKey(text string) string {
+	sum := sha1.Sum(norm.NFC.Bytes([]byte(text)))
+	return hex.EncodeToString(sum[:])
+}
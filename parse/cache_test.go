@@ -0,0 +1,67 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"reflect"
+	"testing"
+)
+
+// mapCache is a Cache that counts the number of cache hits.
+type mapCache struct {
+	trees map[string]*Tree
+	hits  int
+}
+
+func (m *mapCache) Get(key string) (*Tree, bool) {
+	t, ok := m.trees[key]
+	if ok {
+		m.hits++
+	}
+	return t, ok
+}
+
+func (m *mapCache) Set(key string, t *Tree) { m.trees[key] = t }
+
+func TestParseCached(t *testing.T) {
+	cache := &mapCache{trees: make(map[string]*Tree)}
+	input := "Title\n===\n\nParagraph."
+	first, _ := ParseCached(cache, "first", input)
+	if cache.hits != 0 {
+		t.Errorf("Got: hits = %d, Expect: 0", cache.hits)
+	}
+	second, errors := ParseCached(cache, "second", input)
+	if cache.hits != 1 {
+		t.Errorf("Got: hits = %d, Expect: 1", cache.hits)
+	}
+	if second.Name != "second" {
+		t.Errorf("Got: Name = %q, Expect: %q", second.Name, "second")
+	}
+	if !reflect.DeepEqual(first.Nodes, second.Nodes) ||
+		!reflect.DeepEqual(first.Messages, errors) {
+		t.Fatal("Got: cached tree is not equal to the parsed tree")
+	}
+	sec1 := first.Nodes[0].(*SectionNode)
+	sec2 := second.Nodes[0].(*SectionNode)
+	if sec1 == sec2 || sec1.Title == sec2.Title {
+		t.Error("Got: cached tree shares nodes with the parsed tree")
+	}
+	// System messages are shared between the nodes and the messages of a
+	// tree, and must be shared in the copy as well.
+	if sec2.NodeList[0] != second.Messages[0] ||
+		second.Diagnostics[0].Node != second.Messages[0] {
+		t.Error("Got: system message copied more than once")
+	}
+
+	sec2.Title.Text = "Changed"
+	third, _ := ParseCached(cache, "third", input)
+	if text := third.Nodes[0].(*SectionNode).Title.Text; text != "Title" {
+		t.Errorf("Got: Title.Text = %q, Expect: %q", text, "Title")
+	}
+	ParseCached(cache, "other", "Other input.")
+	if cache.hits != 2 {
+		t.Errorf("Got: hits = %d, Expect: 2", cache.hits)
+	}
+}
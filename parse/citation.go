@@ -0,0 +1,16 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "strings"
+
+// citation returns the CitationNode of the citation at item i with label and
+// body text. The normalized label is the name of the citation.
+func (t *Tree) citation(label, body string, i *item) Node {
+	n := newCitation(i, label, &t.id)
+	n.Names = append(n.Names, strings.ToLower(label))
+	n.NodeList = t.bracketBody(body, i)
+	return n
+}
@@ -0,0 +1,76 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "strings"
+
+// className converts name to a class name like the docutils make_id function.
+// The name is lowercased and runs of characters other than ASCII letters and
+// digits are replaced by a hyphen. Leading digits and hyphens are removed.
+func className(name string) string {
+	var buf []rune
+	sep := false
+	for _, r := range strings.ToLower(name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			if sep && len(buf) > 0 {
+				buf = append(buf, '-')
+			}
+			buf = append(buf, r)
+			sep = false
+		} else {
+			sep = true
+		}
+	}
+	return strings.TrimLeft(string(buf), "0123456789-")
+}
+
+// classNames converts the names in args to class names. Names that do not
+// contain a valid class name are skipped.
+func classNames(args []string) (classes []string) {
+	for _, arg := range args {
+		if name := className(arg); name != "" {
+			classes = append(classes, name)
+		}
+	}
+	return
+}
+
+// classDirective handles the class directive i with arguments args. The class
+// names are added to the next body element by applyPendingClasses. nil is
+// returned, or a system message if there are no valid class names.
+func (t *Tree) classDirective(args []string, i *item) Node {
+	classes := classNames(args)
+	if len(classes) == 0 {
+		return t.itemMessage(errorClassDirectiveArgument, i)
+	}
+	t.pendingClasses = append(t.pendingClasses, classes...)
+	t.pendingClassItem = i
+	t.pendingClassTarget = t.nodeTarget
+	return nil
+}
+
+// applyPendingClasses adds the classes of a preceding class directive to the
+// attributes of n. Comments, system messages, and blank lines are skipped,
+// the classes are added to the next body element.
+func (t *Tree) applyPendingClasses(n Node) {
+	if len(t.pendingClasses) == 0 || isPreBibliographic(n) {
+		return
+	}
+	a := n.Attrs()
+	a.Classes = append(a.Classes, t.pendingClasses...)
+	t.pendingClasses = nil
+}
+
+// pendingClassesAtEnd adds an errorNoElementFollowingClassDirective system
+// message after the class directive if no element follows it.
+func (t *Tree) pendingClassesAtEnd() {
+	if len(t.pendingClasses) == 0 {
+		return
+	}
+	m := t.itemMessage(errorNoElementFollowingClassDirective,
+		t.pendingClassItem)
+	t.pendingClassTarget.append(m)
+	t.pendingClasses = nil
+}
@@ -0,0 +1,106 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"reflect"
+	"testing"
+)
+
+var classDirectiveTests = []struct {
+	name    string
+	input   string
+	types   []NodeType    // The expected types of the top level nodes
+	classes []string      // The expected classes of the last node
+	message parserMessage // The expected system message
+}{
+	{
+		name:    "Class before paragraph",
+		input:   ".. class:: special\n\nParagraph.",
+		types:   []NodeType{NodeParagraph},
+		classes: []string{"special"},
+	},
+	{
+		name:    "Classes before section",
+		input:   ".. class:: Special Two_Words\n\nTitle\n=====",
+		types:   []NodeType{NodeSection},
+		classes: []string{"special", "two-words"},
+	},
+	{
+		name:    "Class skips comments",
+		input:   ".. class:: special\n\n.. A comment\n\nParagraph.",
+		types:   []NodeType{NodeComment, NodeParagraph},
+		classes: []string{"special"},
+	},
+	{
+		name:    "Class without a following element",
+		input:   "Paragraph.\n\n.. class:: special",
+		types:   []NodeType{NodeParagraph, NodeSystemMessage},
+		message: errorNoElementFollowingClassDirective,
+	},
+	{
+		name:    "Class without arguments",
+		input:   ".. class::\n\nParagraph.",
+		types:   []NodeType{NodeSystemMessage, NodeParagraph},
+		message: errorClassDirectiveArgument,
+	},
+}
+
+func TestParseClassDirective(t *testing.T) {
+	for _, tt := range classDirectiveTests {
+		tr, errors := Parse(tt.name, tt.input)
+		if len(tr.Nodes) != len(tt.types) {
+			t.Errorf("Test: %q\n\t    Got: len(Nodes) = %d, "+
+				"Expect: %d\n\n", tt.name, len(tr.Nodes),
+				len(tt.types))
+			continue
+		}
+		for num, n := range tr.Nodes {
+			if n.NodeType() != tt.types[num] {
+				t.Errorf("Test: %q\n\t    Got: Nodes[%d] = %s, "+
+					"Expect: %s\n\n", tt.name, num,
+					n.NodeType(), tt.types[num])
+			}
+		}
+		if tt.classes != nil {
+			classes := tr.Nodes[len(tr.Nodes)-1].Attrs().Classes
+			if !reflect.DeepEqual(classes, tt.classes) {
+				t.Errorf("Test: %q\n\t    Got: Classes = %v, "+
+					"Expect: %v\n\n", tt.name, classes,
+					tt.classes)
+			}
+		}
+		if tt.message == parserMessageNil {
+			if len(errors) != 0 {
+				t.Errorf("Test: %q\n\t    Got: len(errors) = %d, "+
+					"Expect: 0\n\n", tt.name, len(errors))
+			}
+			continue
+		}
+		if len(errors) != 1 {
+			t.Errorf("Test: %q\n\t    Got: len(errors) = %d, "+
+				"Expect: 1\n\n", tt.name, len(errors))
+		} else if m := errors[0].(*SystemMessageNode); m.MessageType != tt.message {
+			t.Errorf("Test: %q\n\t    Got: MessageType = %s, "+
+				"Expect: %s\n\n", tt.name, m.MessageType, tt.message)
+		}
+	}
+}
+
+func TestClassName(t *testing.T) {
+	for name, expect := range map[string]string{
+		"special":     "special",
+		"Two_Words":   "two-words",
+		"--a..b--":    "a-b",
+		"1st-class":   "st-class",
+		"!!!":         "",
+		"mixed CASE1": "mixed-case1",
+	} {
+		if got := className(name); got != expect {
+			t.Errorf("Got: className(%q) = %q, Expect: %q", name,
+				got, expect)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "reflect"
+
+// Clone returns a deep copy of the parse tree. The nodes, messages,
+// diagnostics, and options of the copy can be changed without affecting t.
+// Nodes shared between Tree.Nodes and Tree.Messages remain shared in the copy.
+// The copy only contains the results of the parse, it can not be used to
+// continue parsing.
+func (t *Tree) Clone() *Tree {
+	c := &nodeCloner{clones: make(map[Node]Node)}
+	n := &Tree{
+		Name:     t.Name,
+		Nodes:    c.nodeList(t.Nodes),
+		Messages: c.nodeList(t.Messages),
+		Fset:     t.Fset,
+		Halted:   t.Halted,
+		Title:    c.titleNode(t.Title),
+		Subtitle: c.titleNode(t.Subtitle),
+		text:     t.text,
+		id:       t.id,
+	}
+	if t.Options != nil {
+		opts := *t.Options
+		if t.Options.AdornmentChars != nil {
+			opts.AdornmentChars = append([]rune(nil),
+				t.Options.AdornmentChars...)
+		}
+		if t.Options.Directives != nil {
+			opts.Directives = append([]DirectiveHandler(nil),
+				t.Options.Directives...)
+		}
+		if t.Options.Roles != nil {
+			opts.Roles = append([]RoleHandler(nil), t.Options.Roles...)
+		}
+		n.Options = &opts
+	}
+	for _, r := range t.FootnoteReferences {
+		nr := *r
+		if r.Footnote != nil {
+			nr.Footnote = c.node(r.Footnote).(*FootnoteNode)
+		}
+		n.FootnoteReferences = append(n.FootnoteReferences, &nr)
+	}
+	if t.Names != nil {
+		n.Names = make(map[string]Node, len(t.Names))
+		for name, nn := range t.Names {
+			n.Names[name] = c.node(nn)
+		}
+	}
+	for _, d := range t.Diagnostics {
+		nd := *d
+		if d.Node != nil {
+			nd.Node = c.node(d.Node).(*SystemMessageNode)
+		}
+		n.Diagnostics = append(n.Diagnostics, &nd)
+	}
+	return n
+}
+
+// nodeCloner makes deep copies of nodes. Each node is copied once, so nodes
+// referenced from several places are still shared after copying.
+type nodeCloner struct {
+	clones map[Node]Node // Copies of the nodes that have been cloned
+}
+
+func (c *nodeCloner) nodeList(nl NodeList) NodeList {
+	if nl == nil {
+		return nil
+	}
+	cl := make(NodeList, len(nl))
+	for num, n := range nl {
+		cl[num] = c.node(n)
+	}
+	return cl
+}
+
+// node returns a deep copy of n. The fields of n are copied with reflection,
+// see value.
+func (c *nodeCloner) node(n Node) Node {
+	if n == nil || reflect.ValueOf(n).IsNil() {
+		return n
+	}
+	if cl, ok := c.clones[n]; ok {
+		return cl
+	}
+	src := reflect.ValueOf(n).Elem()
+	dst := reflect.New(src.Type())
+	dst.Elem().Set(src)
+	cl := dst.Interface().(Node)
+	c.clones[n] = cl
+	for i := 0; i < src.NumField(); i++ {
+		if f := dst.Elem().Field(i); f.CanSet() {
+			f.Set(c.value(src.Field(i)))
+		}
+	}
+	return cl
+}
+
+// value returns a deep copy of v. Nodes are copied with node, so a node
+// referenced from several places is copied once. Slices, maps, structs, and
+// the values pointed to are copied recursively, so the copy shares no mutable
+// data with v, like the Options of a DirectiveNode or the BackRefs of a
+// FootnoteNode.
+func (c *nodeCloner) value(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.New(v.Type()).Elem()
+		if n, ok := v.Interface().(Node); ok {
+			cp.Set(reflect.ValueOf(c.node(n)))
+			return cp
+		}
+		if v.Kind() == reflect.Ptr {
+			p := reflect.New(v.Type().Elem())
+			p.Elem().Set(c.value(v.Elem()))
+			cp.Set(p)
+			return cp
+		}
+		cp.Set(c.value(v.Elem()))
+		return cp
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			cp.Index(i).Set(c.value(v.Index(i)))
+		}
+		return cp
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		cp := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for _, k := range v.MapKeys() {
+			cp.SetMapIndex(k, c.value(v.MapIndex(k)))
+		}
+		return cp
+	case reflect.Struct:
+		cp := reflect.New(v.Type()).Elem()
+		cp.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if f := cp.Field(i); f.CanSet() {
+				f.Set(c.value(v.Field(i)))
+			}
+		}
+		return cp
+	}
+	return v
+}
+
+// titleNode returns a copy of the title node n, or nil if n is nil.
+func (c *nodeCloner) titleNode(n *TitleNode) *TitleNode {
+	if n == nil {
+		return nil
+	}
+	return c.node(n).(*TitleNode)
+}
@@ -0,0 +1,51 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+func TestTreeCloneDeepCopy(t *testing.T) {
+	input := ".. unknown:: arg\n   :opt: value\n\nText [1]_.\n\n.. [1] Note.\n"
+	tr, _ := ParseWithOptions("clone", input, &ParseOptions{ParseInline: true})
+	var dir *DirectiveNode
+	var fn *FootnoteNode
+	Walk(tr.Nodes, func(n Node) bool {
+		switch n := n.(type) {
+		case *DirectiveNode:
+			dir = n
+		case *FootnoteNode:
+			fn = n
+		}
+		return true
+	})
+	if dir == nil || fn == nil || len(fn.BackRefs) == 0 {
+		t.Fatalf("Got: directive = %v, footnote = %v, "+
+			"Expect: a directive and a referenced footnote", dir, fn)
+	}
+	c := tr.Clone()
+	Walk(c.Nodes, func(n Node) bool {
+		switch n := n.(type) {
+		case *DirectiveNode:
+			n.Options["opt"] = "changed"
+			n.Options["new"] = "added"
+		case *FootnoteNode:
+			n.BackRefs[0] = "changed"
+			n.Names[0] = "changed"
+		}
+		return true
+	})
+	if dir.Options["opt"] != "value" || len(dir.Options) != 1 {
+		t.Errorf("Got: Options = %q, Expect: the options of the original "+
+			"directive unchanged", dir.Options)
+	}
+	if fn.BackRefs[0] == "changed" {
+		t.Errorf("Got: BackRefs = %q, Expect: the back references of "+
+			"the original footnote unchanged", fn.BackRefs)
+	}
+	if fn.Names[0] == "changed" {
+		t.Errorf("Got: Names = %q, Expect: the names of the original "+
+			"footnote unchanged", fn.Names)
+	}
+}
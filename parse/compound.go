@@ -0,0 +1,28 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "strings"
+
+// compoundDirective returns the CompoundNode of the compound directive d at
+// item i. The directive content is parsed as the body of the node.
+func (t *Tree) compoundDirective(d *directive, i *item) Node {
+	t.directiveContent(d, i)
+	n := newCompound(i, &t.id)
+	n.NodeList = t.parseNested(d.content, i.Line+Line(d.contentLine),
+		d.contentIndent)
+	return n
+}
+
+// containerDirective returns the ContainerNode of the container directive d at
+// item i. The optional argument contains the class names of the container. The
+// directive content is parsed as the body of the node.
+func (t *Tree) containerDirective(d *directive, i *item) Node {
+	t.directiveContent(d, i)
+	n := newContainer(i, classNames(strings.Fields(d.argument)), &t.id)
+	n.NodeList = t.parseNested(d.content, i.Line+Line(d.contentLine),
+		d.contentIndent)
+	return n
+}
@@ -0,0 +1,64 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"reflect"
+	"testing"
+)
+
+var compoundDirectiveTests = []struct {
+	name    string
+	input   string
+	body    []NodeType // The expected node types of the body
+	classes []string   // The expected classes of a container
+}{
+	{
+		name: "Compound with a block quote",
+		input: ".. compound::\n\n   The command is\n\n" +
+			"       go build\n\n   in the package directory.",
+		body: []NodeType{NodeParagraph, NodeBlockQuote, NodeParagraph},
+	},
+	{
+		name: "Container with classes",
+		input: ".. container:: Custom Note\n\n   First paragraph.\n\n" +
+			"   - First item\n   - Second item",
+		body:    []NodeType{NodeParagraph, NodeBulletList},
+		classes: []string{"custom", "note"},
+	},
+}
+
+func TestParseCompoundDirectives(t *testing.T) {
+	for _, tt := range compoundDirectiveTests {
+		tr, errors := Parse(tt.name, tt.input)
+		if len(errors) != 0 {
+			t.Errorf("Test: %q\n\t    Got: len(errors) = %d, "+
+				"Expect: 0\n\n", tt.name, len(errors))
+			continue
+		}
+		if len(tr.Nodes) != 1 {
+			t.Errorf("Test: %q\n\t    Got: len(Nodes) = %d, "+
+				"Expect: 1\n\n", tt.name, len(tr.Nodes))
+			continue
+		}
+		var body []NodeType
+		for _, n := range children(tr.Nodes[0]) {
+			body = append(body, n.NodeType())
+		}
+		if !reflect.DeepEqual(body, tt.body) {
+			t.Errorf("Test: %q\n\t    Got: body = %v, Expect: %v\n\n",
+				tt.name, body, tt.body)
+		}
+		if c := tr.Nodes[0].Attrs().Classes; !reflect.DeepEqual(c,
+			tt.classes) {
+			t.Errorf("Test: %q\n\t    Got: Classes = %v, "+
+				"Expect: %v\n\n", tt.name, c, tt.classes)
+		}
+		if errs := tr.Validate(); len(errs) != 0 {
+			t.Errorf("Test: %q\n\t    Got: Validate() = %v, "+
+				"Expect: no errors\n\n", tt.name, errs)
+		}
+	}
+}
@@ -0,0 +1,351 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"unicode/utf8"
+)
+
+// DecodeNodes decodes the JSON encoded nodes of a test fixture into a
+// NodeList. The JSON data is an array of node objects. The keys of a node
+// object are the json struct tags of the node type, and the "type" key is the
+// name of the NodeType, for example "NodeSection", which selects the node
+// type. Keys that are missing from a node object are left at their zero
+// values.
+//
+// The "messageType", "severity", "enumType", "affix", and "auto" keys are the
+// names of the constants, for example "warningShortUnderline" and "ERROR". The
+// "rune" key of adornment nodes is a one character string. The "nodeList" key
+// is an array of node objects, and the "title", "overLine", "underLine",
+// "term", "definition", and "message" keys are node objects or null. The
+// "attributes" key is an object with the "ids", "names", and "classes" keys,
+// which are arrays of strings.
+//
+// The values of the constants and of the "rune" key may also be given as
+// numbers, like they are encoded by NodeList.MarshalJSON. A null element of
+// the array is decoded as a nil node.
+func DecodeNodes(data []byte) (NodeList, error) {
+	var v []interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return decodeNodeList(v)
+}
+
+// decodeNodeList decodes the unmarshaled JSON array v into a NodeList.
+func decodeNodeList(v []interface{}) (nl NodeList, err error) {
+	for _, e := range v {
+		if e == nil {
+			nl = append(nl, nil)
+			continue
+		}
+		m, ok := e.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("node is not a JSON object: %v", e)
+		}
+		var nt NodeType
+		name, _ := m["type"].(string)
+		if err = nt.UnmarshalText([]byte(name)); err != nil {
+			return nil, err
+		}
+		n := newNodeOfType(nt)
+		if n == nil {
+			return nil, fmt.Errorf("Node ID=%v: unsupported node type %s",
+				m["id"], nt)
+		}
+		if err = decodeFields(reflect.ValueOf(n).Elem(), m); err != nil {
+			return nil, fmt.Errorf("Node ID=%v: %s", m["id"], err)
+		}
+		nl = append(nl, n)
+	}
+	return
+}
+
+// newNodeOfType returns a pointer to a new zero value node of NodeType t, or
+// nil if there is no node struct for t yet.
+func newNodeOfType(t NodeType) (n Node) {
+	switch t {
+	case NodeSection:
+		n = new(SectionNode)
+	case NodeParagraph:
+		n = new(ParagraphNode)
+	case NodeAdornment:
+		n = new(AdornmentNode)
+	case NodeBlockQuote:
+		n = new(BlockQuoteNode)
+	case NodeSystemMessage:
+		n = new(SystemMessageNode)
+	case NodeLiteralBlock:
+		n = new(LiteralBlockNode)
+	case NodeTransition:
+		n = new(TransitionNode)
+	case NodeTitle:
+		n = new(TitleNode)
+	case NodeComment:
+		n = new(CommentNode)
+	case NodeBulletList:
+		n = new(BulletListNode)
+	case NodeBulletListItem:
+		n = new(BulletListItemNode)
+	case NodeEnumList:
+		n = new(EnumListNode)
+	case NodeDefinitionList:
+		n = new(DefinitionListNode)
+	case NodeDefinitionListItem:
+		n = new(DefinitionListItemNode)
+	case NodeDefinitionTerm:
+		n = new(DefinitionTermNode)
+	case NodeDefinition:
+		n = new(DefinitionNode)
+	case NodeBlankLine:
+		n = new(BlankLineNode)
+	case NodeImage:
+		n = new(ImageNode)
+	case NodeFootnote:
+		n = new(FootnoteNode)
+	case NodeText:
+		n = new(TextNode)
+	case NodeEmphasis:
+		n = new(EmphasisNode)
+	case NodeStrong:
+		n = new(StrongNode)
+	case NodeInlineLiteral:
+		n = new(InlineLiteralNode)
+	case NodeProblematic:
+		n = new(ProblematicNode)
+	case NodeTopic:
+		n = new(TopicNode)
+	case NodeSidebar:
+		n = new(SidebarNode)
+	case NodeRubric:
+		n = new(RubricNode)
+	case NodeParsedLiteral:
+		n = new(ParsedLiteralNode)
+	case NodeCompound:
+		n = new(CompoundNode)
+	case NodeContainer:
+		n = new(ContainerNode)
+	case NodeSubstitutionDefinition:
+		n = new(SubstitutionDefinitionNode)
+	case NodeFootnoteRef:
+		n = new(FootnoteRefNode)
+	case NodeCitationRef:
+		n = new(CitationRefNode)
+	case NodeReference:
+		n = new(ReferenceNode)
+	case NodeCitation:
+		n = new(CitationNode)
+	case NodeTarget:
+		n = new(TargetNode)
+	case NodeDirective:
+		n = new(DirectiveNode)
+	case NodeClassifier:
+		n = new(ClassifierNode)
+	case NodeFieldList:
+		n = new(FieldListNode)
+	case NodeField:
+		n = new(FieldNode)
+	case NodeDocInfo:
+		n = new(DocInfoNode)
+	case NodeLineBlock:
+		n = new(LineBlockNode)
+	case NodeLine:
+		n = new(LineNode)
+	case NodeTable:
+		n = new(TableNode)
+	case NodeTableRow:
+		n = new(TableRowNode)
+	case NodeTableEntry:
+		n = new(TableEntryNode)
+	}
+	return
+}
+
+// decodeFields sets the fields of the node struct v from the unmarshaled JSON
+// object m. An error is returned if m contains a key that is not a field of v.
+func decodeFields(v reflect.Value, m map[string]interface{}) error {
+	fields := make(map[string]reflect.Value)
+	for i := 0; i < v.NumField(); i++ {
+		fields[v.Type().Field(i).Tag.Get("json")] = v.Field(i)
+	}
+	for key, jv := range m {
+		f, ok := fields[key]
+		if !ok {
+			return fmt.Errorf("%s has no field %q", v.Type(), key)
+		}
+		if jv == nil {
+			continue
+		}
+		if err := decodeValue(f, jv); err != nil {
+			return fmt.Errorf("field %q: %s", key, err)
+		}
+	}
+	return nil
+}
+
+// nameIndex returns the index of name in names, or -1 if it is not found.
+func nameIndex(names []string, name string) int {
+	for num, n := range names {
+		if n == name {
+			return num
+		}
+	}
+	return -1
+}
+
+// decodeValue sets the field f from the unmarshaled JSON value jv.
+func decodeValue(f reflect.Value, jv interface{}) error {
+	var names []string
+	switch f.Interface().(type) {
+	case NodeType:
+		s, _ := jv.(string)
+		return f.Addr().Interface().(*NodeType).UnmarshalText([]byte(s))
+	case NodeList:
+		a, ok := jv.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected an array, got %v", jv)
+		}
+		nl, err := decodeNodeList(a)
+		if err != nil {
+			return err
+		}
+		f.Set(reflect.ValueOf(nl))
+		return nil
+	case rune:
+		if n, ok := jv.(float64); ok {
+			f.SetInt(int64(n))
+			return nil
+		}
+		s, _ := jv.(string)
+		if utf8.RuneCountInString(s) != 1 {
+			return fmt.Errorf("expected a single character, got %q", jv)
+		}
+		r, _ := utf8.DecodeRuneInString(s)
+		f.SetInt(int64(r))
+		return nil
+	case parserMessage:
+		names = parserErrors[:]
+	case systemMessageLevel:
+		names = systemMessageLevels[:]
+	case EnumListType:
+		names = enumListTypes[:]
+	case EnumAffixType:
+		names = enumAffixesTypes[:]
+	case FootnoteAutoType:
+		names = footnoteAutoTypes[:]
+	}
+
+	if names != nil {
+		if n, ok := jv.(float64); ok && int(n) >= 0 && int(n) < len(names) {
+			f.SetInt(int64(n))
+			return nil
+		}
+		s, _ := jv.(string)
+		num := nameIndex(names, s)
+		if num == -1 {
+			return fmt.Errorf("unknown %s %q", f.Type(), jv)
+		}
+		f.SetInt(int64(num))
+		return nil
+	}
+
+	switch f.Kind() {
+	case reflect.Int:
+		n, ok := jv.(float64)
+		if !ok {
+			return fmt.Errorf("expected a number, got %v", jv)
+		}
+		f.SetInt(int64(n))
+	case reflect.String:
+		s, ok := jv.(string)
+		if !ok {
+			return fmt.Errorf("expected a string, got %v", jv)
+		}
+		f.SetString(s)
+	case reflect.Struct:
+		m, ok := jv.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected an object, got %v", jv)
+		}
+		return decodeFields(f, m)
+	case reflect.Slice:
+		a, ok := jv.([]interface{})
+		if !ok || f.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("expected an array of strings, got %v", jv)
+		}
+		l := reflect.MakeSlice(f.Type(), len(a), len(a))
+		for num, e := range a {
+			s, ok := e.(string)
+			if !ok {
+				return fmt.Errorf("expected a string, got %v", e)
+			}
+			l.Index(num).SetString(s)
+		}
+		f.Set(l)
+	case reflect.Map:
+		m, ok := jv.(map[string]interface{})
+		if !ok || f.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("expected an object of strings, got %v", jv)
+		}
+		o := reflect.MakeMap(f.Type())
+		for key, e := range m {
+			s, ok := e.(string)
+			if !ok {
+				return fmt.Errorf("expected a string, got %v", e)
+			}
+			o.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(s))
+		}
+		f.Set(o)
+	case reflect.Ptr:
+		m, ok := jv.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected an object, got %v", jv)
+		}
+		p := reflect.New(f.Type().Elem())
+		if err := decodeFields(p.Elem(), m); err != nil {
+			return err
+		}
+		f.Set(p)
+	default:
+		return fmt.Errorf("can not decode %s", f.Type())
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler and encodes the NodeList as an array
+// of node objects. Each node is encoded by the encoding/json package using
+// the json struct tags of the node type, the "type" key of the node object is
+// the name of its NodeType and identifies the node type when decoding. nil
+// nodes are encoded as null.
+func (l NodeList) MarshalJSON() ([]byte, error) {
+	elems := make([]json.RawMessage, len(l))
+	for num, n := range l {
+		if n == nil {
+			elems[num] = json.RawMessage("null")
+			continue
+		}
+		data, err := json.Marshal(n)
+		if err != nil {
+			return nil, err
+		}
+		elems[num] = data
+	}
+	return json.Marshal(elems)
+}
+
+// UnmarshalJSON implements json.Unmarshaler and decodes an array of node
+// objects with DecodeNodes. The "type" key of each node object selects the
+// node type.
+func (l *NodeList) UnmarshalJSON(data []byte) error {
+	nl, err := DecodeNodes(data)
+	if err != nil {
+		return err
+	}
+	*l = nl
+	return nil
+}
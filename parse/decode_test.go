@@ -0,0 +1,115 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeNodes(t *testing.T) {
+	data := `[
+		{
+			"id": 1,
+			"type": "NodeSection",
+			"level": 1,
+			"title": {"id": 2, "type": "NodeTitle", "text": "Title",
+				"line": 1, "length": 5},
+			"overLine": null,
+			"underLine": {"id": 3, "type": "NodeAdornment", "rune": "=",
+				"line": 2, "length": 5},
+			"nodeList": [
+				{
+					"id": 4,
+					"type": "NodeSystemMessage",
+					"messageType": "warningShortUnderline",
+					"severity": "WARNING",
+					"line": 1,
+					"nodeList": [
+						{"id": 5, "type": "NodeParagraph",
+							"text": "Title underline too short."}
+					]
+				}
+			]
+		}
+	]`
+	nl, err := DecodeNodes([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec, ok := nl[0].(*SectionNode)
+	if !ok {
+		t.Fatalf("Got: %T, Expect: *SectionNode", nl[0])
+	}
+	if sec.Title.Text != "Title" || sec.UnderLine.Rune != '=' ||
+		sec.OverLine != nil || sec.Level != 1 {
+		t.Errorf("Got: incorrectly decoded section: %#v", sec)
+	}
+	sm := sec.NodeList[0].(*SystemMessageNode)
+	if sm.MessageType != warningShortUnderline || sm.Severity != levelWarning {
+		t.Errorf("Got: MessageType = %s, Severity = %s, "+
+			"Expect: %s, %s", sm.MessageType, sm.Severity,
+			warningShortUnderline, levelWarning)
+	}
+	if p := sm.NodeList[0].(*ParagraphNode); p.ID != 5 {
+		t.Errorf("Got: ID = %d, Expect: %d", p.ID, 5)
+	}
+}
+
+func TestDecodeNodesErrors(t *testing.T) {
+	for _, data := range []string{
+		`[{"id": 1, "type": "NodeUnknown"}]`,
+		`[{"id": 1, "type": "NodeParagraph", "bullet": "*"}]`,
+		`[{"id": 1, "type": "NodeSystemMessage", "severity": "LOUD"}]`,
+		`[{"id": 1, "type": "NodeAdornment", "rune": "=="}]`,
+		`[{"id": 1, "type": "NodeOptionList"}]`,
+		`[{"id": 1, "type": "NodeParagraph", "nodeList": [{"type": "NodeSubstitutionReference"}]}]`,
+		`{"id": 1}`,
+	} {
+		if _, err := DecodeNodes([]byte(data)); err == nil {
+			t.Errorf("Test: %q\n\t    Got: no error\n\n", data)
+		}
+	}
+}
+
+func TestNodeListJSONRoundTrip(t *testing.T) {
+	tr, _ := Parse("round trip", "Title\n=====\n\nParagraph text.\n")
+	nl := append(NodeList{}, tr.Nodes...)
+	id := tr.id
+	nl = append(nl, newLiteralBlock(&item{
+		Text:          "x := 1",
+		Length:        6,
+		Line:          5,
+		StartPosition: 5,
+	}, &id), nil)
+	nl[0].Attrs().Classes = []string{"intro"}
+
+	data, err := json.Marshal(nl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got NodeList
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal: %s\n%s", err, data)
+	}
+	if !reflect.DeepEqual(got, nl) {
+		t.Errorf("Got: %s\n\t    Expect: %s", spd.Sdump(got),
+			spd.Sdump(nl))
+	}
+	if _, ok := got[0].(*SectionNode).NodeList[0].(*ParagraphNode); !ok {
+		t.Errorf("Got: %T, Expect: *ParagraphNode",
+			got[0].(*SectionNode).NodeList[0])
+	}
+}
+
+func TestNodeListUnmarshalJSONUnsupportedType(t *testing.T) {
+	var nl NodeList
+	err := json.Unmarshal([]byte(`[{"type":"NodeOptionList"}]`), &nl)
+	if err == nil || !strings.Contains(err.Error(), "unsupported node type") {
+		t.Errorf("Got: err = %v, Expect: an unsupported node type error", err)
+	}
+}
@@ -0,0 +1,190 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Diagnostic contains the position of a system message generated by the
+// parser. Diagnostics are recorded in Tree.Diagnostics in the same order as
+// their system messages in Tree.Messages.
+type Diagnostic struct {
+	// Source is the name of the input, Options.Source or the name of the
+	// Tree.
+	Source string
+
+	// Line is the line of input the message refers to.
+	Line
+
+	// StartPosition is the column of the token on Line that caused the
+	// message. It is zero if the message does not refer to a single token.
+	StartPosition
+
+	// The type of parser message and its level.
+	MessageType parserMessage
+	Severity    systemMessageLevel
+
+	// Node is the system message node added to Tree.Messages.
+	Node *SystemMessageNode
+}
+
+// Message returns the text of the message of the Diagnostic.
+func (d *Diagnostic) Message() string {
+	return d.MessageType.Message()
+}
+
+// String returns the Diagnostic in the form "source:line:col: message", like
+// the messages of the Go tools. The column is omitted if it is zero, and the
+// source is omitted if it is empty.
+func (d *Diagnostic) String() string {
+	s := strconv.Itoa(int(d.Line))
+	if d.StartPosition > 0 {
+		s += ":" + strconv.Itoa(int(d.StartPosition))
+	}
+	if d.Source != "" {
+		s = d.Source + ":" + s
+	}
+	return s + ": " + d.Message()
+}
+
+// SortedDiagnostics returns a copy of Tree.Diagnostics sorted by line and
+// then by column. Diagnostics at the same position are ordered by severity,
+// the most severe first, and otherwise keep the order of Tree.Diagnostics.
+// Tree.Diagnostics is in the order the messages were generated, which is not
+// always the order of the input.
+func (t *Tree) SortedDiagnostics() []*Diagnostic {
+	diags := make(diagnosticsByPosition, len(t.Diagnostics))
+	copy(diags, t.Diagnostics)
+	sort.Stable(diags)
+	return diags
+}
+
+// diagnosticsByPosition is a list of diagnostics sorted by line, column, and
+// descending severity.
+type diagnosticsByPosition []*Diagnostic
+
+func (d diagnosticsByPosition) Len() int      { return len(d) }
+func (d diagnosticsByPosition) Swap(i, j int) { d[i], d[j] = d[j], d[i] }
+func (d diagnosticsByPosition) Less(i, j int) bool {
+	if d[i].Line != d[j].Line {
+		return d[i].Line < d[j].Line
+	}
+	if d[i].StartPosition != d[j].StartPosition {
+		return d[i].StartPosition < d[j].StartPosition
+	}
+	return d[i].Severity > d[j].Severity
+}
+
+// addMessage adds the system message s to Tree.Messages and records a
+// Diagnostic for it using the line of s and the column pos. Messages below
+// Options.ReportLevel are not added. If the level of s is at or above
+// Options.HaltLevel, the parser is halted. Once Options.MaxDiagnostics messages
+// have been added, an infoTooManyMessages message is added in place of s and
+// the following messages are dropped.
+func (t *Tree) addMessage(s *SystemMessageNode, pos StartPosition) {
+	level := messageLevel(s.Severity)
+	if t.Options.HaltLevel > 0 && level >= t.Options.HaltLevel {
+		t.Halted = true
+	} else if level < t.Options.ReportLevel {
+		return
+	}
+	if s = t.limitMessage(s); s == nil {
+		return
+	}
+	t.Messages.append(s)
+	t.Diagnostics = append(t.Diagnostics, &Diagnostic{
+		Source:        t.source(),
+		Line:          s.Line,
+		StartPosition: pos,
+		MessageType:   s.MessageType,
+		Severity:      s.Severity,
+		Node:          s,
+	})
+}
+
+// limitMessage returns the system message that is added to Tree.Messages in
+// place of s. Once Options.MaxDiagnostics messages have been added, an
+// infoTooManyMessages message is returned for the first message over the
+// limit, and nil for the following messages. Otherwise s is returned.
+func (t *Tree) limitMessage(s *SystemMessageNode) *SystemMessageNode {
+	max := t.Options.MaxDiagnostics
+	if max <= 0 || len(t.Diagnostics) < max {
+		return s
+	}
+	if t.suppressed {
+		return nil
+	}
+	t.suppressed = true
+	msg := infoTooManyMessages.Message()
+	s = newSystemMessage(&item{Type: itemSystemMessage, Line: s.Line},
+		infoTooManyMessages, &t.id)
+	s.NodeList = append(s.NodeList, newParagraph(&item{
+		Text:   msg,
+		Length: len(msg),
+	}, &t.id))
+	return s
+}
+
+// tokenPosition returns the column of the first token in the token buffer that
+// is on line. Zero is returned if no token is found.
+func (t *Tree) tokenPosition(line Line) StartPosition {
+	for _, tok := range t.token {
+		if tok != nil && tok.Line == line {
+			return tok.StartPosition
+		}
+	}
+	return 0
+}
+
+// positionMessage adds a system message for msg at line and column pos to
+// Tree.Messages. It is used for messages found after parsing, when the tokens
+// of the input are no longer available.
+func (t *Tree) positionMessage(msg parserMessage, line Line,
+	pos StartPosition) *SystemMessageNode {
+
+	s := newSystemMessage(&item{Type: itemSystemMessage, Line: line}, msg,
+		&t.id)
+	s.NodeList = append(s.NodeList, newParagraph(&item{
+		Text:   msg.Message(),
+		Length: len(msg.Message()),
+	}, &t.id))
+	t.addMessage(s, pos)
+	return s
+}
+
+// diagnosticMessages adds the system messages of the diagnostics returned by a
+// DirectiveHandler or a RoleHandler to Tree.Messages, and returns the system
+// messages. A diagnostic without a system message gets a system message for
+// its MessageType. line and pos are the position of the directive or the role,
+// they are used for diagnostics without a line.
+func (t *Tree) diagnosticMessages(diags []Diagnostic, line Line,
+	pos StartPosition) (msgs []*SystemMessageNode) {
+
+	for _, diag := range diags {
+		dLine, dPos := diag.Line, diag.StartPosition
+		if dLine == 0 {
+			dLine = line
+			if dPos == 0 {
+				dPos = pos
+			}
+		}
+		s := diag.Node
+		if s == nil {
+			s = newSystemMessage(&item{Type: itemSystemMessage,
+				Line: dLine}, diag.MessageType, &t.id)
+			msg := diag.MessageType.Message()
+			s.NodeList = append(s.NodeList, newParagraph(&item{
+				Text:   msg,
+				Length: len(msg),
+			}, &t.id))
+		}
+		t.numberNodes(NodeList{s})
+		t.addMessage(s, dPos)
+		msgs = append(msgs, s)
+	}
+	return
+}
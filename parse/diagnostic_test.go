@@ -0,0 +1,149 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+var diagnosticTests = []struct {
+	name          string
+	input         string
+	messageType   parserMessage
+	line          Line
+	startPosition StartPosition
+}{
+	{
+		name:          "Short underline",
+		input:         "Title\n====\n\nParagraph.",
+		messageType:   warningShortUnderline,
+		line:          1,
+		startPosition: 1,
+	},
+	{
+		name: "Indented title with short overline and underline",
+		input: "Paragraph\n\n    ==\n    ABC\n    ==\n\n" +
+			"    Over & underline too short.",
+		messageType:   infoUnexpectedTitleOverlineOrTransition,
+		line:          3,
+		startPosition: 5,
+	},
+	{
+		name:          "Transition at the beginning of the document",
+		input:         "----------\n\nParagraph.",
+		messageType:   errorTransitionAtBeginning,
+		line:          1,
+		startPosition: 1,
+	},
+}
+
+func TestTreeDiagnostics(t *testing.T) {
+	for _, tt := range diagnosticTests {
+		tr, errors := Parse(tt.name, tt.input)
+		if len(tr.Diagnostics) != 1 || len(errors) != 1 {
+			t.Errorf("Test: %q\n\t    "+
+				"Got: len(Diagnostics) = %d, Expect: %d\n\n",
+				tt.name, len(tr.Diagnostics), 1)
+			continue
+		}
+		d := tr.Diagnostics[0]
+		if d.MessageType != tt.messageType {
+			t.Errorf("Test: %q\n\t    "+
+				"Got: MessageType = %q, Expect: %q\n\n",
+				tt.name, d.MessageType, tt.messageType)
+		}
+		if d.Line != tt.line {
+			t.Errorf("Test: %q\n\t    Got: Line = %d, Expect: %d\n\n",
+				tt.name, d.Line, tt.line)
+		}
+		if d.StartPosition != tt.startPosition {
+			t.Errorf("Test: %q\n\t    "+
+				"Got: StartPosition = %d, Expect: %d\n\n",
+				tt.name, d.StartPosition, tt.startPosition)
+		}
+		if d.Node != errors[0] {
+			t.Errorf("Test: %q\n\t    "+
+				"Got: Node = %p, Expect: %p\n\n",
+				tt.name, d.Node, errors[0])
+		}
+	}
+}
+
+func TestDiagnosticSource(t *testing.T) {
+	input := "Title\n===\n\nParagraph."
+	tests := []struct {
+		name   string
+		source string
+		expect string
+	}{
+		{"doc.rst", "", "doc.rst"},
+		{"doc.rst", "included.rst", "included.rst"},
+	}
+	for _, tt := range tests {
+		tr, _ := ParseWithOptions(tt.name, input,
+			&ParseOptions{Source: tt.source})
+		if len(tr.Diagnostics) != 1 {
+			t.Fatalf("Got: len(Diagnostics) = %d, Expect: 1",
+				len(tr.Diagnostics))
+		}
+		d := tr.Diagnostics[0]
+		if d.Source != tt.expect || tr.Name != tt.name {
+			t.Errorf("Test: %q\n\t    Got: Source = %q, Name = %q, "+
+				"Expect: %q, %q\n\n", tt.source, d.Source, tr.Name,
+				tt.expect, tt.name)
+		}
+		msg := tt.expect + ":1:1: " + warningShortUnderline.Message()
+		if d.String() != msg {
+			t.Errorf("Test: %q\n\t    Got: String() = %q, "+
+				"Expect: %q\n\n", tt.source, d.String(), msg)
+		}
+		if pos := tr.PositionString(0); pos != tt.expect+":1:1" {
+			t.Errorf("Test: %q\n\t    Got: PositionString(0) = %q, "+
+				"Expect: %q\n\n", tt.source, pos, tt.expect+":1:1")
+		}
+	}
+}
+
+func TestTreeSortedDiagnostics(t *testing.T) {
+	// The line length messages are generated after parsing, so they are
+	// recorded after the message of the title on line 3.
+	tr, _ := ParseWithOptions("sorted",
+		"A very long first line.\n\nTitle\n===\n\nx",
+		&ParseOptions{MaxLineLength: 10})
+	// The diagnostics at the same position are ordered by severity.
+	tr.Diagnostics = append(tr.Diagnostics,
+		&Diagnostic{Line: 3, StartPosition: 1, Severity: levelInfo},
+		&Diagnostic{Line: 3, StartPosition: 1, Severity: levelError})
+	expect := []struct {
+		line     Line
+		pos      StartPosition
+		severity systemMessageLevel
+	}{
+		{1, 11, levelInfo},
+		{3, 1, levelError},
+		{3, 1, levelWarning},
+		{3, 1, levelInfo},
+	}
+	if tr.Diagnostics[0].Line != 3 {
+		t.Fatalf("Got: Diagnostics[0].Line = %d, Expect: 3",
+			tr.Diagnostics[0].Line)
+	}
+	sorted := tr.SortedDiagnostics()
+	if len(sorted) != len(expect) {
+		t.Fatalf("Got: len(SortedDiagnostics()) = %d, Expect: %d",
+			len(sorted), len(expect))
+	}
+	for num, e := range expect {
+		d := sorted[num]
+		if d.Line != e.line || d.StartPosition != e.pos ||
+			d.Severity != e.severity {
+			t.Errorf("Test: %d\n\t    Got: %d:%d %s, Expect: %d:%d %s\n\n",
+				num, d.Line, d.StartPosition, d.Severity, e.line,
+				e.pos, e.severity)
+		}
+	}
+	if tr.Diagnostics[0].Line != 3 {
+		t.Errorf("Got: Diagnostics[0].Line = %d after sorting, Expect: 3",
+			tr.Diagnostics[0].Line)
+	}
+}
@@ -0,0 +1,197 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// pseudoXMLIndent is the indentation of the children and the text of an
+// element in the pseudo-XML dump.
+const pseudoXMLIndent = "    "
+
+// dumpLine is a line of a pseudo-XML dump and the node it was written for.
+type dumpLine struct {
+	text string
+	node Node
+}
+
+// PseudoXML returns the nodes in nl as pseudo-XML, in the style of the
+// pseudo-XML writer of docutils. Each node is written as an element on its own
+// line with the non-zero fields of the node as attributes. The text of the node
+// and its children follow on indented lines.
+func PseudoXML(nl NodeList) string {
+	var lines []string
+	for _, l := range pseudoXMLLines(nl, "") {
+		lines = append(lines, l.text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// pseudoXMLLines returns the lines of the pseudo-XML dump of nl indented with
+// indent.
+func pseudoXMLLines(nl NodeList, indent string) (lines []dumpLine) {
+	for _, n := range nl {
+		if n == nil {
+			continue
+		}
+		lines = append(lines, dumpLine{indent + pseudoXMLElement(n), n})
+		v := reflect.Indirect(reflect.ValueOf(n))
+		tIndent := indent + pseudoXMLIndent
+		f := v.FieldByName("Text")
+		if f.IsValid() && f.Kind() == reflect.String {
+			for _, l := range strings.Split(f.String(), "\n") {
+				lines = append(lines, dumpLine{tIndent + l, n})
+			}
+		}
+		lines = append(lines, pseudoXMLLines(children(n), tIndent)...)
+	}
+	return
+}
+
+// pseudoXMLElement returns the start tag of the element of node n.
+func pseudoXMLElement(n Node) string {
+	name := strings.TrimPrefix(n.NodeType().String(), "Node")
+	attrs := pseudoXMLAttrs(reflect.Indirect(reflect.ValueOf(n)))
+	if len(attrs) == 0 {
+		return "<" + elementName(name) + ">"
+	}
+	return "<" + elementName(name) + " " + strings.Join(attrs, " ") + ">"
+}
+
+var nodeInterface = reflect.TypeOf((*Node)(nil)).Elem()
+
+// pseudoXMLAttrs returns the attributes of the struct v, named by the json tags
+// of the fields. Fields with a zero value, the node type, the text, and the
+// child nodes are omitted. The fields of embedded structs, like Attributes, are
+// included as attributes of their own.
+func pseudoXMLAttrs(v reflect.Value) (attrs []string) {
+	for i := 0; i < v.NumField(); i++ {
+		f, sf := v.Field(i), v.Type().Field(i)
+		name := strings.Split(sf.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" || name == "type" || name == "text" ||
+			f.IsZero() {
+			continue
+		}
+		ft := f.Type()
+		switch {
+		case ft.Implements(nodeInterface):
+			continue
+		case ft.Kind() == reflect.Slice &&
+			ft.Elem().Implements(nodeInterface):
+			continue
+		case ft.Kind() == reflect.Struct:
+			attrs = append(attrs, pseudoXMLAttrs(f)...)
+			continue
+		}
+		var val string
+		switch {
+		case ft.Kind() == reflect.Int32:
+			// Runes, like the rune of an adornment.
+			val = string(rune(f.Int()))
+		case ft.Kind() == reflect.Slice &&
+			ft.Elem().Kind() == reflect.String:
+			val = strings.Join(f.Interface().([]string), " ")
+		case ft.Kind() == reflect.Ptr:
+			val = fmt.Sprint(f.Elem().Interface())
+		default:
+			val = fmt.Sprint(f.Interface())
+		}
+		attrs = append(attrs, fmt.Sprintf("%s=%q", name, val))
+	}
+	return
+}
+
+// elementName returns the element name for the node type name, for example
+// "literal_block" for "LiteralBlock".
+func elementName(name string) string {
+	var b []rune
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b = append(b, '_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b = append(b, r)
+	}
+	return string(b)
+}
+
+// DiffNodes returns a line oriented diff of the pseudo-XML dumps of want and
+// got, as returned by PseudoXML, or an empty string if the dumps are equal.
+// Lines only in want are prefixed with "-", lines only in got with "+". The
+// first line of the diff names the first node that differs, with its type, ID,
+// and line in the input.
+func DiffNodes(want, got NodeList) string {
+	wl, gl := pseudoXMLLines(want, ""), pseudoXMLLines(got, "")
+	first := 0
+	for first < len(wl) && first < len(gl) &&
+		wl[first].text == gl[first].text {
+		first++
+	}
+	if first == len(wl) && first == len(gl) {
+		return ""
+	}
+	var n Node
+	if first < len(gl) {
+		n = gl[first].node
+	} else {
+		n = wl[first].node
+	}
+	out := []string{fmt.Sprintf("first difference at %s (ID %d, line %d)",
+		n.NodeType(), n.IDNumber(), nodeLine(n))}
+	return strings.Join(append(out, diffLines(wl, gl)...), "\n")
+}
+
+// nodeLine returns the Line field of node n, or zero if n has none.
+func nodeLine(n Node) Line {
+	v := reflect.Indirect(reflect.ValueOf(n))
+	if f := v.FieldByName("Line"); f.IsValid() && f.Kind() == reflect.Int {
+		return Line(f.Int())
+	}
+	return 0
+}
+
+// diffLines returns the lines of a and b prefixed with " ", "-", or "+", using
+// the longest common subsequence of the two.
+func diffLines(a, b []dumpLine) (out []string) {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i].text == b[j].text {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i].text == b[j].text:
+			out = append(out, " "+a[i].text)
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			out = append(out, "-"+a[i].text)
+			i++
+		default:
+			out = append(out, "+"+b[j].text)
+			j++
+		}
+	}
+	return
+}
@@ -0,0 +1,51 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPseudoXML(t *testing.T) {
+	tr, _ := Parse("test", "Title\n=====\n\nOne\ntwo.\n")
+	expect := `<section id="1" level="1">
+    <title id="2" length="5" line="1" startPosition="1">
+        Title
+    <adornment id="3" rune="=" length="5" line="2" startPosition="1">
+    <paragraph id="4" length="8" line="4" startPosition="1">
+        One
+        two.`
+	if got := PseudoXML(tr.Nodes); got != expect {
+		t.Errorf("Got:\n%s\nExpect:\n%s", got, expect)
+	}
+}
+
+func TestDiffNodes(t *testing.T) {
+	want, _ := Parse("want", "Title\n=====\n\nOne.\n\nTwo.\n")
+	got, _ := Parse("got", "Title\n=====\n\nOne.\n\nTwo!\n")
+	if d := DiffNodes(want.Nodes, want.Nodes); d != "" {
+		t.Errorf("Got: diff of equal trees = %q, Expect: \"\"", d)
+	}
+	d := DiffNodes(want.Nodes, got.Nodes)
+	lines := strings.Split(d, "\n")
+	if e := "first difference at NodeParagraph (ID 5, line 6)"; lines[0] != e {
+		t.Errorf("Got: %q, Expect: %q", lines[0], e)
+	}
+	var minus, plus []string
+	for _, l := range lines[1:] {
+		switch l[0] {
+		case '-':
+			minus = append(minus, strings.TrimSpace(l[1:]))
+		case '+':
+			plus = append(plus, strings.TrimSpace(l[1:]))
+		}
+	}
+	if len(minus) != 1 || minus[0] != "Two." || len(plus) != 1 ||
+		plus[0] != "Two!" {
+		t.Errorf("Got: removed %q, added %q, Expect: [\"Two.\"], "+
+			"[\"Two!\"]", minus, plus)
+	}
+}
@@ -0,0 +1,263 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// directive contains the parts of a directive block.
+type directive struct {
+	name     string            // The lowercased directive type
+	argument string            // The argument lines joined with newlines
+	options  map[string]string // The field list options by name
+
+	// argumentOffset is the number of characters between the beginning of
+	// text and an argument beginning on the first line.
+	argumentOffset int
+
+	// content is the text of the directive content, and contentLine is the
+	// number of lines between the directive marker and the first line of
+	// the content. contentIndent is the width of the indentation removed
+	// from the content by directiveContent.
+	content       string
+	contentLine   int
+	contentIndent int
+
+	// block is the text of the input lines of the directive, set by
+	// directiveContent.
+	block string
+}
+
+// DirectiveHandler parses a directive. Handlers are added to the parser with
+// ParseOptions.Directives.
+type DirectiveHandler interface {
+	// Name returns the name of the directive handled, for example
+	// "image". Directive names are not case sensitive.
+	Name() string
+
+	// Parse returns the node of the directive d, which replaces d in the
+	// parse tree. The IDs of the returned nodes are set by the parser if
+	// they are zero. The system messages of the returned diagnostics are
+	// added to Tree.Messages, a diagnostic without a system message gets a
+	// system message for its MessageType, and a diagnostic without a line
+	// gets the line of d. If the returned node is nil, the system messages
+	// take the place of d in the parse tree.
+	Parse(d *DirectiveNode) (Node, []Diagnostic)
+}
+
+// builtinDirectives are the built-in directives parsed by a DirectiveHandler.
+// The other built-in directives depend on the state of the parser and are
+// handled by Tree.comment.
+var builtinDirectives = []DirectiveHandler{
+	imageHandler{},
+}
+
+// directiveHandler returns the DirectiveHandler of the directive name. The
+// handlers of ParseOptions.Directives are searched before the built-in
+// handlers. nil is returned if there is no handler for name.
+func (t *Tree) directiveHandler(name string) DirectiveHandler {
+	for _, h := range t.Options.Directives {
+		if strings.ToLower(h.Name()) == name {
+			return h
+		}
+	}
+	for _, h := range builtinDirectives {
+		if h.Name() == name {
+			return h
+		}
+	}
+	return nil
+}
+
+// handleDirective parses the directive d at item i with the handler h and
+// returns the node of the directive. Unless the handler returns the
+// DirectiveNode it is given, the ID of the DirectiveNode is reused by the
+// returned nodes, so the IDs of the document stay consecutive.
+func (t *Tree) handleDirective(h DirectiveHandler, d *directive, i *item) Node {
+	t.directiveContent(d, i)
+	start := t.id
+	dn := newDirective(i, d, &t.id)
+	n, diags := h.Parse(dn)
+	if n != Node(dn) {
+		t.id = start
+	}
+	if n != nil {
+		t.numberNodes(NodeList{n})
+	}
+	msgs := t.diagnosticMessages(diags, i.Line, i.StartPosition)
+	if n != nil || len(msgs) == 0 {
+		return n
+	}
+	for _, m := range msgs[:len(msgs)-1] {
+		t.nodeTarget.append(m)
+	}
+	return msgs[len(msgs)-1]
+}
+
+// directiveSpec describes a directive known to the parser.
+type directiveSpec struct {
+	argumentRequired bool // The directive must have an argument
+	contentRequired  bool // The directive must have a content block
+}
+
+// directiveSpecs are the directives known to the parser by name. Directives
+// with other names are reported as unknown directives.
+var directiveSpecs = map[string]directiveSpec{
+	"class":          {argumentRequired: true},
+	"topic":          {argumentRequired: true, contentRequired: true},
+	"sidebar":        {argumentRequired: true, contentRequired: true},
+	"rubric":         {argumentRequired: true},
+	"parsed-literal": {contentRequired: true},
+	"compound":       {contentRequired: true},
+	"container":      {contentRequired: true},
+}
+
+// checkDirective returns an error level system message if the directive d at
+// item i is not a known directive, or has no content although its content is
+// required. The DirectiveNode of d is added to the current NodeList before
+// the system message, so the parts of the directive are kept. The system
+// message contains the text of the directive block. nil is returned if d can
+// be parsed. A missing required argument is reported by the directive handler
+// before a missing content block, like docutils does.
+func (t *Tree) checkDirective(d *directive, i *item) Node {
+	spec, known := directiveSpecs[d.name]
+	if known && (!spec.contentRequired ||
+		spec.argumentRequired && d.argument == "") {
+		return nil
+	}
+	t.directiveContent(d, i)
+	msg := errorUnknownDirectiveType
+	text := "Unknown directive type \"" + d.name + "\"."
+	if known {
+		if d.content != "" {
+			return nil
+		}
+		msg = errorDirectiveContent
+		text = "Content block expected for the \"" + d.name +
+			"\" directive; none found."
+	}
+	t.nodeTarget.append(newDirective(i, d, &t.id))
+	s := newSystemMessage(&item{Type: itemSystemMessage, Line: i.Line}, msg,
+		&t.id)
+	s.NodeList = append(s.NodeList, newParagraph(&item{
+		Text:   text,
+		Length: len(text),
+	}, &t.id))
+	s.NodeList = append(s.NodeList, newLiteralBlock(&item{
+		Type:   itemLiteralBlock,
+		Text:   d.block,
+		Length: len(d.block),
+	}, &t.id))
+	t.addMessage(s, i.StartPosition)
+	return s
+}
+
+// parseDirective splits the text of an explicit markup block into the parts
+// of a directive. The argument ends at the first line after the directive
+// marker that begins with a field marker, the following field list lines are
+// the options. The content begins after the first blank line. ok is false if
+// text is not a directive.
+func parseDirective(text string) (d *directive, ok bool) {
+	if explicitMarkupKindOf(text) != explicitDirective {
+		return nil, false
+	}
+	end := strings.Index(text, "::")
+	d = &directive{name: strings.ToLower(text[:end])}
+	var args []string
+	var opt string
+	lines := strings.Split(text[end+2:], "\n")
+	first := strings.TrimLeftFunc(lines[0], unicode.IsSpace)
+	d.argumentOffset = utf8.RuneCountInString(
+		text[:end+2+len(lines[0])-len(first)])
+	for num, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" && num > 0 {
+			d.content = strings.Trim(strings.Join(lines[num:], "\n"),
+				"\n")
+			d.contentLine = num + 1
+			for d.contentLine < len(lines) && lines[d.contentLine] == "" {
+				d.contentLine++
+			}
+			break
+		}
+		name, value, isOpt := directiveOption(line)
+		switch {
+		case isOpt && num > 0:
+			if d.options == nil {
+				d.options = make(map[string]string)
+			}
+			opt = name
+			d.options[opt] = value
+		case opt != "":
+			d.options[opt] += "\n" + line
+		default:
+			args = append(args, line)
+		}
+	}
+	d.argument = strings.TrimSpace(strings.Join(args, "\n"))
+	return d, true
+}
+
+// directiveOption splits a field list line ":name: value" of a directive
+// option. ok is false if line does not begin with a field marker.
+func directiveOption(line string) (name, value string, ok bool) {
+	if len(line) < 3 || line[0] != ':' {
+		return "", "", false
+	}
+	end := strings.Index(line[1:], ":")
+	if end < 1 {
+		return "", "", false
+	}
+	name = strings.ToLower(line[1 : end+1])
+	return name, strings.TrimSpace(line[end+2:]), true
+}
+
+// directiveContent replaces the content of the directive d at the explicit
+// markup start i with the content lines of the input, and skips the tokens of
+// the content. The content of a directive parsed from the text of a comment
+// block ends at the first line that is not a paragraph, and the indentation of
+// its lines is lost. The content lines of the input end at the first line that
+// is not indented relative to i, their common indentation is removed. The
+// lines of the directive block are kept in d.block.
+func (t *Tree) directiveContent(d *directive, i *item) {
+	lines := strings.Split(t.text, "\n")
+	start := int(i.Line) - 1
+	if start < 0 || start >= len(lines) {
+		return
+	}
+	markIndent := lineIndent(lines[start], t.tabWidth())
+	end := start + 1
+	for num := start + 1; num < len(lines); num++ {
+		if lineIsBlank(lines[num]) {
+			continue
+		}
+		if lineIndent(lines[num], t.tabWidth()) <= markIndent {
+			break
+		}
+		end = num + 1
+	}
+	for t.peek(1).Type != itemEOF && t.peek(1).Line <= Line(end) {
+		t.next(1)
+	}
+	d.block = strings.Join(lines[start:end], "\n")
+	// The content begins after the first blank line of the block.
+	first := start + 1
+	for first < end && !lineIsBlank(lines[first]) {
+		first++
+	}
+	for first < end && lineIsBlank(lines[first]) {
+		first++
+	}
+	if first >= end {
+		d.content, d.contentLine, d.contentIndent = "", 0, 0
+		return
+	}
+	d.content, d.contentIndent = t.dedentBlock(lines[first:end],
+		Line(first+1))
+	d.contentLine = first - start
+}
@@ -0,0 +1,262 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+var directiveDiagnosticTests = []struct {
+	name    string
+	input   string
+	dName   string
+	msgType parserMessage
+	text    string // The text of the system message paragraph
+	block   string // The text of the system message literal block
+}{
+	{
+		name:    "unknown directive",
+		input:   ".. frobnicate:: arg\n   :level: 3\n\n   Content.\n",
+		dName:   "frobnicate",
+		msgType: errorUnknownDirectiveType,
+		text:    "Unknown directive type \"frobnicate\".",
+		block:   ".. frobnicate:: arg\n   :level: 3\n\n   Content.",
+	},
+	{
+		name:    "missing required content",
+		input:   ".. compound::\n",
+		dName:   "compound",
+		msgType: errorDirectiveContent,
+		text: "Content block expected for the \"compound\" " +
+			"directive; none found.",
+		block: ".. compound::",
+	},
+}
+
+func TestParseDirectiveDiagnostics(t *testing.T) {
+	for _, tt := range directiveDiagnosticTests {
+		tr, _ := Parse(tt.name, tt.input)
+		if len(tr.Nodes) != 2 {
+			t.Errorf("Test: %q\n\t    Got: len(Nodes) = %d, "+
+				"Expect: 2\n\n", tt.name, len(tr.Nodes))
+			continue
+		}
+		d, ok := tr.Nodes[0].(*DirectiveNode)
+		if !ok || d.Name != tt.dName || d.Line != 1 {
+			t.Errorf("Test: %q\n\t    Got: Nodes[0] = %#v, "+
+				"Expect: DirectiveNode %q on line 1\n\n", tt.name,
+				tr.Nodes[0], tt.dName)
+		}
+		s, ok := tr.Nodes[1].(*SystemMessageNode)
+		if !ok {
+			t.Errorf("Test: %q\n\t    Got: Nodes[1] = %s, "+
+				"Expect: NodeSystemMessage\n\n", tt.name,
+				tr.Nodes[1].NodeType())
+			continue
+		}
+		if s.MessageType != tt.msgType || s.Severity != levelError {
+			t.Errorf("Test: %q\n\t    Got: MessageType = %s, "+
+				"Severity = %s, Expect: %s, %s\n\n", tt.name,
+				s.MessageType, s.Severity, tt.msgType, levelError)
+		}
+		if len(s.NodeList) != 2 {
+			t.Errorf("Test: %q\n\t    Got: len(NodeList) = %d, "+
+				"Expect: 2\n\n", tt.name, len(s.NodeList))
+			continue
+		}
+		if p := s.NodeList[0].(*ParagraphNode); p.Text != tt.text {
+			t.Errorf("Test: %q\n\t    Got: Text = %q, Expect: %q\n\n",
+				tt.name, p.Text, tt.text)
+		}
+		if lb := s.NodeList[1].(*LiteralBlockNode); lb.Text != tt.block {
+			t.Errorf("Test: %q\n\t    Got: Text = %q, Expect: %q\n\n",
+				tt.name, lb.Text, tt.block)
+		}
+		if len(tr.Diagnostics) != 1 ||
+			tr.Diagnostics[0].MessageType != tt.msgType {
+			t.Errorf("Test: %q\n\t    Got: Diagnostics = %v, "+
+				"Expect: one %s\n\n", tt.name, tr.Diagnostics,
+				tt.msgType)
+		}
+	}
+}
+
+func TestDirectiveNodeJSONRoundTrip(t *testing.T) {
+	tr, _ := Parse("unknown", ".. frobnicate:: arg\n   :level: 3\n\n   Body.")
+	d := tr.Nodes[0].(*DirectiveNode)
+	expect := map[string]string{"level": "3"}
+	if !reflect.DeepEqual(d.Options, expect) || d.Argument != "arg" ||
+		d.Content != "Body." {
+		t.Fatalf("Got: %#v, Expect: argument \"arg\", options %v, "+
+			"content \"Body.\"", d, expect)
+	}
+	data, err := json.Marshal(NodeList{d})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got NodeList
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal: %s\n%s", err, data)
+	}
+	if !reflect.DeepEqual(got, NodeList{d}) {
+		t.Errorf("Got: %#v\n\t    Expect: %#v", got[0], d)
+	}
+}
+
+// versionHandler is a custom directive handler that parses the content of the
+// "version" directive into a paragraph with the version in its classes.
+type versionHandler struct{}
+
+func (versionHandler) Name() string { return "Version" }
+
+func (versionHandler) Parse(d *DirectiveNode) (Node, []Diagnostic) {
+	if d.Content == "" {
+		return nil, []Diagnostic{{MessageType: errorDirectiveContent}}
+	}
+	p := &ParagraphNode{Type: NodeParagraph, Text: d.Content,
+		Length: len(d.Content), Line: d.Line + 2, StartPosition: 4}
+	p.Classes = []string{className("version " + d.Argument)}
+	return p, nil
+}
+
+var directiveHandlerTests = []struct {
+	name    string
+	input   string
+	nType   NodeType      // The type of the first node
+	msgType parserMessage // The type of the diagnostic, if any
+}{
+	{
+		name:  "custom directive",
+		input: ".. version:: 1.2\n\n   Changed the parser.\n\nAfter.",
+		nType: NodeParagraph,
+	},
+	{
+		name:    "custom directive diagnostic",
+		input:   ".. version:: 1.2\n\nAfter.",
+		nType:   NodeSystemMessage,
+		msgType: errorDirectiveContent,
+	},
+}
+
+func TestParseDirectiveHandler(t *testing.T) {
+	opts := &ParseOptions{Directives: []DirectiveHandler{versionHandler{}}}
+	for _, tt := range directiveHandlerTests {
+		tr, _ := ParseWithOptions(tt.name, tt.input, opts)
+		if len(tr.Nodes) != 2 {
+			t.Errorf("Test: %q\n\t    Got: len(Nodes) = %d, "+
+				"Expect: 2\n\n", tt.name, len(tr.Nodes))
+			continue
+		}
+		if tr.Nodes[0].NodeType() != tt.nType {
+			t.Errorf("Test: %q\n\t    Got: Nodes[0] = %s, Expect: %s\n\n",
+				tt.name, tr.Nodes[0].NodeType(), tt.nType)
+			continue
+		}
+		if tr.Nodes[0].IDNumber() == 0 ||
+			tr.Nodes[0].IDNumber() >= tr.Nodes[1].IDNumber() {
+			t.Errorf("Test: %q\n\t    Got: IDs = %d, %d, Expect: "+
+				"increasing IDs\n\n", tt.name,
+				tr.Nodes[0].IDNumber(), tr.Nodes[1].IDNumber())
+		}
+		if tt.msgType == parserMessageNil {
+			p := tr.Nodes[0].(*ParagraphNode)
+			if p.Text != "Changed the parser." ||
+				!reflect.DeepEqual(p.Classes, []string{"version-1-2"}) {
+				t.Errorf("Test: %q\n\t    Got: %#v\n\n", tt.name, p)
+			}
+			if len(tr.Diagnostics) != 0 {
+				t.Errorf("Test: %q\n\t    Got: Diagnostics = %v, "+
+					"Expect: none\n\n", tt.name, tr.Diagnostics)
+			}
+			continue
+		}
+		if len(tr.Diagnostics) != 1 ||
+			tr.Diagnostics[0].MessageType != tt.msgType ||
+			tr.Diagnostics[0].Line != 1 ||
+			tr.Diagnostics[0].Node != tr.Nodes[0] {
+			t.Errorf("Test: %q\n\t    Got: Diagnostics = %v, Expect: "+
+				"%s on line 1\n\n", tt.name, tr.Diagnostics,
+				tt.msgType)
+		}
+	}
+}
+
+// uriImageHandler replaces the built-in image directive.
+type uriImageHandler struct{}
+
+func (uriImageHandler) Name() string { return "image" }
+
+func (uriImageHandler) Parse(d *DirectiveNode) (Node, []Diagnostic) {
+	return &ImageNode{Type: NodeImage, URI: "images/" + d.Argument,
+		Line: d.Line, StartPosition: d.StartPosition}, nil
+}
+
+func TestParseDirectiveHandlerReplacesBuiltin(t *testing.T) {
+	opts := &ParseOptions{Directives: []DirectiveHandler{uriImageHandler{}}}
+	tr, _ := ParseWithOptions("image", ".. image:: x.png\n", opts)
+	if len(tr.Nodes) != 1 {
+		t.Fatalf("Got: len(Nodes) = %d, Expect: 1", len(tr.Nodes))
+	}
+	img, ok := tr.Nodes[0].(*ImageNode)
+	if !ok || img.URI != "images/x.png" {
+		t.Errorf("Got: Nodes[0] = %#v, Expect: image \"images/x.png\"",
+			tr.Nodes[0])
+	}
+}
+
+var directiveSectionTests = []struct {
+	name  string
+	input string
+	nodes []NodeType // The types of the top level nodes
+}{
+	{
+		name:  "Unknown directive",
+		input: ".. note::\n\n   Content.\n\nTitle\n=====\n\nBody.\n",
+		nodes: []NodeType{NodeDirective, NodeSystemMessage, NodeSection},
+	},
+	{
+		name:  "Topic directive",
+		input: ".. topic:: T\n\n   Content.\n\nTitle\n=====\n\nBody.\n",
+		nodes: []NodeType{NodeTopic, NodeSection},
+	},
+	{
+		name: "Container directive in a section",
+		input: "Top\n===\n\n.. container::\n\n   Content.\n\n" +
+			"Other\n=====\n\nBody.\n",
+		nodes: []NodeType{NodeSection, NodeSection},
+	},
+}
+
+func TestParseSectionAfterDirective(t *testing.T) {
+	for _, tt := range directiveSectionTests {
+		tr, _ := Parse(tt.name, tt.input)
+		if len(tr.Nodes) != len(tt.nodes) {
+			t.Errorf("Test: %q\n\t    Got: len(Nodes) = %d, Expect: %d\n\n",
+				tt.name, len(tr.Nodes), len(tt.nodes))
+			continue
+		}
+		for num, typ := range tt.nodes {
+			if tr.Nodes[num].NodeType() != typ {
+				t.Errorf("Test: %q\n\t    Got: Nodes[%d] = %s, "+
+					"Expect: %s\n\n", tt.name, num,
+					tr.Nodes[num].NodeType(), typ)
+			}
+		}
+		// The section after the directive contains only its own body,
+		// and the content of the directive stays in the directive.
+		sec, ok := tr.Nodes[len(tr.Nodes)-1].(*SectionNode)
+		var body *ParagraphNode
+		if ok && len(sec.NodeList) == 1 {
+			body, _ = sec.NodeList[0].(*ParagraphNode)
+		}
+		if body == nil || sec.Level != 1 || body.Text != "Body." {
+			t.Errorf("Test: %q\n\t    Got: %s, Expect: a level 1 "+
+				"section with the paragraph \"Body.\"\n\n", tt.name,
+				PseudoXML(tr.Nodes[len(tr.Nodes)-1:]))
+		}
+	}
+}
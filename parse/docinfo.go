@@ -0,0 +1,122 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "strings"
+
+// docInfo implements the docutils DocInfo transform. If the first node of
+// Tree.Nodes that is not pre-bibliographic is a field list, it is replaced by
+// a DocInfoNode with the same ID. Fields are matched by name without regard
+// to case.
+func (t *Tree) docInfo() {
+	for num, n := range t.Nodes {
+		if isPreBibliographic(n) {
+			continue
+		}
+		if fl, ok := n.(*FieldListNode); ok {
+			t.Nodes[num] = newDocInfo(fl)
+		}
+		return
+	}
+}
+
+// newDocInfo returns the DocInfoNode of the field list fl. The text of the
+// bibliographic fields is stored in the typed fields of the DocInfoNode, the
+// other fields are added to its NodeList.
+func newDocInfo(fl *FieldListNode) *DocInfoNode {
+	d := &DocInfoNode{
+		ID:            fl.ID,
+		Type:          NodeDocInfo,
+		Line:          fl.Line,
+		StartPosition: fl.StartPosition,
+		Attributes:    fl.Attributes,
+	}
+	for _, n := range fl.NodeList {
+		if f := n.(*FieldNode); !d.setField(f) {
+			d.NodeList = append(d.NodeList, f)
+		}
+	}
+	return d
+}
+
+// setField stores the text of the bibliographic field f in the typed field of
+// d and returns true. False is returned if f is not a bibliographic field, if
+// the typed field is already set, or if the body of f can not be converted.
+// The body of an "Authors" field is one paragraph with the names separated by
+// semicolons or commas, a paragraph per name, or a bullet list with a
+// paragraph per name. The body of the other fields must be one paragraph.
+func (d *DocInfoNode) setField(f *FieldNode) bool {
+	name := normalizeName(f.Name)
+	if name == "authors" {
+		authors := fieldAuthors(f.NodeList)
+		if authors == nil || d.Authors != nil {
+			return false
+		}
+		d.Authors = authors
+		return true
+	}
+	s, ok := map[string]*string{
+		"author":       &d.Author,
+		"organization": &d.Organization,
+		"contact":      &d.Contact,
+		"address":      &d.Address,
+		"version":      &d.Version,
+		"revision":     &d.Revision,
+		"status":       &d.Status,
+		"date":         &d.Date,
+		"copyright":    &d.Copyright,
+	}[name]
+	if !ok || *s != "" || len(f.NodeList) != 1 {
+		return false
+	}
+	p, ok := f.NodeList[0].(*ParagraphNode)
+	if !ok {
+		return false
+	}
+	*s = p.Text
+	return true
+}
+
+// fieldAuthors returns the names in the body nl of an "Authors" field, or nil
+// if the body can not be converted.
+func fieldAuthors(nl NodeList) (authors []string) {
+	if len(nl) == 1 {
+		if b, ok := nl[0].(*BulletListNode); ok {
+			for _, n := range b.NodeList {
+				item := n.(*BulletListItemNode)
+				if len(item.NodeList) != 1 {
+					return nil
+				}
+				p, ok := item.NodeList[0].(*ParagraphNode)
+				if !ok {
+					return nil
+				}
+				authors = append(authors, p.Text)
+			}
+			return authors
+		}
+	}
+	for _, n := range nl {
+		p, ok := n.(*ParagraphNode)
+		if !ok {
+			return nil
+		}
+		authors = append(authors, p.Text)
+	}
+	if len(authors) != 1 {
+		return authors
+	}
+	text, sep := authors[0], ","
+	if strings.Contains(text, ";") {
+		sep = ";"
+	}
+	authors = nil
+	for _, a := range strings.Split(text, sep) {
+		if a = strings.TrimSpace(a); a != "" {
+			authors = append(authors, a)
+		}
+	}
+	return authors
+}
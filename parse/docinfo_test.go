@@ -0,0 +1,117 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDocInfo(t *testing.T) {
+	input := "Title\n=====\n\n:Author: J. Random Hacker\n:Date: 2001-08-16\n" +
+		":Authors: Me; Myself; I\n:Dedication: For you.\n\nParagraph."
+	tr, errors := ParseWithOptions("docinfo", input,
+		&ParseOptions{PromoteTitle: true, DocInfo: true})
+	if len(errors) != 0 {
+		t.Errorf("Got: len(errors) = %d, Expect: 0", len(errors))
+	}
+	if len(tr.Nodes) != 2 {
+		t.Fatalf("Got: len(Nodes) = %d, Expect: 2", len(tr.Nodes))
+	}
+	d, ok := tr.Nodes[0].(*DocInfoNode)
+	if !ok {
+		t.Fatalf("Got: Nodes[0] = %s, Expect: NodeDocInfo",
+			tr.Nodes[0].NodeType())
+	}
+	if d.Author != "J. Random Hacker" || d.Date != "2001-08-16" {
+		t.Errorf("Got: Author = %q, Date = %q, Expect: %q, %q", d.Author,
+			d.Date, "J. Random Hacker", "2001-08-16")
+	}
+	if authors := []string{"Me", "Myself", "I"}; !reflect.DeepEqual(d.Authors,
+		authors) {
+		t.Errorf("Got: Authors = %q, Expect: %q", d.Authors, authors)
+	}
+	if len(d.NodeList) != 1 || d.NodeList[0].(*FieldNode).Name != "Dedication" {
+		t.Errorf("Got: NodeList = %#v, Expect: the Dedication field",
+			d.NodeList)
+	}
+	if d.Line != 4 {
+		t.Errorf("Got: Line = %d, Expect: 4", d.Line)
+	}
+	if errs := tr.Validate(); len(errs) != 0 {
+		t.Errorf("Got: Validate() = %v, Expect: no errors", errs)
+	}
+}
+
+var docInfoTests = []struct {
+	name    string
+	input   string
+	opts    *ParseOptions
+	docInfo bool // Expected if Nodes[0] is a DocInfoNode
+}{
+	{
+		name:    "Comment before the field list",
+		input:   ".. A comment\n\n:Author: Me",
+		opts:    &ParseOptions{DocInfo: true},
+		docInfo: true,
+	},
+	{
+		name:  "Paragraph before the field list",
+		input: "Paragraph.\n\n:Author: Me",
+		opts:  &ParseOptions{DocInfo: true},
+	},
+	{
+		name:  "Option not set",
+		input: ":Author: Me",
+		opts:  &ParseOptions{},
+	},
+	{
+		name:  "Title not promoted",
+		input: "Title\n=====\n\n:Author: Me",
+		opts:  &ParseOptions{DocInfo: true},
+	},
+}
+
+func TestParseDocInfoPosition(t *testing.T) {
+	for _, tt := range docInfoTests {
+		tr, _ := ParseWithOptions(tt.name, tt.input, tt.opts)
+		var found bool
+		Walk(tr.Nodes, func(n Node) bool {
+			if _, ok := n.(*DocInfoNode); ok {
+				found = true
+			}
+			return true
+		})
+		if found != tt.docInfo {
+			t.Errorf("Test: %q\n\t    Got: DocInfoNode = %t, Expect: %t\n\n",
+				tt.name, found, tt.docInfo)
+		}
+	}
+}
+
+func TestDocInfoFieldConversion(t *testing.T) {
+	input := ":Author: One\n:Author: Two\n:Version:\n   - 1\n" +
+		":Authors:\n   - Ann\n   - Bob\n:Address: Main St\n   Town\n"
+	tr, _ := ParseWithOptions("conversion", input,
+		&ParseOptions{DocInfo: true})
+	d := tr.Nodes[0].(*DocInfoNode)
+	if d.Author != "One" || d.Version != "" || d.Address != "Main St\nTown" {
+		t.Errorf("Got: Author = %q, Version = %q, Address = %q, Expect: "+
+			"%q, %q, %q", d.Author, d.Version, d.Address, "One", "",
+			"Main St\nTown")
+	}
+	if authors := []string{"Ann", "Bob"}; !reflect.DeepEqual(d.Authors,
+		authors) {
+		t.Errorf("Got: Authors = %q, Expect: %q", d.Authors, authors)
+	}
+	var names []string
+	for _, n := range d.NodeList {
+		names = append(names, n.(*FieldNode).Name)
+	}
+	if expect := []string{"Author", "Version"}; !reflect.DeepEqual(names,
+		expect) {
+		t.Errorf("Got: generic fields %q, Expect: %q", names, expect)
+	}
+}
@@ -0,0 +1,115 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// fieldMarkerEnd returns the index in text after the field marker ":name:"
+// beginning text, or -1 if text does not begin with a field marker. The name
+// must not begin with a colon or whitespace, and the closing colon must not
+// follow whitespace. The marker must be followed by whitespace or end text. A
+// colon inside the name must not be followed by whitespace or a backquote, it
+// can be escaped with a backslash.
+func fieldMarkerEnd(text string) int {
+	if len(text) < 3 || text[0] != ':' || text[1] == ':' || text[1] == ' ' ||
+		text[1] == '\t' {
+		return -1
+	}
+	for j := 1; j < len(text); j++ {
+		switch {
+		case text[j] == '\\':
+			j++
+		case text[j] != ':':
+		case j+1 == len(text) || text[j+1] == ' ' || text[j+1] == '\t':
+			if text[j-1] == ' ' || text[j-1] == '\t' {
+				return -1
+			}
+			return j + 1
+		case text[j+1] == '`':
+			return -1
+		}
+	}
+	return -1
+}
+
+// fieldList returns the FieldListNode of the field list beginning with the
+// field marker at the start of the paragraph or definition term i, and skips
+// the tokens of the list. The list contains the fields beginning at the
+// indentation of i, the fields may be separated by blank lines. The list ends
+// at the first line that is not blank and is not a field at that indentation.
+func (t *Tree) fieldList(i *item) Node {
+	lines := strings.Split(t.text, "\n")
+	n := newFieldList(i, &t.id)
+	indent := lineIndent(lines[i.Line-1], t.tabWidth())
+	end := int(i.Line) - 1
+	for num := end; num < len(lines); num++ {
+		if lineIsBlank(lines[num]) {
+			continue
+		}
+		text := strings.TrimLeft(lines[num], " \t")
+		if lineIndent(lines[num], t.tabWidth()) != indent ||
+			fieldMarkerEnd(text) == -1 {
+			break
+		}
+		var f *FieldNode
+		f, end = t.field(lines, num, indent)
+		n.NodeList.append(f)
+		num = end - 1
+	}
+	for t.peek(1).Type != itemEOF && t.peek(1).Line <= Line(end) {
+		t.next(1)
+	}
+	return n
+}
+
+// field returns the FieldNode of the field marker on the line with index num
+// of lines, and the index of the line after the body of the field. The body
+// begins with the text after the marker, and ends at the first line that is
+// not blank and is not indented more than indent, the indentation of the
+// marker. Like in docutils, the common indentation of the lines following the
+// marker is removed from the body, and the text after the marker is the first
+// line of the body. The body is parsed into the NodeList of the FieldNode.
+func (t *Tree) field(lines []string, num, indent int) (*FieldNode, int) {
+	line := lines[num]
+	text := strings.TrimLeft(line, " \t")
+	markEnd := fieldMarkerEnd(text)
+	pos := utf8.RuneCountInString(line) - utf8.RuneCountInString(text) + 1
+	f := newField(&item{
+		Text:          text[1 : markEnd-1],
+		Line:          Line(num + 1),
+		StartPosition: StartPosition(pos),
+	}, &t.id)
+	end := num + 1
+	for k := end; k < len(lines); k++ {
+		if lineIsBlank(lines[k]) {
+			continue
+		}
+		if lineIndent(lines[k], t.tabWidth()) <= indent {
+			break
+		}
+		end = k + 1
+	}
+	first := strings.TrimSpace(text[markEnd:])
+	rest := lines[num+1 : end]
+	pad := utf8.RuneCountInString(line) -
+		utf8.RuneCountInString(strings.TrimLeft(text[markEnd:], " \t"))
+	if _, restIndent := dedentLines(rest, t.tabWidth()); restIndent > 0 {
+		pad = restIndent
+	}
+	bodyLines := []string{""}
+	if first != "" {
+		bodyLines[0] = strings.Repeat(" ", pad) + first
+	}
+	body, bodyIndent := t.dedentBlock(append(bodyLines, rest...), Line(num+1))
+	// A body beginning on the line after the marker is parsed from its
+	// first line.
+	trimmed := strings.TrimLeft(body, "\n")
+	start := Line(num + 1 + len(body) - len(trimmed))
+	f.NodeList = t.parseNested(trimmed, start, bodyIndent)
+	return f, end
+}
@@ -0,0 +1,144 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+var fieldMarkerEndTests = []struct {
+	text string
+	end  int // Expected return of fieldMarkerEnd()
+}{
+	{":Author: Me", 8},
+	{":Date:", 6},
+	{":Two words: text", 11},
+	{`:a\: b: text`, 7},
+	{":a:b: text", 5},
+	{"::", -1},
+	{": a: text", -1},
+	{":a : text", -1},
+	{":emphasis:`text`", -1},
+	{":a:text", -1},
+	{"Text", -1},
+}
+
+func TestFieldMarkerEnd(t *testing.T) {
+	for _, tt := range fieldMarkerEndTests {
+		if end := fieldMarkerEnd(tt.text); end != tt.end {
+			t.Errorf("Test: %q\n\t    Got: %d, Expect: %d\n\n", tt.text,
+				end, tt.end)
+		}
+	}
+}
+
+type expectField struct {
+	name  string
+	line  Line
+	paras []string // Expected text of the paragraphs of the body
+}
+
+var fieldListTests = []struct {
+	name   string
+	input  string
+	fields []expectField
+	types  []NodeType // Expected types of the top level nodes
+}{
+	{
+		name:  "One line fields",
+		input: ":Author: Me\n:Date: 2001-08-16\n\nParagraph.",
+		fields: []expectField{
+			{"Author", 1, []string{"Me"}},
+			{"Date", 2, []string{"2001-08-16"}},
+		},
+		types: []NodeType{NodeFieldList, NodeParagraph},
+	},
+	{
+		name:  "Indented body",
+		input: ":Abstract: One\n   two.\n\n   Three.\n:Version: 1\n",
+		fields: []expectField{
+			{"Abstract", 1, []string{"One\ntwo.", "Three."}},
+			{"Version", 5, []string{"1"}},
+		},
+		types: []NodeType{NodeFieldList},
+	},
+	{
+		name:  "Body on the next line and blank lines between fields",
+		input: ":Abstract:\n\n    Text.\n\n:Empty:\n\nParagraph.",
+		fields: []expectField{
+			{"Abstract", 1, []string{"Text."}},
+			{"Empty", 5, nil},
+		},
+		types: []NodeType{NodeFieldList, NodeParagraph},
+	},
+}
+
+func TestParseFieldList(t *testing.T) {
+	for _, tt := range fieldListTests {
+		tr, errors := Parse(tt.name, tt.input)
+		if len(errors) != 0 {
+			t.Errorf("Test: %q\n\t    Got: len(errors) = %d, Expect: 0\n\n",
+				tt.name, len(errors))
+		}
+		if len(tr.Nodes) != len(tt.types) {
+			t.Errorf("Test: %q\n\t    Got: len(Nodes) = %d, Expect: %d\n\n",
+				tt.name, len(tr.Nodes), len(tt.types))
+			continue
+		}
+		for num, n := range tr.Nodes {
+			if n.NodeType() != tt.types[num] {
+				t.Errorf("Test: %q\n\t    Got: Nodes[%d] = %s, "+
+					"Expect: %s\n\n", tt.name, num, n.NodeType(),
+					tt.types[num])
+			}
+		}
+		fl, ok := tr.Nodes[0].(*FieldListNode)
+		if !ok || len(fl.NodeList) != len(tt.fields) {
+			t.Errorf("Test: %q\n\t    Got: Nodes[0] = %#v, Expect: field "+
+				"list with %d fields\n\n", tt.name, tr.Nodes[0],
+				len(tt.fields))
+			continue
+		}
+		for num, ef := range tt.fields {
+			f := fl.NodeList[num].(*FieldNode)
+			if f.Name != ef.name || f.Line != ef.line ||
+				len(f.NodeList) != len(ef.paras) {
+				t.Errorf("Test: %q\n\t    Got: field %q on line %d with "+
+					"%d nodes, Expect: field %q on line %d with %d "+
+					"nodes\n\n", tt.name, f.Name, f.Line,
+					len(f.NodeList), ef.name, ef.line, len(ef.paras))
+				continue
+			}
+			for k, text := range ef.paras {
+				p, ok := f.NodeList[k].(*ParagraphNode)
+				if !ok || p.Text != text {
+					t.Errorf("Test: %q\n\t    Got: %#v, Expect: "+
+						"paragraph %q\n\n", tt.name, f.NodeList[k],
+						text)
+				}
+			}
+		}
+		if errs := tr.Validate(); len(errs) != 0 {
+			t.Errorf("Test: %q\n\t    Got: Validate() = %v, Expect: no "+
+				"errors\n\n", tt.name, errs)
+		}
+	}
+}
+
+func TestParseFieldListInBlockQuote(t *testing.T) {
+	tr, _ := Parse("field-list-in-block-quote",
+		"Paragraph.\n\n    :a: b\n    :c: d\n\n    Quote.")
+	if len(tr.Nodes) != 2 {
+		t.Fatalf("Got: len(Nodes) = %d, Expect: 2", len(tr.Nodes))
+	}
+	bq, ok := tr.Nodes[1].(*BlockQuoteNode)
+	if !ok || len(bq.NodeList) != 2 {
+		t.Fatalf("Got: Nodes[1] = %#v, Expect: block quote with 2 nodes",
+			tr.Nodes[1])
+	}
+	fl, ok := bq.NodeList[0].(*FieldListNode)
+	if !ok || len(fl.NodeList) != 2 || fl.StartPosition != 5 {
+		t.Errorf("Got: %#v, Expect: field list with 2 fields at column 5",
+			bq.NodeList[0])
+	}
+}
@@ -0,0 +1,104 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FootnoteReference is a reference to a footnote, like "[1]_", "[#]_",
+// "[#name]_", or "[*]_". The FootnoteRefNodes parsed by
+// ParseOptions.ParseInline are collected in Tree.FootnoteReferences by
+// resolveReferences.
+type FootnoteReference struct {
+	// Label is the label of the reference without the brackets.
+	Label string
+
+	// Line is the line of the reference.
+	Line
+
+	// Footnote is the footnote the reference refers to, or nil if no
+	// footnote matches the reference.
+	Footnote *FootnoteNode
+}
+
+// splitBracketLabel splits the text of a footnote or citation into the label
+// between the brackets and the text of the body.
+func splitBracketLabel(text string) (label, body string) {
+	end := strings.Index(text, "]")
+	lines := strings.Split(text[end+1:], "\n")
+	for num, line := range lines {
+		lines[num] = strings.TrimSpace(line)
+	}
+	return text[1:end], strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// footnote returns the FootnoteNode of the footnote at item i with label and
+// body text. Manually numbered footnotes get their number here, auto-numbered
+// footnotes are numbered by numberFootnotes after parsing.
+func (t *Tree) footnote(label, body string, i *item) Node {
+	n := newFootnote(i, label, &t.id)
+	if num, err := strconv.Atoi(label); err == nil {
+		n.Number = num
+		n.Names = append(n.Names, label)
+	} else if name := footnoteName(label); name != "" {
+		n.Names = append(n.Names, name)
+	}
+	n.NodeList = t.bracketBody(body, i)
+	return n
+}
+
+// bracketBody returns the NodeList of the body text of the footnote or
+// citation at item i. The body is a single paragraph.
+func (t *Tree) bracketBody(body string, i *item) NodeList {
+	if body == "" {
+		return nil
+	}
+	return NodeList{newParagraph(&item{
+		Text:          body,
+		Length:        len(body),
+		Line:          i.Line,
+		StartPosition: i.StartPosition,
+	}, &t.id)}
+}
+
+// footnoteName returns the normalized reference name of an auto-numbered
+// footnote label like "#name", or an empty string if the label has no name.
+func footnoteName(label string) string {
+	if !strings.HasPrefix(label, "#") {
+		return ""
+	}
+	return strings.ToLower(label[1:])
+}
+
+// numberFootnotes assigns numbers to the auto-numbered footnotes. Like
+// docutils, auto-numbered footnotes are numbered in document order with the
+// lowest numbers not used by a manually numbered footnote. The references to
+// the footnotes are linked by resolveReferences.
+func (t *Tree) numberFootnotes() {
+	var footnotes []*FootnoteNode
+	used := make(map[int]bool)
+	Walk(t.Nodes, func(n Node) bool {
+		if f, ok := n.(*FootnoteNode); ok {
+			footnotes = append(footnotes, f)
+			if f.Number > 0 {
+				used[f.Number] = true
+			}
+		}
+		return true
+	})
+	next := 1
+	for _, f := range footnotes {
+		if !strings.HasPrefix(f.Label, "#") {
+			continue
+		}
+		for used[next] {
+			next++
+		}
+		f.Number = next
+		used[next] = true
+	}
+}
@@ -0,0 +1,88 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+func TestNumberFootnotes(t *testing.T) {
+	input := "See [#]_, [1]_, [#note]_, and [#]_.\n\n" +
+		".. [#] First auto.\n\n" +
+		".. [1] Manual.\n\n" +
+		".. [#note] Named.\n\n" +
+		".. [#] Second auto."
+	tr, _ := ParseWithOptions("TestNumberFootnotes", input,
+		&ParseOptions{ParseInline: true})
+	expect := []struct {
+		label  string
+		number int
+	}{{"#", 2}, {"1", 1}, {"#note", 3}, {"#", 4}}
+	var footnotes []*FootnoteNode
+	for _, n := range tr.Nodes {
+		if f, ok := n.(*FootnoteNode); ok {
+			footnotes = append(footnotes, f)
+		}
+	}
+	if len(footnotes) != len(expect) {
+		t.Fatalf("Got: len(footnotes) = %d, Expect: %d", len(footnotes),
+			len(expect))
+	}
+	for num, f := range footnotes {
+		if f.Label != expect[num].label || f.Number != expect[num].number {
+			t.Errorf("Got: footnotes[%d] = [%s] %d, Expect: [%s] %d",
+				num, f.Label, f.Number, expect[num].label,
+				expect[num].number)
+		}
+	}
+	if n := footnotes[2].Names; len(n) != 1 || n[0] != "note" {
+		t.Errorf("Got: Names = %v, Expect: [note]", n)
+	}
+
+	// The references in the order they appear and the numbers of the
+	// footnotes they refer to, as resolved by resolveReferences.
+	refs := []struct {
+		label  string
+		number int
+	}{{"#", 2}, {"1", 1}, {"#note", 3}, {"#", 4}}
+	if len(tr.FootnoteReferences) != len(refs) {
+		t.Fatalf("Got: len(FootnoteReferences) = %d, Expect: %d",
+			len(tr.FootnoteReferences), len(refs))
+	}
+	for num, r := range tr.FootnoteReferences {
+		if r.Label != refs[num].label || r.Footnote == nil ||
+			r.Footnote.Number != refs[num].number {
+			t.Errorf("Got: FootnoteReferences[%d] = %+v, "+
+				"Expect: [%s] to footnote %d", num, r,
+				refs[num].label, refs[num].number)
+		}
+	}
+}
+
+func TestFootnoteReferencesUnresolved(t *testing.T) {
+	input := "See [2]_ and [*]_.\n\n.. [1] Manual.\n\n.. [*] Symbol."
+	tr, _ := ParseWithOptions("TestFootnoteReferencesUnresolved", input,
+		&ParseOptions{ParseInline: true})
+	if len(tr.FootnoteReferences) != 2 {
+		t.Fatalf("Got: len(FootnoteReferences) = %d, Expect: 2",
+			len(tr.FootnoteReferences))
+	}
+	if r := tr.FootnoteReferences[0]; r.Label != "2" || r.Footnote != nil {
+		t.Errorf("Got: FootnoteReferences[0] = %+v, Expect: [2] to no "+
+			"footnote", r)
+	}
+	if r := tr.FootnoteReferences[1]; r.Label != "*" || r.Footnote == nil ||
+		r.Footnote.Symbol != "*" {
+		t.Errorf("Got: FootnoteReferences[1] = %+v, Expect: [*] to the "+
+			"symbol footnote", r)
+	}
+}
+
+func TestFootnoteReferencesWithoutInline(t *testing.T) {
+	tr, _ := Parse("TestFootnoteReferencesWithoutInline",
+		"See [1]_.\n\n.. [1] Manual.")
+	if len(tr.FootnoteReferences) != 0 {
+		t.Errorf("Got: len(FootnoteReferences) = %d, Expect: 0",
+			len(tr.FootnoteReferences))
+	}
+}
@@ -0,0 +1,54 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "strconv"
+
+// IDOptions are the options of Tree.AssignIDs.
+type IDOptions struct {
+	// Prefix is added to the beginning of every section id, for example
+	// to keep the ids of several documents on one page apart.
+	Prefix string
+}
+
+// AssignIDs sets the id of each section of the document to an id made from
+// its title, so renderers can link to the sections with stable ids. Like the
+// make_id function of docutils, the title without inline markup is lowercased
+// and runs of characters other than ASCII letters and digits are replaced by a
+// hyphen. A title without letters or digits gets the id "section".
+//
+// The ids are unique within the document. An id already used by a preceding
+// section, or by a footnote, citation, or reference, gets the numeric suffix
+// "-1", "-2", and so on, for example "my-section-1". The id replaces the Ids of
+// the SectionNode, so calling AssignIDs again with other options gives the
+// sections new ids.
+func (t *Tree) AssignIDs(opts IDOptions) {
+	used := make(map[string]bool)
+	var sections []*SectionNode
+	Walk(t.Nodes, func(n Node) bool {
+		if s, ok := n.(*SectionNode); ok {
+			sections = append(sections, s)
+			return true
+		}
+		if a := n.Attrs(); a != nil {
+			for _, id := range a.Ids {
+				used[id] = true
+			}
+		}
+		return true
+	})
+	for _, s := range sections {
+		base := className(plainInline(s.Title.Text))
+		if base == "" {
+			base = "section"
+		}
+		id := opts.Prefix + base
+		for num := 1; used[id]; num++ {
+			id = opts.Prefix + base + "-" + strconv.Itoa(num)
+		}
+		used[id] = true
+		s.Ids = []string{id}
+	}
+}
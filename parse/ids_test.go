@@ -0,0 +1,90 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"reflect"
+	"testing"
+)
+
+var assignIDsTests = []struct {
+	name   string
+	input  string
+	opts   IDOptions
+	expect []string // The ids of the sections in document order
+}{
+	{
+		name:   "Duplicate titles",
+		input:  "My Section\n==========\n\nMy Section\n==========\n",
+		expect: []string{"my-section", "my-section-1"},
+	},
+	{
+		name: "Suffix already used",
+		input: "My Section\n==========\n\nMy Section 1\n============\n\n" +
+			"My Section\n==========\n",
+		expect: []string{"my-section", "my-section-1", "my-section-2"},
+	},
+	{
+		name:   "Inline markup and punctuation",
+		input:  "The *Go* -- language!\n=====================\n",
+		expect: []string{"the-go-language"},
+	},
+	{
+		name:   "No letters",
+		input:  "1.2\n===\n\n?!\n--\n",
+		expect: []string{"section", "section-1"},
+	},
+	{
+		name:   "Prefix",
+		input:  "Intro\n=====\n\nUsage\n-----\n\nIntro\n=====\n",
+		opts:   IDOptions{Prefix: "doc-"},
+		expect: []string{"doc-intro", "doc-usage", "doc-intro-1"},
+	},
+}
+
+func TestTreeAssignIDs(t *testing.T) {
+	for _, tt := range assignIDsTests {
+		tr, _ := Parse(tt.name, tt.input)
+		tr.AssignIDs(tt.opts)
+		var ids []string
+		Walk(tr.Nodes, func(n Node) bool {
+			if s, ok := n.(*SectionNode); ok {
+				ids = append(ids, s.Ids...)
+			}
+			return true
+		})
+		if !reflect.DeepEqual(ids, tt.expect) {
+			t.Errorf("Test: %q\n\t    Got: %q, Expect: %q\n\n", tt.name,
+				ids, tt.expect)
+		}
+		// The ids are replaced when they are assigned again.
+		tr.AssignIDs(tt.opts)
+		var again []string
+		Walk(tr.Nodes, func(n Node) bool {
+			if s, ok := n.(*SectionNode); ok {
+				again = append(again, s.Ids...)
+			}
+			return true
+		})
+		if !reflect.DeepEqual(again, tt.expect) {
+			t.Errorf("Test: %q\n\t    Got: %q after assigning the ids "+
+				"again, Expect: %q\n\n", tt.name, again, tt.expect)
+		}
+	}
+}
+
+// checkIDsInDocumentOrder reports an error for each node of nl whose ID is not
+// its position in document order, counted from 1.
+func checkIDsInDocumentOrder(t *testing.T, nl NodeList) {
+	id := 0
+	Walk(nl, func(n Node) bool {
+		id++
+		if got := reflect.ValueOf(n).Elem().FieldByName("ID").Int(); got !=
+			int64(id) {
+			t.Errorf("Got: %s ID = %d, Expect: %d", n.NodeType(), got, id)
+		}
+		return true
+	})
+}
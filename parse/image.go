@@ -0,0 +1,46 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "strings"
+
+// imageHandler is the DirectiveHandler of the built-in image directive.
+type imageHandler struct{}
+
+// Name returns the name of the image directive.
+func (imageHandler) Name() string {
+	return "image"
+}
+
+// Parse returns the ImageNode of the image directive d. The whitespace of the
+// URI argument is removed, like in hyperlink targets. An error is returned if
+// the URI is missing.
+func (imageHandler) Parse(d *DirectiveNode) (Node, []Diagnostic) {
+	uri := removeWhitespace(d.Argument)
+	if uri == "" {
+		return nil, []Diagnostic{{MessageType: errorImageDirectiveArgument}}
+	}
+	return &ImageNode{
+		Type:          NodeImage,
+		URI:           uri,
+		Target:        imageTarget(d.Options["target"]),
+		Line:          d.Line,
+		StartPosition: d.StartPosition,
+	}, nil
+}
+
+// imageTarget normalizes the value of the :target: option. The whitespace of a
+// URI is removed, and the whitespace of a reference name is collapsed.
+func imageTarget(target string) string {
+	if strings.HasSuffix(target, "_") && !strings.HasSuffix(target, `\_`) {
+		return strings.Join(strings.Fields(target), " ")
+	}
+	return removeWhitespace(target)
+}
+
+// removeWhitespace returns s with all whitespace removed.
+func removeWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
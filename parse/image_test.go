@@ -0,0 +1,152 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+var imageDirectiveTests = []struct {
+	name    string
+	input   string
+	uri     string        // The expected URI of the image
+	target  string        // The expected target of the image
+	message parserMessage // The expected system message
+}{
+	{
+		name:  "Image without target",
+		input: ".. image:: x.png\n",
+		uri:   "x.png",
+	},
+	{
+		name:   "Image with URI target",
+		input:  ".. image:: x.png\n   :target: http://example.com\n",
+		uri:    "x.png",
+		target: "http://example.com",
+	},
+	{
+		name:   "Image with reference name target",
+		input:  ".. image:: x.png\n   :target: Some  Name_\n",
+		uri:    "x.png",
+		target: "Some Name_",
+	},
+	{
+		name:    "Image without URI",
+		input:   ".. image::\n",
+		message: errorImageDirectiveArgument,
+	},
+}
+
+func TestParseImageDirective(t *testing.T) {
+	for _, tt := range imageDirectiveTests {
+		tr, errors := Parse(tt.name, tt.input)
+		if len(tr.Nodes) != 1 {
+			t.Errorf("Test: %q\n\t    Got: len(Nodes) = %d, "+
+				"Expect: 1\n\n", tt.name, len(tr.Nodes))
+			continue
+		}
+		if tt.message != parserMessageNil {
+			if len(errors) != 1 {
+				t.Errorf("Test: %q\n\t    Got: len(errors) = %d, "+
+					"Expect: 1\n\n", tt.name, len(errors))
+			} else if m := errors[0].(*SystemMessageNode); m.MessageType != tt.message {
+				t.Errorf("Test: %q\n\t    Got: MessageType = %s, "+
+					"Expect: %s\n\n", tt.name, m.MessageType, tt.message)
+			}
+			continue
+		}
+		img, ok := tr.Nodes[0].(*ImageNode)
+		if !ok {
+			t.Errorf("Test: %q\n\t    Got: Nodes[0] = %s, "+
+				"Expect: NodeImage\n\n", tt.name,
+				tr.Nodes[0].NodeType())
+			continue
+		}
+		if img.URI != tt.uri {
+			t.Errorf("Test: %q\n\t    Got: URI = %q, Expect: %q\n\n",
+				tt.name, img.URI, tt.uri)
+		}
+		if img.Target != tt.target {
+			t.Errorf("Test: %q\n\t    Got: Target = %q, Expect: %q\n\n",
+				tt.name, img.Target, tt.target)
+		}
+		// The image takes the ID of the directive.
+		if img.ID != 1 {
+			t.Errorf("Test: %q\n\t    Got: ID = %d, Expect: 1\n\n",
+				tt.name, img.ID)
+		}
+	}
+}
+
+func TestParseImageDirectiveIDs(t *testing.T) {
+	tr, _ := Parse("image ids", "Para.\n\n.. image:: x.png\n   :name: pic\n\n"+
+		"After.")
+	if len(tr.Nodes) != 3 {
+		t.Fatalf("Got: len(Nodes) = %d, Expect: 3", len(tr.Nodes))
+	}
+	ids := []ID{tr.Nodes[0].(*ParagraphNode).ID, tr.Nodes[1].(*ImageNode).ID,
+		tr.Nodes[2].(*ParagraphNode).ID}
+	if ids[0] != 1 || ids[1] != 2 || ids[2] != 3 {
+		t.Errorf("Got: IDs = %v, Expect: [1 2 3]", ids)
+	}
+}
+
+func TestParseDirectiveNameOption(t *testing.T) {
+	tr, errors := Parse("name option", ".. image:: x.png\n   :name: mylogo\n")
+	if len(errors) != 0 {
+		t.Fatalf("Got: len(errors) = %d, Expect: 0", len(errors))
+	}
+	img, ok := tr.Nodes[0].(*ImageNode)
+	if !ok {
+		t.Fatalf("Got: Nodes[0] = %s, Expect: NodeImage", tr.Nodes[0].NodeType())
+	}
+	if n := tr.Names["mylogo"]; n != img {
+		t.Errorf("Got: Names[%q] = %v, Expect: the image node", "mylogo", n)
+	}
+	if len(img.Names) != 1 || img.Names[0] != "mylogo" {
+		t.Errorf("Got: Names = %q, Expect: %q", img.Names, []string{"mylogo"})
+	}
+	if c := tr.Clone(); c.Names["mylogo"] != c.Nodes[0] {
+		t.Error("Got: Names of the clone refer to the original nodes, " +
+			"Expect: the cloned image node")
+	}
+}
+
+func TestParseDirectiveNameOptionNested(t *testing.T) {
+	input := ".. container::\n\n   .. image:: x.png\n      :name: inner\n"
+	tr, errors := Parse("nested name option", input)
+	if len(errors) != 0 {
+		t.Fatalf("Got: len(errors) = %d, Expect: 0", len(errors))
+	}
+	img, ok := tr.Names["inner"].(*ImageNode)
+	if !ok || img.URI != "x.png" {
+		t.Fatalf("Got: Names[%q] = %v, Expect: the image node", "inner",
+			tr.Names["inner"])
+	}
+	var found bool
+	Walk(tr.Nodes, func(n Node) bool {
+		found = found || n == Node(img)
+		return true
+	})
+	if !found {
+		t.Error("Got: Names refers to a node not in the tree")
+	}
+}
+
+func TestParseDirectiveNameOptionDuplicate(t *testing.T) {
+	input := ".. image:: a.png\n   :name: logo\n\n" +
+		".. container::\n\n   .. image:: b.png\n      :name: Logo\n"
+	tr, errors := Parse("duplicate name option", input)
+	if len(errors) != 1 {
+		t.Fatalf("Got: len(errors) = %d, Expect: 1", len(errors))
+	}
+	m := errors[0].(*SystemMessageNode)
+	if m.MessageType != warningDuplicateExplicitTargetName || m.Line != 6 {
+		t.Errorf("Got: %s on line %d, Expect: %s on line 6",
+			m.MessageType, m.Line, warningDuplicateExplicitTargetName)
+	}
+	if img, ok := tr.Names["logo"].(*ImageNode); !ok || img.URI != "a.png" {
+		t.Errorf("Got: Names[%q] = %v, Expect: the first image",
+			"logo", tr.Names["logo"])
+	}
+}
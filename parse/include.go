@@ -0,0 +1,81 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// IncludeHandler is the DirectiveHandler of the include directive. It is not
+// a built-in directive, since the parser does not read files; it is added to
+// the parser with ParseOptions.Directives and a Resolve function.
+type IncludeHandler struct {
+	// Resolve returns the text of the included file path, the argument of
+	// the directive.
+	Resolve func(path string) (string, error)
+}
+
+// Name returns the name of the include directive.
+func (IncludeHandler) Name() string {
+	return "include"
+}
+
+// Parse returns the node of the include directive d. With the :literal: option
+// the text of the included file becomes a LiteralBlockNode. With the ":code:
+// lang" option it becomes a LiteralBlockNode with the classes "code" and lang,
+// like the code blocks of docutils. Including parsed reStructuredText is not
+// supported yet and is reported as an error. A severe error is returned if the
+// file cannot be resolved.
+func (h IncludeHandler) Parse(d *DirectiveNode) (Node, []Diagnostic) {
+	path := removeWhitespace(d.Argument)
+	if path == "" {
+		return nil, []Diagnostic{{MessageType: errorIncludeDirectiveArgument}}
+	}
+	_, literal := d.Options["literal"]
+	lang, code := d.Options["code"]
+	if !literal && !code {
+		return nil, []Diagnostic{{MessageType: errorIncludeDirectiveParsed}}
+	}
+	if h.Resolve == nil {
+		return nil, []Diagnostic{includeError(d, path, "no resolver")}
+	}
+	text, err := h.Resolve(path)
+	if err != nil {
+		return nil, []Diagnostic{includeError(d, path, err.Error())}
+	}
+	text = strings.TrimRight(text, "\n")
+	n := &LiteralBlockNode{
+		Type:          NodeLiteralBlock,
+		Text:          text,
+		Length:        utf8.RuneCountInString(text),
+		Line:          d.Line,
+		StartPosition: d.StartPosition,
+	}
+	if code {
+		n.Classes = append([]string{"code"}, strings.Fields(lang)...)
+	}
+	return n, nil
+}
+
+// includeError returns the diagnostic of the include directive d if path cannot
+// be resolved. The system message contains the path and the reason.
+func includeError(d *DirectiveNode, path, reason string) Diagnostic {
+	msg := severeIncludeDirectivePath.Message() + "\n" + path + ": " + reason
+	return Diagnostic{
+		MessageType: severeIncludeDirectivePath,
+		Node: &SystemMessageNode{
+			Type:        NodeSystemMessage,
+			Line:        d.Line,
+			MessageType: severeIncludeDirectivePath,
+			Severity:    severeIncludeDirectivePath.Level(),
+			NodeList: NodeList{&ParagraphNode{
+				Type:   NodeParagraph,
+				Text:   msg,
+				Length: len(msg),
+			}},
+		},
+	}
+}
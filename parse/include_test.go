@@ -0,0 +1,116 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// includeFiles are the files of the in-memory include resolver.
+var includeFiles = map[string]string{
+	"example.txt": "Not *parsed*.\n",
+	"example.py":  "def f():\n    return 1\n",
+}
+
+func resolveIncludeFile(path string) (string, error) {
+	text, ok := includeFiles[path]
+	if !ok {
+		return "", errors.New("file not found")
+	}
+	return text, nil
+}
+
+var includeDirectiveTests = []struct {
+	name    string
+	input   string
+	text    string        // The expected text of the literal block
+	classes []string      // The expected classes of the literal block
+	message parserMessage // The expected system message
+}{
+	{
+		name:  "Literal include",
+		input: ".. include:: example.txt\n   :literal:\n",
+		text:  "Not *parsed*.",
+	},
+	{
+		name:    "Code include",
+		input:   ".. include:: example.py\n   :code: python\n",
+		text:    "def f():\n    return 1",
+		classes: []string{"code", "python"},
+	},
+	{
+		name:    "Code include without language",
+		input:   ".. include:: example.py\n   :code:\n",
+		text:    "def f():\n    return 1",
+		classes: []string{"code"},
+	},
+	{
+		name:    "Include without path",
+		input:   ".. include::\n   :literal:\n",
+		message: errorIncludeDirectiveArgument,
+	},
+	{
+		name:    "Parsed include",
+		input:   ".. include:: example.txt\n",
+		message: errorIncludeDirectiveParsed,
+	},
+	{
+		name:    "Include of a missing file",
+		input:   ".. include:: missing.txt\n   :literal:\n",
+		message: severeIncludeDirectivePath,
+	},
+}
+
+func TestParseIncludeDirective(t *testing.T) {
+	opts := &ParseOptions{
+		Directives: []DirectiveHandler{
+			IncludeHandler{Resolve: resolveIncludeFile},
+		},
+	}
+	for _, tt := range includeDirectiveTests {
+		tr, errors := ParseWithOptions(tt.name, tt.input, opts)
+		if len(tr.Nodes) != 1 {
+			t.Errorf("Test: %q\n\t    Got: len(Nodes) = %d, "+
+				"Expect: 1\n\n", tt.name, len(tr.Nodes))
+			continue
+		}
+		if tt.message != parserMessageNil {
+			if len(errors) != 1 {
+				t.Errorf("Test: %q\n\t    Got: len(errors) = %d, "+
+					"Expect: 1\n\n", tt.name, len(errors))
+			} else if m := errors[0].(*SystemMessageNode); m.MessageType != tt.message {
+				t.Errorf("Test: %q\n\t    Got: MessageType = %s, "+
+					"Expect: %s\n\n", tt.name, m.MessageType, tt.message)
+			}
+			continue
+		}
+		if len(errors) != 0 {
+			t.Errorf("Test: %q\n\t    Got: len(errors) = %d, "+
+				"Expect: 0\n\n", tt.name, len(errors))
+		}
+		lb, ok := tr.Nodes[0].(*LiteralBlockNode)
+		if !ok {
+			t.Errorf("Test: %q\n\t    Got: Nodes[0] = %s, "+
+				"Expect: NodeLiteralBlock\n\n", tt.name,
+				tr.Nodes[0].NodeType())
+			continue
+		}
+		if lb.Text != tt.text {
+			t.Errorf("Test: %q\n\t    Got: Text = %q, Expect: %q\n\n",
+				tt.name, lb.Text, tt.text)
+		}
+		if !reflect.DeepEqual(lb.Classes, tt.classes) {
+			t.Errorf("Test: %q\n\t    Got: Classes = %q, Expect: %q\n\n",
+				tt.name, lb.Classes, tt.classes)
+		}
+		// The literal block takes the ID of the directive.
+		if lb.ID != 1 {
+			t.Errorf("Test: %q\n\t    Got: ID = %d, Expect: 1\n\n",
+				tt.name, lb.ID)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "strings"
+
+// The default number of columns between tab stops used when expanding tabs in
+// indentation.
+const defaultTabWidth = 8
+
+// tabWidth returns the number of columns between tab stops set by
+// Options.TabWidth, or defaultTabWidth if it is not set.
+func (t *Tree) tabWidth() int {
+	if t.Options.TabWidth > 0 {
+		return t.Options.TabWidth
+	}
+	return defaultTabWidth
+}
+
+// lineIsBlank returns true if line contains only whitespace.
+func lineIsBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+// lineIndentText returns the whitespace at the start of line.
+func lineIndentText(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// lineIndent returns the width in columns of the whitespace at the start of
+// line. Tabs are expanded to the next tab stop, the tab stops are tabWidth
+// columns apart.
+func lineIndent(line string, tabWidth int) (col int) {
+	for _, r := range lineIndentText(line) {
+		if r == '\t' {
+			col += tabWidth - col%tabWidth
+		} else {
+			col++
+		}
+	}
+	return
+}
+
+// indentIsConsistent returns false if the whitespace at the start of the lines
+// can not be consistently interpreted. This is the case when the indentation of
+// one line is not a prefix of the indentation of another, for example, when a
+// tab is used on one line and spaces on another. Blank lines are ignored.
+func indentIsConsistent(lines []string) bool {
+	var prev string
+	for _, line := range lines {
+		if lineIsBlank(line) {
+			continue
+		}
+		indent := lineIndentText(line)
+		if !strings.HasPrefix(prev, indent) && !strings.HasPrefix(indent, prev) {
+			return false
+		}
+		if len(indent) > len(prev) {
+			prev = indent
+		}
+	}
+	return true
+}
+
+// dedentBlock removes the common indentation from lines and returns the
+// dedented lines joined by newlines. indent is the width in columns of the
+// removed indentation. Tabs in the indentation are expanded before removal.
+// Blank lines do not count toward the common indentation and are returned
+// empty. If the indentation of the lines is inconsistent, an
+// errorInconsistentIndentation system message is added to Tree.Messages,
+// unless Options.TolerateIndentation is set. line is the line number of the
+// first of lines, or zero if it is not known. The lines are not checked again
+// by checkIndentation.
+func (t *Tree) dedentBlock(lines []string, line Line) (dedented string,
+	indent int) {
+
+	if line > 0 {
+		if t.dedentedLines == nil {
+			t.dedentedLines = make(map[Line]bool)
+		}
+		for num := range lines {
+			t.dedentedLines[line+Line(num)] = true
+		}
+	}
+	if !t.Options.TolerateIndentation && !indentIsConsistent(lines) {
+		t.indentMessage(lines, line)
+	}
+	return dedentLines(lines, t.tabWidth())
+}
+
+// dedentLines is like dedentBlock, but does not check the consistency of the
+// indentation.
+func dedentLines(lines []string, tabWidth int) (dedented string, indent int) {
+	indent = -1
+	for _, line := range lines {
+		if lineIsBlank(line) {
+			continue
+		}
+		if i := lineIndent(line, tabWidth); indent == -1 || i < indent {
+			indent = i
+		}
+	}
+	if indent == -1 {
+		indent = 0
+	}
+
+	out := make([]string, len(lines))
+	for num, line := range lines {
+		if lineIsBlank(line) {
+			continue
+		}
+		text := line[len(lineIndentText(line)):]
+		out[num] = strings.Repeat(" ", lineIndent(line, tabWidth)-indent) + text
+	}
+	dedented = strings.Join(out, "\n")
+	return
+}
+
+// checkIndentation adds an errorInconsistentIndentation system message for
+// each indented block of input with indentation that can not be consistently
+// interpreted. An indented block is a run of indented lines, it ends at a line
+// that is not indented. The lines already checked by dedentBlock, like the
+// bodies of list items and literal blocks, are skipped. The system messages are
+// added to Tree.Messages only. Nothing is checked if
+// Options.TolerateIndentation is set.
+func (t *Tree) checkIndentation() {
+	if t.Options.TolerateIndentation {
+		return
+	}
+	lines := strings.Split(t.text, "\n")
+	start, end := -1, -1
+	for num := 0; num <= len(lines); num++ {
+		if num < len(lines) && !t.dedentedLines[Line(num+1)] {
+			if lineIsBlank(lines[num]) {
+				continue
+			}
+			if lineIndentText(lines[num]) != "" {
+				if start == -1 {
+					start = num
+				}
+				end = num + 1
+				continue
+			}
+		}
+		if start != -1 && !indentIsConsistent(lines[start:end]) {
+			t.indentMessage(lines[start:end], Line(start+1))
+		}
+		start = -1
+	}
+}
+
+// indentMessage adds an errorInconsistentIndentation system message containing
+// lines to Tree.Messages and returns the system message. line is the line
+// number of the first of lines, or zero if it is not known.
+func (t *Tree) indentMessage(lines []string, line Line) *SystemMessageNode {
+	s := newSystemMessage(&item{Type: itemSystemMessage, Line: line},
+		errorInconsistentIndentation, &t.id)
+	msg := errorInconsistentIndentation.Message()
+	s.NodeList = append(s.NodeList, newParagraph(&item{
+		Text:   msg,
+		Length: len(msg),
+	}, &t.id))
+	lbText := strings.Join(lines, "\n")
+	s.NodeList = append(s.NodeList, newLiteralBlock(&item{
+		Type:   itemLiteralBlock,
+		Text:   lbText,
+		Length: len(lbText),
+	}, &t.id))
+	t.addMessage(s, 0)
+	return s
+}
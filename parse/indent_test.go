@@ -0,0 +1,195 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+var dedentBlockTests = []struct {
+	name     string
+	lines    []string
+	dedented string // Expected text returned by dedentBlock()
+	indent   int    // Expected indent returned by dedentBlock()
+	nMsgs    int    // Expected number of messages in Tree.Messages
+}{
+	{
+		name:     "Uniform indentation",
+		lines:    []string{"    One", "    Two", "    Three"},
+		dedented: "One\nTwo\nThree",
+		indent:   4,
+	},
+	{
+		name:     "Ragged indentation",
+		lines:    []string{"    One", "      Two", "   Three"},
+		dedented: " One\n   Two\nThree",
+		indent:   3,
+	},
+	{
+		name:     "Ragged indentation with blank line",
+		lines:    []string{"  One", "", "    Two"},
+		dedented: "One\n\n  Two",
+		indent:   2,
+	},
+	{
+		name:     "Tab indentation",
+		lines:    []string{"\tOne", "\tTwo"},
+		dedented: "One\nTwo",
+		indent:   8,
+	},
+	{
+		name:     "Tab and space indentation on the same lines",
+		lines:    []string{"\t  One", "\t    Two"},
+		dedented: "One\n  Two",
+		indent:   10,
+	},
+	{
+		name:     "Tab mixed with spaces",
+		lines:    []string{"        One", "\tTwo"},
+		dedented: "One\nTwo",
+		indent:   8,
+		nMsgs:    1,
+	},
+	{
+		name:     "No indentation",
+		lines:    []string{"One", "Two"},
+		dedented: "One\nTwo",
+	},
+}
+
+func TestTreeDedentBlock(t *testing.T) {
+	for _, tt := range dedentBlockTests {
+		tr := New(tt.name, "")
+		dedented, indent := tr.dedentBlock(tt.lines, 0)
+		if dedented != tt.dedented {
+			t.Errorf("Test: %q\n\t    "+
+				"Got: dedented = %q, Expect: %q\n\n",
+				tt.name, dedented, tt.dedented)
+		}
+		if indent != tt.indent {
+			t.Errorf("Test: %q\n\t    "+
+				"Got: indent = %d, Expect: %d\n\n",
+				tt.name, indent, tt.indent)
+		}
+		if len(tr.Messages) != tt.nMsgs {
+			t.Errorf("Test: %q\n\t    "+
+				"Got: len(Messages) = %d, Expect: %d\n\n",
+				tt.name, len(tr.Messages), tt.nMsgs)
+			continue
+		}
+		if tt.nMsgs > 0 {
+			sm := tr.Messages[0].(*SystemMessageNode)
+			if sm.MessageType != errorInconsistentIndentation {
+				t.Errorf("Test: %q\n\t    "+
+					"Got: MessageType = %q, Expect: %q\n\n",
+					tt.name, sm.MessageType,
+					errorInconsistentIndentation)
+			}
+		}
+	}
+}
+
+var checkIndentationTests = []struct {
+	name  string
+	input string
+	line  Line // Expected line of the message, zero if there is none
+}{
+	{
+		name:  "Block quote with spaces",
+		input: "Paragraph.\n\n    Line one.\n    Line two.",
+	},
+	{
+		name:  "Block quote with a tab on the second line",
+		input: "Paragraph.\n\n    Line one.\n\tLine two.",
+		line:  3,
+	},
+	{
+		name:  "Literal block with a tab on the second line",
+		input: "Paragraph::\n\n    Line one.\n\tLine two.",
+		line:  3,
+	},
+	{
+		name:  "Bullet list item with a tab on the second line",
+		input: "- Line one.\n\n        Line two.\n\tLine three.",
+		line:  1,
+	},
+	{
+		name:  "Definition with a tab on the second line",
+		input: "Term\n        Line one.\n\tLine two.",
+		line:  2,
+	},
+	{
+		name:  "Directive content with a tab on the second line",
+		input: ".. note::\n\n        Line one.\n\tLine two.",
+		line:  3,
+	},
+}
+
+func TestParseInconsistentIndentation(t *testing.T) {
+	for _, tt := range checkIndentationTests {
+		tr, _ := Parse(tt.name, tt.input)
+		var msgs []*Diagnostic
+		for _, d := range tr.Diagnostics {
+			if d.MessageType == errorInconsistentIndentation {
+				msgs = append(msgs, d)
+			}
+		}
+		if tt.line == 0 {
+			if len(msgs) != 0 {
+				t.Errorf("Test: %q\n\t    Got: len(messages) = %d, "+
+					"Expect: 0\n\n", tt.name, len(msgs))
+			}
+			continue
+		}
+		if len(msgs) != 1 {
+			t.Errorf("Test: %q\n\t    Got: len(messages) = %d, "+
+				"Expect: 1\n\n", tt.name, len(msgs))
+			continue
+		}
+		if msgs[0].Line != tt.line {
+			t.Errorf("Test: %q\n\t    Got: Line = %d, Expect: %d\n\n",
+				tt.name, msgs[0].Line, tt.line)
+		}
+		if msgs[0].Severity != levelError {
+			t.Errorf("Test: %q\n\t    Got: Severity = %s, "+
+				"Expect: %s\n\n", tt.name, msgs[0].Severity, levelError)
+		}
+	}
+}
+
+func TestParseBlockQuoteIndentation(t *testing.T) {
+	// The body of a block quote is dedented while parsing, so its message
+	// is reported before the messages of the following elements.
+	input := "Paragraph.\n\n    Line one.\n\tLine two.\n\nTitle\n===\n\nText."
+	_, errors := Parse("TestParseBlockQuoteIndentation", input)
+	expect := []parserMessage{errorInconsistentIndentation,
+		warningShortUnderline}
+	if len(errors) != len(expect) {
+		t.Fatalf("Got: len(errors) = %d, Expect: %d", len(errors),
+			len(expect))
+	}
+	for num, e := range errors {
+		if m := e.(*SystemMessageNode); m.MessageType != expect[num] {
+			t.Errorf("Got: errors[%d] = %s, Expect: %s", num,
+				m.MessageType, expect[num])
+		}
+	}
+}
+
+func TestLineIndentTabWidth(t *testing.T) {
+	for _, tt := range []struct {
+		line     string
+		tabWidth int
+		indent   int
+	}{
+		{"\tOne", 8, 8},
+		{"\tOne", 4, 4},
+		{"  \tOne", 4, 4},
+		{"    \tOne", 4, 8},
+	} {
+		if got := lineIndent(tt.line, tt.tabWidth); got != tt.indent {
+			t.Errorf("Test: %q, tab width %d\n\t    Got: indent = %d, "+
+				"Expect: %d\n\n", tt.line, tt.tabWidth, got, tt.indent)
+		}
+	}
+}
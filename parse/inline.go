@@ -0,0 +1,372 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The ASCII characters that may precede an inline markup start-string, and
+// follow an inline markup end-string. Non-ASCII characters are classified by
+// their Unicode punctuation category.
+const (
+	inlineStartASCII = `-:/'"<([{`
+	inlineEndASCII   = `-.,:;!?\/'")]}>`
+)
+
+// inlineQuotePairs contains the closing quotes matching an opening quote. In
+// addition to the usual pairs, some languages use the same quote, or a low-9
+// quote, as the closing quote.
+var inlineQuotePairs = map[rune]string{
+	'"':  `"`,
+	'\'': `'`,
+	'«':  "»«",
+	'»':  "«»",
+	'‘':  "’‚",
+	'’':  "’",
+	'‚':  "‘’",
+	'“':  "”„",
+	'”':  "”",
+	'„':  "“”",
+	'‹':  "›‹",
+	'›':  "‹›",
+}
+
+// isInlineStartContext returns true if the rune before an inline markup
+// start-string allows the start-string to be recognized. Start-strings must
+// begin a text block (before is eof), or be preceded by whitespace, one of the
+// ASCII characters - : / ' " < ( [ {, or a non-ASCII dash, opening, quoting,
+// or other punctuation character.
+func isInlineStartContext(before rune) bool {
+	switch {
+	case before == eof || unicode.IsSpace(before):
+		return true
+	case before <= unicode.MaxASCII:
+		return strings.ContainsRune(inlineStartASCII, before)
+	}
+	return unicode.In(before, unicode.Pd, unicode.Po, unicode.Ps,
+		unicode.Pi, unicode.Pf)
+}
+
+// isInlineEndContext returns true if the rune after an inline markup
+// end-string allows the end-string to be recognized. End-strings must end a
+// text block (after is eof), or be followed by whitespace, one of the ASCII
+// characters - . , : ; ! ? \ / ' " ) ] } >, or a non-ASCII dash, closing,
+// quoting, or other punctuation character.
+func isInlineEndContext(after rune) bool {
+	switch {
+	case after == eof || unicode.IsSpace(after):
+		return true
+	case after <= unicode.MaxASCII:
+		return strings.ContainsRune(inlineEndASCII, after)
+	}
+	return unicode.In(after, unicode.Pd, unicode.Po, unicode.Pe,
+		unicode.Pi, unicode.Pf)
+}
+
+// isInlineMatchingPair returns true if open and close are a matching pair of
+// opening and closing brackets or quotes. A start-string preceded by open must
+// not be followed by its matching close, for example "(*)" is not emphasis.
+func isInlineMatchingPair(open, close rune) bool {
+	switch open {
+	case '(':
+		return close == ')'
+	case '[':
+		return close == ']'
+	case '{':
+		return close == '}'
+	case '<':
+		return close == '>'
+	}
+	if closers, ok := inlineQuotePairs[open]; ok {
+		return strings.ContainsRune(closers, close)
+	}
+	// The closing bracket of a Unicode opening bracket is the next code
+	// point for all but a few rarely used brackets.
+	return unicode.Is(unicode.Ps, open) && unicode.Is(unicode.Pe, close) &&
+		close == open+1
+}
+
+// findInlineEnd returns the index in text of the end-string endString that
+// closes an inline markup span, or -1 if the span is not closed. start is the
+// index of the first rune after the start-string of the span.
+//
+// Inline markup can not be nested. Start-strings inside an open span are not
+// recognized, so the span is closed by the first end-string that is preceded
+// by a non-whitespace rune, is not escaped with a backslash, and is followed
+// by an end context. For example, the emphasis in "*a *b* c*" contains the
+// text "a *b", and the remaining " c*" is text.
+func findInlineEnd(text string, start int, endString string) int {
+	for i := start; i < len(text); i++ {
+		if text[i] == '\\' {
+			i++
+			continue
+		}
+		if i == start || !strings.HasPrefix(text[i:], endString) {
+			continue
+		}
+		before, _ := utf8.DecodeLastRuneInString(text[:i])
+		after, _ := utf8.DecodeRuneInString(text[i+len(endString):])
+		if i+len(endString) == len(text) {
+			after = eof
+		}
+		if !unicode.IsSpace(before) && isInlineEndContext(after) {
+			return i
+		}
+	}
+	return -1
+}
+
+// inlineMarkupKinds are the inline markup recognized by Tree.inline. Longer
+// start-strings are listed first.
+var inlineMarkupKinds = []struct {
+	delim   string
+	nodeTyp NodeType
+	message parserMessage // The message of a start-string without end-string
+}{
+	{"**", NodeStrong, warningInlineStrongStart},
+	{"``", NodeInlineLiteral, warningInlineLiteralStart},
+	{"*", NodeEmphasis, warningInlineEmphasisStart},
+}
+
+// parseInline parses the inline markup of the text of all paragraphs in the
+// document into the NodeList of the paragraphs. Each paragraph is scanned on
+// its own, so a start-string is never closed by an end-string in a following
+// paragraph.
+func (t *Tree) parseInline() {
+	Walk(t.Nodes, func(n Node) bool {
+		if p, ok := n.(*ParagraphNode); ok {
+			p.NodeList = t.inline(p)
+			return false
+		}
+		return true
+	})
+}
+
+// inline returns the inline nodes of the text of paragraph p. Inline markup
+// can not span paragraphs, a start-string without an end-string in the text of
+// p is added as a ProblematicNode, and its warning is added to Tree.Messages.
+func (t *Tree) inline(p *ParagraphNode) (nl NodeList) {
+	text := p.Text
+	prev := 0
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' {
+			i++
+			continue
+		}
+		before := eof
+		if i > 0 {
+			before, _ = utf8.DecodeLastRuneInString(text[:i])
+		}
+		if !isInlineStartContext(before) {
+			continue
+		}
+		if end := bracketReferenceEnd(text, i); end != -1 {
+			if prev < i {
+				nl = append(nl, t.inlineNode(NodeText, p, prev,
+					text[prev:i]))
+			}
+			nl = append(nl, t.bracketReference(p, i, text[i+1:end-2]))
+			prev = end
+			i = prev - 1
+			continue
+		}
+		if closing, end := phraseReferenceEnd(text, i); end != -1 {
+			if prev < i {
+				nl = append(nl, t.inlineNode(NodeText, p, prev,
+					text[prev:i]))
+			}
+			nl = append(nl, t.phraseReference(p, i, text[i+1:closing],
+				text[i:end], end-closing == 3)...)
+			prev = end
+			i = prev - 1
+			continue
+		}
+		if role, content, end := interpretedText(text, i); end != -1 {
+			if prev < i {
+				nl = append(nl, t.inlineNode(NodeText, p, prev,
+					text[prev:i]))
+			}
+			nl = append(nl, t.interpreted(p, i, role, content,
+				text[i:end]))
+			prev = end
+			i = prev - 1
+			continue
+		}
+		for _, k := range inlineMarkupKinds {
+			if !strings.HasPrefix(text[i:], k.delim) {
+				continue
+			}
+			start := i + len(k.delim)
+			after, _ := utf8.DecodeRuneInString(text[start:])
+			if start == len(text) || unicode.IsSpace(after) ||
+				isInlineMatchingPair(before, after) {
+				break
+			}
+			if prev < i {
+				nl = append(nl, t.inlineNode(NodeText, p, prev,
+					text[prev:i]))
+			}
+			end := findInlineEnd(text, start, k.delim)
+			if end == -1 {
+				nl = append(nl, t.problematic(p, i, k.delim,
+					k.message))
+				prev = start
+			} else {
+				nl = append(nl, t.inlineNode(k.nodeTyp, p, i,
+					text[start:end]))
+				prev = end + len(k.delim)
+			}
+			i = prev - 1
+			break
+		}
+	}
+	if prev < len(text) {
+		nl = append(nl, t.inlineNode(NodeText, p, prev, text[prev:]))
+	}
+	return
+}
+
+// bracketReferenceEnd returns the index after the footnote or citation
+// reference "[label]_" beginning at index i of text, or -1 if no reference
+// begins at i. The label must be a footnote label or a citation label, and
+// the reference must be followed by an end context.
+func bracketReferenceEnd(text string, i int) int {
+	if text[i] != '[' {
+		return -1
+	}
+	end := strings.Index(text[i:], "]_")
+	if end == -1 {
+		return -1
+	}
+	end += i
+	label := text[i+1 : end]
+	if !isFootnoteLabel(label) && !isSimpleReferenceName(label) {
+		return -1
+	}
+	after, _ := utf8.DecodeRuneInString(text[end+2:])
+	if end+2 < len(text) && !isInlineEndContext(after) {
+		return -1
+	}
+	return end + 2
+}
+
+// bracketReference returns a FootnoteRefNode or a CitationRefNode for the
+// reference with label at the byte offset off in the text of paragraph p.
+// Footnote labels are numbers, "#", "#name", or "*", other labels are
+// citation labels.
+func (t *Tree) bracketReference(p *ParagraphNode, off int, label string) Node {
+	t.id++
+	line, pos := inlinePosition(p, off)
+	length := len(label) + 3
+	if !isFootnoteLabel(label) {
+		return &CitationRefNode{ID: ID(t.id), Type: NodeCitationRef,
+			Label: label, Length: length, Line: line,
+			StartPosition: pos}
+	}
+	auto := footnoteManual
+	switch {
+	case label == "*":
+		auto = footnoteAutoSymbol
+	case strings.HasPrefix(label, "#"):
+		auto = footnoteAutoNumber
+	}
+	return &FootnoteRefNode{ID: ID(t.id), Type: NodeFootnoteRef,
+		Label: label, Auto: auto, Length: length, Line: line,
+		StartPosition: pos}
+}
+
+// inlinePosition returns the line and the column of the byte offset off in
+// the text of paragraph p. Columns are counted in bytes like the StartPosition
+// of the nodes returned by the lexer.
+func inlinePosition(p *ParagraphNode, off int) (Line, StartPosition) {
+	text := p.Text[:off]
+	line := p.Line + Line(strings.Count(text, "\n"))
+	if nl := strings.LastIndex(text, "\n"); nl != -1 {
+		return line, StartPosition(len(text) - nl)
+	}
+	pos := p.StartPosition
+	if pos == 0 {
+		pos = 1
+	}
+	return line, pos + StartPosition(len(text))
+}
+
+// inlineNode returns a new inline node of type typ with text, beginning at
+// the byte offset off in the text of paragraph p. The escaped whitespace in the
+// text of all but inline literals is removed, see removeNullEscapes. Length is
+// the length of text in the input.
+func (t *Tree) inlineNode(typ NodeType, p *ParagraphNode, off int,
+	text string) Node {
+
+	t.id++
+	line, pos := inlinePosition(p, off)
+	id, length := ID(t.id), len(text)
+	switch typ {
+	case NodeEmphasis:
+		return &EmphasisNode{ID: id, Type: typ,
+			Text: removeNullEscapes(text), Length: length, Line: line,
+			StartPosition: pos}
+	case NodeStrong:
+		return &StrongNode{ID: id, Type: typ,
+			Text: removeNullEscapes(text), Length: length, Line: line,
+			StartPosition: pos}
+	case NodeInlineLiteral:
+		return &InlineLiteralNode{ID: id, Type: typ, Text: text,
+			Length: length, Line: line, StartPosition: pos}
+	}
+	return &TextNode{ID: id, Type: NodeText, Text: removeNullEscapes(text),
+		Length: length, Line: line, StartPosition: pos}
+}
+
+// removeNullEscapes returns text without its null escapes. A backslash followed
+// by whitespace is a null escape, the backslash and the whitespace are both
+// removed, so "a\ b" becomes "ab" and an escaped line break joins the words
+// around it without a space. Other escapes are kept.
+func removeNullEscapes(text string) string {
+	if !strings.Contains(text, "\\") {
+		return text
+	}
+	var buf []byte
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' && i+1 < len(text) {
+			if !unicode.IsSpace(rune(text[i+1])) {
+				buf = append(buf, text[i], text[i+1])
+			}
+			i++
+			continue
+		}
+		buf = append(buf, text[i])
+	}
+	return string(buf)
+}
+
+// problematic returns a ProblematicNode for the unrecognized inline markup
+// text at the byte offset off in the text of paragraph p. A system message
+// for msg is added to Tree.Messages and referenced by the ProblematicNode.
+func (t *Tree) problematic(p *ParagraphNode, off int, text string,
+	msg parserMessage) *ProblematicNode {
+
+	line, pos := inlinePosition(p, off)
+	s := newSystemMessage(&item{Type: itemSystemMessage, Line: line}, msg,
+		&t.id)
+	s.NodeList = append(s.NodeList, newParagraph(&item{
+		Text:   msg.Message(),
+		Length: len(msg.Message()),
+	}, &t.id))
+	t.addMessage(s, pos)
+	t.id++
+	return &ProblematicNode{
+		ID:            ID(t.id),
+		Type:          NodeProblematic,
+		Text:          text,
+		Length:        len(text),
+		Line:          line,
+		StartPosition: pos,
+		Message:       s,
+	}
+}
@@ -0,0 +1,412 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"reflect"
+	"testing"
+)
+
+var inlineContextTests = []struct {
+	name  string
+	r     rune
+	start bool // Expected return of isInlineStartContext(r)
+	end   bool // Expected return of isInlineEndContext(r)
+}{
+	{name: "Start or end of text block", r: eof, start: true, end: true},
+	{name: "Space", r: ' ', start: true, end: true},
+	{name: "Tab", r: '\t', start: true, end: true},
+	{name: "Newline", r: '\n', start: true, end: true},
+	{name: "No-break space", r: '\u00a0', start: true, end: true},
+	{name: "Hyphen", r: '-', start: true, end: true},
+	{name: "Colon", r: ':', start: true, end: true},
+	{name: "Slash", r: '/', start: true, end: true},
+	{name: "Single quote", r: '\'', start: true, end: true},
+	{name: "Double quote", r: '"', start: true, end: true},
+	{name: "Less than", r: '<', start: true},
+	{name: "Open parenthesis", r: '(', start: true},
+	{name: "Open bracket", r: '[', start: true},
+	{name: "Open brace", r: '{', start: true},
+	{name: "Period", r: '.', end: true},
+	{name: "Comma", r: ',', end: true},
+	{name: "Semicolon", r: ';', end: true},
+	{name: "Exclamation mark", r: '!', end: true},
+	{name: "Question mark", r: '?', end: true},
+	{name: "Backslash", r: '\\', end: true},
+	{name: "Close parenthesis", r: ')', end: true},
+	{name: "Close bracket", r: ']', end: true},
+	{name: "Close brace", r: '}', end: true},
+	{name: "Greater than", r: '>', end: true},
+	{name: "Letter", r: 'a'},
+	{name: "Digit", r: '1'},
+	{name: "Asterisk", r: '*'},
+	{name: "Underscore", r: '_'},
+	{name: "Backquote", r: '`'},
+	{name: "Non-ASCII letter", r: 'é'},
+	{name: "En dash (Pd)", r: '–', start: true, end: true},
+	{name: "Inverted question mark (Po)", r: '¿', start: true, end: true},
+	{name: "Fullwidth open parenthesis (Ps)", r: '（', start: true},
+	{name: "Fullwidth close parenthesis (Pe)", r: '）', end: true},
+	{name: "Left double quote (Pi)", r: '“', start: true, end: true},
+	{name: "Right double quote (Pf)", r: '”', start: true, end: true},
+	{name: "Left guillemet (Pi)", r: '«', start: true, end: true},
+}
+
+func TestIsInlineContext(t *testing.T) {
+	for _, tt := range inlineContextTests {
+		if got := isInlineStartContext(tt.r); got != tt.start {
+			t.Errorf("Test: %q\n\t    "+
+				"Got: isInlineStartContext(%q) = %t, Expect: %t\n\n",
+				tt.name, tt.r, got, tt.start)
+		}
+		if got := isInlineEndContext(tt.r); got != tt.end {
+			t.Errorf("Test: %q\n\t    "+
+				"Got: isInlineEndContext(%q) = %t, Expect: %t\n\n",
+				tt.name, tt.r, got, tt.end)
+		}
+	}
+}
+
+var inlineMatchingPairTests = []struct {
+	open, close rune
+	expect      bool
+}{
+	{'(', ')', true},
+	{'[', ']', true},
+	{'{', '}', true},
+	{'<', '>', true},
+	{'"', '"', true},
+	{'\'', '\'', true},
+	{'«', '»', true},
+	{'»', '«', true},
+	{'‘', '’', true},
+	{'‘', '‚', true},
+	{'‚', '‘', true},
+	{'“', '”', true},
+	{'“', '„', true},
+	{'„', '“', true},
+	{'”', '”', true},
+	{'‹', '›', true},
+	{'（', '）', true},
+	{'「', '」', true},
+	{'(', ']', false},
+	{'[', ')', false},
+	{'"', '\'', false},
+	{'“', '“', false},
+	{')', '(', false},
+	{'a', 'b', false},
+	{'（', ')', false},
+}
+
+func TestIsInlineMatchingPair(t *testing.T) {
+	for _, tt := range inlineMatchingPairTests {
+		if got := isInlineMatchingPair(tt.open, tt.close); got != tt.expect {
+			t.Errorf("Got: isInlineMatchingPair(%q, %q) = %t, "+
+				"Expect: %t\n\n", tt.open, tt.close, got, tt.expect)
+		}
+	}
+}
+
+var findInlineEndTests = []struct {
+	name      string
+	text      string
+	start     int
+	endString string
+	end       int // Expected return of findInlineEnd
+}{
+	{
+		name: "Emphasis", text: "*emphasis*", start: 1,
+		endString: "*", end: 9,
+	},
+	{
+		name: "Nested emphasis is text", text: "*a *b* c*", start: 1,
+		endString: "*", end: 5,
+	},
+	{
+		name: "Nested strong is text", text: "**a **b** c**", start: 2,
+		endString: "**", end: 7,
+	},
+	{
+		name: "Emphasis inside strong is text", text: "**a *b* c**",
+		start: 2, endString: "**", end: 9,
+	},
+	{
+		name: "End-string preceded by space", text: "*a * b*", start: 1,
+		endString: "*", end: 6,
+	},
+	{
+		name: "Escaped end-string", text: `*a\* b*`, start: 1,
+		endString: "*", end: 6,
+	},
+	{
+		name: "End-string followed by punctuation", text: "*a*, b",
+		start: 1, endString: "*", end: 2,
+	},
+	{
+		name: "End-string followed by a letter", text: "*a*b", start: 1,
+		endString: "*", end: -1,
+	},
+	{
+		name: "Empty span", text: "**", start: 1, endString: "*",
+		end: -1,
+	},
+	{
+		name: "Unclosed span", text: "*a *b c", start: 1,
+		endString: "*", end: -1,
+	},
+}
+
+func TestFindInlineEnd(t *testing.T) {
+	for _, tt := range findInlineEndTests {
+		got := findInlineEnd(tt.text, tt.start, tt.endString)
+		if got != tt.end {
+			t.Errorf("Test: %q\n\t    "+
+				"Got: findInlineEnd(%q) = %d, Expect: %d\n\n",
+				tt.name, tt.text, got, tt.end)
+		}
+	}
+}
+
+var parseInlineTests = []struct {
+	name    string
+	input   string
+	types   []NodeType    // The expected types of the inline nodes
+	texts   []string      // The expected text of the inline nodes
+	message parserMessage // The expected warning
+}{
+	{
+		name:  "Emphasis, strong, and literal",
+		input: "Some *emphasis*, **strong**, and ``literal``.",
+		types: []NodeType{NodeText, NodeEmphasis, NodeText, NodeStrong,
+			NodeText, NodeInlineLiteral, NodeText},
+		texts: []string{"Some ", "emphasis", ", ", "strong", ", and ",
+			"literal", "."},
+	},
+	{
+		name:    "Unclosed emphasis",
+		input:   "Some *unclosed emphasis.",
+		types:   []NodeType{NodeText, NodeProblematic, NodeText},
+		texts:   []string{"Some ", "*", "unclosed emphasis."},
+		message: warningInlineEmphasisStart,
+	},
+	{
+		name:    "Unclosed inline literal",
+		input:   "Some ``unclosed literal.",
+		types:   []NodeType{NodeText, NodeProblematic, NodeText},
+		texts:   []string{"Some ", "``", "unclosed literal."},
+		message: warningInlineLiteralStart,
+	},
+	{
+		name:  "Null escape",
+		input: `Null\ escape and \*escaped\* markup`,
+		types: []NodeType{NodeText},
+		texts: []string{`Nullescape and \*escaped\* markup`},
+	},
+	{
+		name:  "Escaped line break",
+		input: "A word\\\nwrapped and *em\\ phasis*",
+		types: []NodeType{NodeText, NodeEmphasis},
+		texts: []string{"A wordwrapped and ", "emphasis"},
+	},
+	{
+		name:  "Escaped whitespace in inline literal",
+		input: "``a\\ b``",
+		types: []NodeType{NodeInlineLiteral},
+		texts: []string{"a\\ b"},
+	},
+	{
+		name:  "Start-string followed by whitespace",
+		input: "Multiply 2 * 3",
+		types: []NodeType{NodeText},
+		texts: []string{"Multiply 2 * 3"},
+	},
+}
+
+func TestParseInline(t *testing.T) {
+	for _, tt := range parseInlineTests {
+		tr, errors := ParseWithOptions(tt.name, tt.input,
+			&ParseOptions{ParseInline: true})
+		p := tr.Nodes[0].(*ParagraphNode)
+		if len(p.NodeList) != len(tt.types) {
+			t.Errorf("Test: %q\n\t    Got: len(NodeList) = %d, "+
+				"Expect: %d\n\n", tt.name, len(p.NodeList),
+				len(tt.types))
+			continue
+		}
+		for num, n := range p.NodeList {
+			text := reflect.ValueOf(n).Elem().FieldByName("Text").String()
+			if n.NodeType() != tt.types[num] || text != tt.texts[num] {
+				t.Errorf("Test: %q\n\t    Got: NodeList[%d] = %s %q, "+
+					"Expect: %s %q\n\n", tt.name, num,
+					n.NodeType(), text, tt.types[num],
+					tt.texts[num])
+			}
+		}
+		if tt.message == parserMessageNil {
+			if len(errors) != 0 {
+				t.Errorf("Test: %q\n\t    Got: len(errors) = %d, "+
+					"Expect: 0\n\n", tt.name, len(errors))
+			}
+			continue
+		}
+		if len(errors) != 1 {
+			t.Errorf("Test: %q\n\t    Got: len(errors) = %d, "+
+				"Expect: 1\n\n", tt.name, len(errors))
+			continue
+		}
+		m := errors[0].(*SystemMessageNode)
+		if m.MessageType != tt.message || m.Severity != levelWarning {
+			t.Errorf("Test: %q\n\t    Got: message = %s %s, "+
+				"Expect: %s WARNING\n\n", tt.name, m.Severity,
+				m.MessageType, tt.message)
+		}
+		if pr := p.NodeList[1].(*ProblematicNode); pr.Message != m {
+			t.Errorf("Test: %q\n\t    Got: Message = %p, "+
+				"Expect: %p\n\n", tt.name, pr.Message, m)
+		}
+	}
+}
+
+func TestParseInlineParagraphBoundary(t *testing.T) {
+	input := "First *paragraph.\n\nSecond paragraph*."
+	tr, errors := ParseWithOptions("TestParseInlineParagraphBoundary",
+		input, &ParseOptions{ParseInline: true})
+	if len(tr.Nodes) != 2 {
+		t.Fatalf("Got: len(Nodes) = %d, Expect: 2", len(tr.Nodes))
+	}
+	first := tr.Nodes[0].(*ParagraphNode).NodeList
+	expect := []NodeType{NodeText, NodeProblematic, NodeText}
+	if len(first) != len(expect) {
+		t.Fatalf("Got: len(first.NodeList) = %d, Expect: %d", len(first),
+			len(expect))
+	}
+	for num, n := range first {
+		if n.NodeType() != expect[num] {
+			t.Errorf("Got: first.NodeList[%d] = %s, Expect: %s", num,
+				n.NodeType(), expect[num])
+		}
+	}
+	second := tr.Nodes[1].(*ParagraphNode).NodeList
+	if len(second) != 1 || second[0].NodeType() != NodeText {
+		t.Errorf("Got: second.NodeList = %v, Expect: one NodeText", second)
+	}
+	if len(errors) != 1 {
+		t.Fatalf("Got: len(errors) = %d, Expect: 1", len(errors))
+	}
+	if m := errors[0].(*SystemMessageNode); m.Line != 1 {
+		t.Errorf("Got: Line = %d, Expect: 1", m.Line)
+	}
+}
+
+var bracketReferenceTests = []struct {
+	name  string
+	input string
+	typ   NodeType         // The expected type of the reference node
+	label string           // The expected label of the reference
+	auto  FootnoteAutoType // The expected numbering of a footnote reference
+}{
+	{
+		name:  "Numbered footnote reference",
+		input: "See the note [1]_ here.\n\n.. [1] Note.",
+		typ:   NodeFootnoteRef,
+		label: "1",
+		auto:  footnoteManual,
+	},
+	{
+		name:  "Auto-numbered footnote reference",
+		input: "See the note [#]_ here.\n\n.. [#] Note.",
+		typ:   NodeFootnoteRef,
+		label: "#",
+		auto:  footnoteAutoNumber,
+	},
+	{
+		name:  "Named auto-numbered footnote reference",
+		input: "See the note [#note]_ here.\n\n.. [#note] Note.",
+		typ:   NodeFootnoteRef,
+		label: "#note",
+		auto:  footnoteAutoNumber,
+	},
+	{
+		name:  "Auto-symbol footnote reference",
+		input: "See the note [*]_ here.\n\n.. [*] Note.",
+		typ:   NodeFootnoteRef,
+		label: "*",
+		auto:  footnoteAutoSymbol,
+	},
+	{
+		name:  "Citation reference",
+		input: "See the book [CIT2002]_ here.\n\n.. [CIT2002] Book.",
+		typ:   NodeCitationRef,
+		label: "CIT2002",
+	},
+}
+
+func TestParseInlineBracketReferences(t *testing.T) {
+	for _, tt := range bracketReferenceTests {
+		tr, errors := ParseWithOptions(tt.name, tt.input,
+			&ParseOptions{ParseInline: true})
+		if len(errors) != 0 {
+			t.Errorf("Test: %q\n\t    Got: len(errors) = %d, "+
+				"Expect: 0\n\n", tt.name, len(errors))
+			continue
+		}
+		p := tr.Nodes[0].(*ParagraphNode)
+		if len(p.NodeList) != 3 {
+			t.Errorf("Test: %q\n\t    Got: len(NodeList) = %d, "+
+				"Expect: 3\n\n", tt.name, len(p.NodeList))
+			continue
+		}
+		n := p.NodeList[1]
+		if n.NodeType() != tt.typ {
+			t.Errorf("Test: %q\n\t    Got: NodeType = %s, "+
+				"Expect: %s\n\n", tt.name, n.NodeType(), tt.typ)
+			continue
+		}
+		var label string
+		var auto FootnoteAutoType
+		switch n := n.(type) {
+		case *FootnoteRefNode:
+			label, auto = n.Label, n.Auto
+		case *CitationRefNode:
+			label = n.Label
+		}
+		if label != tt.label || auto != tt.auto {
+			t.Errorf("Test: %q\n\t    Got: Label = %q, Auto = %s, "+
+				"Expect: %q, %s\n\n", tt.name, label, auto,
+				tt.label, tt.auto)
+		}
+		if text := p.NodeList[2].(*TextNode).Text; text != " here." {
+			t.Errorf("Test: %q\n\t    Got: NodeList[2] = %q, "+
+				"Expect: %q\n\n", tt.name, text, " here.")
+		}
+	}
+}
+
+var inlinePositionTests = []struct {
+	name string
+	text string
+	off  int           // The byte offset in text
+	line Line          // The expected line
+	pos  StartPosition // The expected column in bytes
+}{
+	{name: "First line", text: "Some *text*", off: 5, line: 1, pos: 6},
+	{name: "Second line", text: "Some\n*text*", off: 5, line: 2, pos: 1},
+	{name: "Non-ASCII first line", text: "Grüße *text*", off: 8, line: 1,
+		pos: 9},
+	{name: "Non-ASCII second line", text: "Line\nGrüße *text*", off: 13,
+		line: 2, pos: 9},
+}
+
+func TestInlinePosition(t *testing.T) {
+	for _, tt := range inlinePositionTests {
+		p := &ParagraphNode{Text: tt.text, Line: 1, StartPosition: 1}
+		line, pos := inlinePosition(p, tt.off)
+		if line != tt.line || pos != tt.pos {
+			t.Errorf("Test: %q\n\t    Got: line = %d, pos = %d, "+
+				"Expect: line = %d, pos = %d\n\n", tt.name, line, pos,
+				tt.line, tt.pos)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+// byteOrderMark is the UTF-8 encoding of U+FEFF, which some editors write at
+// the start of a file.
+const byteOrderMark = "\ufeff"
+
+// stripBOM returns text without the byte order mark at its start. The lines
+// and columns of the nodes are counted from the first rune after the mark.
+func stripBOM(text string) string {
+	return strings.TrimPrefix(text, byteOrderMark)
+}
+
+// invalidUTF8Position is the position of an invalid UTF-8 byte in the input.
+type invalidUTF8Position struct {
+	Line
+	StartPosition
+}
+
+// replaceInvalidUTF8 returns text with each byte of an invalid UTF-8 sequence
+// replaced by utf8.RuneError (U+FFFD), so that the lexer only sees valid
+// UTF-8. The line and column of each replacement in the returned text are also
+// returned.
+func replaceInvalidUTF8(text string) (string, []invalidUTF8Position) {
+	if utf8.ValidString(text) {
+		return text, nil
+	}
+	var buf bytes.Buffer
+	var invalid []invalidUTF8Position
+	line, lineStart := 1, 0
+	for i := 0; i < len(text); {
+		r, width := utf8.DecodeRuneInString(text[i:])
+		if r == utf8.RuneError && width == 1 {
+			invalid = append(invalid, invalidUTF8Position{
+				Line:          Line(line),
+				StartPosition: StartPosition(buf.Len() - lineStart + 1),
+			})
+			buf.WriteRune(utf8.RuneError)
+		} else {
+			buf.WriteString(text[i : i+width])
+		}
+		if r == '\n' {
+			line++
+			lineStart = buf.Len()
+		}
+		i += width
+	}
+	return buf.String(), invalid
+}
+
+// invalidUTF8 generates a warningInvalidUTF8 system message for each invalid
+// byte replaced by replaceInvalidUTF8. The system messages are added to
+// Tree.Messages only, they are not a part of the parsed document.
+func (t *Tree) invalidUTF8(invalid []invalidUTF8Position) {
+	for _, pos := range invalid {
+		s := newSystemMessage(&item{
+			Type: itemSystemMessage,
+			Line: pos.Line,
+		}, warningInvalidUTF8, &t.id)
+		msg := warningInvalidUTF8.Message()
+		s.NodeList = append(s.NodeList, newParagraph(&item{
+			Text:   msg,
+			Length: len(msg),
+		}, &t.id))
+		t.addMessage(s, pos.StartPosition)
+	}
+}
@@ -0,0 +1,105 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+func TestParseInvalidUTF8(t *testing.T) {
+	name := "Test invalid continuation byte"
+	// "\xc3" begins a two byte sequence, but "(" is not a continuation
+	// byte.
+	input := "Paragraph one.\n\nBad \xc3( byte."
+	tree, errors := Parse(name, input)
+	if len(errors) != 1 {
+		t.Fatalf("Test: %q\n\t    Got: len(errors) = %d, Expect: %d\n\n",
+			name, len(errors), 1)
+	}
+	sm := errors[0].(*SystemMessageNode)
+	if sm.MessageType != warningInvalidUTF8 || sm.Severity != levelWarning {
+		t.Errorf("Test: %q\n\t    Got: MessageType = %s, Severity = %s\n\n",
+			name, sm.MessageType, sm.Severity)
+	}
+	d := tree.Diagnostics[0]
+	if d.Line != 3 || d.StartPosition != 5 {
+		t.Errorf("Test: %q\n\t    Got: Line = %d, StartPosition = %d, "+
+			"Expect: 3, 5\n\n", name, d.Line, d.StartPosition)
+	}
+	p := tree.Nodes[1].(*ParagraphNode)
+	if expect := "Bad \ufffd( byte."; p.Text != expect {
+		t.Errorf("Test: %q\n\t    Got: Text = %q, Expect: %q\n\n", name,
+			p.Text, expect)
+	}
+}
+
+func TestReplaceInvalidUTF8(t *testing.T) {
+	text, invalid := replaceInvalidUTF8("Valid text.")
+	if text != "Valid text." || invalid != nil {
+		t.Errorf("Got: %q, %v, Expect: valid text unchanged", text,
+			invalid)
+	}
+	text, invalid = replaceInvalidUTF8("\xff\n\xfe\xfd")
+	if expect := "\ufffd\n\ufffd\ufffd"; text != expect {
+		t.Errorf("Got: %q, Expect: %q", text, expect)
+	}
+	expect := []invalidUTF8Position{{1, 1}, {2, 1}, {2, 4}}
+	if len(invalid) != len(expect) {
+		t.Fatalf("Got: len(invalid) = %d, Expect: %d", len(invalid),
+			len(expect))
+	}
+	for num, pos := range invalid {
+		if pos != expect[num] {
+			t.Errorf("Got: %v, Expect: %v", pos, expect[num])
+		}
+	}
+}
+
+var whitespaceInputTests = []struct {
+	name  string
+	input string
+}{
+	{"Blank lines", "\n\n\n"},
+	{"Lines of spaces", "   \n\n  \n    \n"},
+	{"Last line of spaces", "\n\n    "},
+	{"Tabs and spaces", "\t\n \t \n\n"},
+}
+
+func TestParseWhitespaceInput(t *testing.T) {
+	for _, tt := range whitespaceInputTests {
+		tree, errors := Parse(tt.name, tt.input)
+		if len(tree.Nodes) != 0 || len(errors) != 0 ||
+			len(tree.Diagnostics) != 0 {
+			t.Errorf("Test: %q\n\t    Got: len(Nodes) = %d, "+
+				"len(errors) = %d, len(Diagnostics) = %d, "+
+				"Expect: 0, 0, 0\n\n", tt.name, len(tree.Nodes),
+				len(errors), len(tree.Diagnostics))
+		}
+	}
+}
+
+func TestParseByteOrderMark(t *testing.T) {
+	tree, errors := Parse("byte order mark",
+		"\ufeffTitle\n=====\n\nBad \xc3( byte.")
+	if len(tree.Nodes) != 1 {
+		t.Fatalf("Got: len(Nodes) = %d, Expect: 1", len(tree.Nodes))
+	}
+	sec := tree.Nodes[0].(*SectionNode)
+	if sec.Title.Text != "Title" || sec.Title.Length != 5 ||
+		sec.Title.StartPosition != 1 {
+		t.Errorf("Got: Title = %q, Length = %d, StartPosition = %d, "+
+			"Expect: %q, 5, 1", sec.Title.Text, sec.Title.Length,
+			sec.Title.StartPosition, "Title")
+	}
+	if len(errors) != 1 {
+		t.Fatalf("Got: len(errors) = %d, Expect: 1", len(errors))
+	}
+	if d := tree.Diagnostics[0]; d.Line != 4 || d.StartPosition != 5 {
+		t.Errorf("Got: Line = %d, StartPosition = %d, Expect: 4, 5",
+			d.Line, d.StartPosition)
+	}
+	if pos := tree.PositionString(6); pos != "byte order mark:2:1" {
+		t.Errorf("Got: PositionString(6) = %q, Expect: %q", pos,
+			"byte order mark:2:1")
+	}
+}
@@ -5,6 +5,8 @@
 package parse
 
 import (
+	"encoding/json"
+	"fmt"
 	"strconv"
 	"strings"
 	"unicode"
@@ -45,11 +47,14 @@ func (s StartPosition) Position() StartPosition { return s }
 // String implements Stringer and returns StartPosition converted to a string.
 func (s StartPosition) String() string { return strconv.Itoa(int(s)) }
 
-// itemElement are the types that are emitted by the lexer.
-type itemElement int
+// ElementType are the types of the items that are emitted by the lexer. New
+// element types must be appended to the end of the list of constants and the
+// elements table so the numbering of the existing types, and the fixtures
+// depending on it, do not change.
+type ElementType int
 
 const (
-	itemEOF itemElement = iota
+	itemEOF ElementType = iota
 	itemError
 	itemTitle
 	itemSectionAdornment
@@ -67,6 +72,22 @@ const (
 	itemInlineLiteral
 	itemDefinitionTerm
 	itemBullet
+
+	// The element types of the explicit markup blocks and of the body
+	// elements that are not lexed yet. They are declared so the lexer and
+	// the parser can refer to them while the constructs are implemented.
+	itemComment
+	itemDirective
+	itemTarget
+	itemFootnote
+	itemCitation
+	itemSubstitution
+	itemField
+	itemOption
+	itemTable
+	itemLineBlock
+	itemDoctest
+	itemMath
 )
 
 var elements = [...]string{
@@ -88,17 +109,46 @@ var elements = [...]string{
 	"itemInlineLiteral",
 	"itemDefinitionTerm",
 	"itemBullet",
+	"itemComment",
+	"itemDirective",
+	"itemTarget",
+	"itemFootnote",
+	"itemCitation",
+	"itemSubstitution",
+	"itemField",
+	"itemOption",
+	"itemTable",
+	"itemLineBlock",
+	"itemDoctest",
+	"itemMath",
 }
 
-// String implements the Stringer interface for printing itemElement types.
-func (t itemElement) String() string { return elements[t] }
+// String implements the Stringer interface for printing ElementTypes.
+func (t ElementType) String() string { return elements[t] }
 
-func (t *itemElement) UnmarshalJSON(data []byte) error {
+// ElementTypeFromString returns the ElementType converted from the string
+// name. -1 is returned if name is not a known element type.
+func ElementTypeFromString(name string) ElementType {
 	for num, elm := range elements {
-		if elm == string(data[1:len(data)-1]) {
-			*t = itemElement(num)
+		if name == elm {
+			return ElementType(num)
 		}
 	}
+	return -1
+}
+
+// UnmarshalJSON implements json.Unmarshaler and decodes the string name of an
+// ElementType.
+func (t *ElementType) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	e := ElementTypeFromString(name)
+	if e == -1 {
+		return fmt.Errorf("unknown element type %q", name)
+	}
+	*t = e
 	return nil
 }
 
@@ -118,7 +168,7 @@ type stateFn func(*lexer) stateFn
 // Struct for tokens emitted by the scanning process
 type item struct {
 	ID            `json:"id"`
-	Type          itemElement `json:"type"`
+	Type          ElementType `json:"type"`
 	Text          string      `json:"text"`
 	Line          `json:"line"`
 	StartPosition `json:"startPosition"`
@@ -141,7 +191,9 @@ type lexer struct {
 	id               int    // Unique ID for each item emitted
 	mark             rune   // The current lexed rune
 	indentLevel      int    // For tracking indentation with indentable items
-	indentWidth      string // For tracking indent width
+	indentWidth      int    // The column of the text of the open list item
+	adornments       []rune // Runes recognized as section adornments
+	tabWidth         int    // The number of columns between tab stops
 }
 
 func newLexer(name, input string) *lexer {
@@ -160,13 +212,15 @@ func newLexer(name, input string) *lexer {
 	log.Debugf("mark: %#U, index: %d, line: %d\n", mark, 0, 1)
 
 	return &lexer{
-		name:  name,
-		input: input,
-		lines: lines,
-		items: make(chan item),
-		index: 0,
-		mark:  mark,
-		width: width,
+		name:       name,
+		input:      input,
+		lines:      lines,
+		items:      make(chan item),
+		index:      0,
+		mark:       mark,
+		width:      width,
+		adornments: sectionAdornments,
+		tabWidth:   defaultTabWidth,
 	}
 }
 
@@ -174,10 +228,24 @@ func newLexer(name, input string) *lexer {
 // the purporse of the lexer. It is mostly used to identify the lexing process
 // in debugging.
 func lex(name, input string) *lexer {
+	return lexWithOptions(name, input, nil, 0)
+}
+
+// lexWithOptions is like lex, but only the runes in adornments are recognized
+// as section adornments and transition markers, and the tab stops used to
+// measure indentation are tabWidth columns apart. If adornments is nil,
+// sectionAdornments is used. If tabWidth is zero, defaultTabWidth is used.
+func lexWithOptions(name, input string, adornments []rune, tabWidth int) *lexer {
 	l := newLexer(name, input)
 	if l == nil {
 		return nil
 	}
+	if adornments != nil {
+		l.adornments = adornments
+	}
+	if tabWidth > 0 {
+		l.tabWidth = tabWidth
+	}
 	go l.run()
 	return l
 }
@@ -190,7 +258,7 @@ func (l *lexer) run() {
 }
 
 // emit passes an item back to the client.
-func (l *lexer) emit(t itemElement) {
+func (l *lexer) emit(t ElementType) {
 	var tok string
 
 	if t == itemBlankLine {
@@ -222,34 +290,41 @@ func (l *lexer) emit(t itemElement) {
 	l.start = l.index
 }
 
+// emitTrimmed emits an item of type t like emit, without the whitespace at the
+// end of the text of the item. The lexer position is not changed.
+func (l *lexer) emitTrimmed(t ElementType) {
+	end := l.index
+	text := l.lines[l.line][l.start:end]
+	l.index = l.start + len(strings.TrimRight(text, " \t\r"))
+	l.emit(t)
+	l.index, l.start = end, end
+}
+
 // backup backs up the lexer position by a number of rune positions (pos).
 // backup cannot backup off the input, in that case the index of the lexer is
-// set to the starting position on the input. The run
+// set to the starting position on the input. Backing up from the start of a
+// line moves the lexer to the end of the previous line, which is the position
+// next moves from to get to the next line. The width of the rune before the
+// index is decoded from the input, so backing up over runes of different
+// widths always lands on the start of a rune.
 func (l *lexer) backup(pos int) {
 	for i := 0; i < pos; i++ {
-		if l.index == 0 && l.line != 0 && i < pos {
+		if l.index == 0 {
+			if l.line == 0 {
+				break
+			}
 			l.line--
-			l.index = len(l.lines[l.line]) + 1
+			l.index = len(l.currentLine())
+			continue
 		}
-
-		l.index -= l.width
-		if l.index < 0 {
-			l.index = 0
-		} else if l.index > len(l.lines[l.line]) {
-			l.index--
-		}
-
-		r, w := utf8.DecodeRuneInString(l.currentLine()[l.index:])
-		l.mark = r
-		l.width = w
-
-		// Backup again if iteration has landed on part of a multi-byte
-		// rune
-		lLen := len(l.currentLine())
-		if r == utf8.RuneError && lLen != 0 && lLen != l.index {
-			l.backup(1)
+		if l.index > len(l.currentLine()) {
+			l.index = len(l.currentLine())
+			continue
 		}
+		_, w := utf8.DecodeLastRuneInString(l.currentLine()[:l.index])
+		l.index -= w
 	}
+	l.mark, l.width = utf8.DecodeRuneInString(l.currentLine()[l.index:])
 	log.Debugln("l.mark backed up to:", string(l.mark))
 }
 
@@ -322,6 +397,13 @@ func (l *lexer) currentLine() string {
 	return l.lines[l.line]
 }
 
+// measureIndent returns the width in columns of the whitespace at the start of
+// the current line. Tabs are expanded to the next tab stop, the tab stops are
+// l.tabWidth columns apart.
+func (l *lexer) measureIndent() int {
+	return lineIndent(l.currentLine(), l.tabWidth)
+}
+
 func (l *lexer) lineNumber() int {
 	return l.line + 1
 }
@@ -361,24 +443,66 @@ func isArabic(r rune) bool {
 // return r == '*' || r == '`' || r == '_' || r == '|'
 // }
 
-// isSection compares a number of positions (skipping whitespace) to determine
-// if the runes are sectionAdornments and returns a true if the positions match
-// each other. Rune comparison begins at the current lexer position. isSection
-// returns false if there is a blank line between the positions or if there is
-// a rune mismatch between positions.
-func isSection(l *lexer) (found bool) {
-	var nLine string
+// sectionKind is the result of classifySection. It tells the lexer what kind
+// of section construct, if any, begins at the current lexer position.
+type sectionKind int
+
+const (
+	// notSection means the current line is not part of a section or a
+	// transition.
+	notSection sectionKind = iota
+
+	// sectionOverlined means the current line is the overline adornment of
+	// a section title.
+	sectionOverlined
 
+	// sectionUnderlined means the current line is a section title followed
+	// by an underline adornment, or the underline adornment itself.
+	sectionUnderlined
+
+	// transition means the current line is a transition marker.
+	transition
+)
+
+var sectionKinds = [...]string{
+	"notSection",
+	"sectionOverlined",
+	"sectionUnderlined",
+	"transition",
+}
+
+// String implements Stringer and returns the sectionKind as a string.
+func (k sectionKind) String() string { return sectionKinds[k] }
+
+// classifySection compares a number of positions (skipping whitespace) to
+// determine if the runes are sectionAdornments and returns the kind of section
+// construct found. Rune comparison begins at the current lexer position.
+//
+// An adornment line surrounded by blank lines (or the start of input) is a
+// transition. An adornment line directly after a title is an underline, any
+// other adornment line is an overline. A line followed by an adornment line is
+// a title with an underline. notSection is returned if there is a blank line
+// between the positions or if there is a rune mismatch between positions.
+func classifySection(l *lexer) sectionKind {
 	checkLine := func(input string, skipSpace bool) (a bool) {
+		if !l.isAdornmentLine(input) {
+			return false
+		}
+		// Look at the first two runes of the adornment. A single rune
+		// adornment, like the underline of a one character title, is
+		// valid, so the end of the line stops the lookahead.
 		end := 2
 		for j := 0; j < end; j++ {
+			if l.start+j >= len(input) {
+				return
+			}
 			r, _ := utf8.DecodeRuneInString(input[l.start+j:])
 			if skipSpace && isSpace(r) {
 				log.Debugln("Skipping space rune")
 				end++
 				continue
 			}
-			a = isSectionAdornment(r)
+			a = l.isSectionAdornment(r)
 			if !a {
 				return
 			}
@@ -388,37 +512,73 @@ func isSection(l *lexer) (found bool) {
 
 	log.Debugln("Checking for transition...")
 	if isTransition(l) {
-		log.Debugln("Returning (found transition)")
-		found = false
-		goto exit
+		log.Debugln("Found transition")
+		return transition
+	}
+
+	// Within a paragraph, an adornment line too short to be a section
+	// adornment is paragraph text, and so is the line it follows.
+	if l.lastItem != nil && l.lastItem.Type == itemParagraph {
+		if l.isShortAdornment(l.currentLine()) ||
+			l.isShortAdornment(l.peekNextLine()) {
+			log.Debugln("Section adornment not found (paragraph text)")
+			return notSection
+		}
 	}
 
 	if checkLine(l.currentLine(), false) {
-		log.Debugln("Found section adornment")
-		found = true
-		goto exit
+		if l.lastItem != nil && l.lastItem.Type == itemTitle {
+			log.Debugln("Found section underline")
+			return sectionUnderlined
+		}
+		log.Debugln("Found section overline")
+		return sectionOverlined
 	}
 
-	nLine = l.peekNextLine()
-	if nLine != "" {
+	if nLine := l.peekNextLine(); nLine != "" {
 		if checkLine(nLine, true) {
-			log.Debugln("Found section adornment")
-			found = true
+			log.Debugln("Found section title")
+			return sectionUnderlined
 		}
 	} else {
 		log.Debugln(`l.peekNextLine() == ""`)
 	}
 
-exit:
-	if !found {
-		log.Debugln("Section adornment not found")
-	}
-	return
+	log.Debugln("Section adornment not found")
+	return notSection
+}
+
+// isShortAdornment returns true if line is an adornment line shorter than the
+// three runes the parser requires of an underline shorter than its title.
+// Such a line is ordinary text when it follows paragraph text.
+func (l *lexer) isShortAdornment(line string) bool {
+	return l.isAdornmentLine(line) &&
+		utf8.RuneCountInString(strings.TrimSpace(line)) < 3
+}
+
+// isSection returns true if classifySection finds an overlined or underlined
+// section at the current lexer position.
+func isSection(l *lexer) bool {
+	k := classifySection(l)
+	return k == sectionOverlined || k == sectionUnderlined
 }
 
-// isSectionAdornment returns true if r matches a section adornment.
-func isSectionAdornment(r rune) bool {
-	for _, a := range sectionAdornments {
+// isAdornmentLine returns true if the line input, without the surrounding
+// whitespace, is made of a single repeated section adornment rune. A line whose
+// first adornment rune is escaped with a backslash, like "\=====", is not an
+// adornment, so it is lexed as paragraph text. The backslash is kept in the
+// text and is removed with the other escapes when the text is rendered.
+func (l *lexer) isAdornmentLine(input string) bool {
+	line := strings.TrimSpace(input)
+	r, _ := utf8.DecodeRuneInString(line)
+	return line != "" && strings.Trim(line, string(r)) == "" &&
+		l.isSectionAdornment(r)
+}
+
+// isSectionAdornment returns true if r matches a section adornment recognized
+// by the lexer.
+func (l *lexer) isSectionAdornment(r rune) bool {
+	for _, a := range l.adornments {
 		if a == r {
 			return true
 		}
@@ -427,7 +587,14 @@ func isSectionAdornment(r rune) bool {
 }
 
 func isTransition(l *lexer) bool {
-	if r := l.peek(); !isSectionAdornment(l.mark) || !isSectionAdornment(r) {
+	if !l.isSectionAdornment(l.mark) {
+		log.Debugln("Transition not found")
+		return false
+	}
+	// A transition marker is a line of the same adornment character. Markers
+	// shorter than four characters are parsed as paragraphs.
+	line := strings.TrimSpace(l.currentLine())
+	if strings.Trim(line, string(l.mark)) != "" {
 		log.Debugln("Transition not found")
 		return false
 	}
@@ -447,7 +614,10 @@ func isTransition(l *lexer) bool {
 	return false
 }
 
-func isComment(l *lexer) bool {
+// isExplicitMarkup returns true if the current line begins an explicit markup
+// block: two periods followed by whitespace or the end of the line, or two
+// underscores followed by whitespace and the link of an anonymous target.
+func isExplicitMarkup(l *lexer) bool {
 	if l.lastItem != nil && l.lastItem.Type == itemTitle {
 		return false
 	}
@@ -455,15 +625,215 @@ func isComment(l *lexer) bool {
 		l.next()
 		nMark2 := l.peek()
 		if isSpace(nMark2) || nMark2 == utf8.RuneError {
-			log.Debugln("Found comment!")
+			log.Debugln("Found explicit markup!")
 			return true
 		}
 		l.backup(1)
 	}
-	log.Debugln("Comment not found!")
+	if nMark := l.peek(); l.mark == '_' && nMark == '_' {
+		// "__ " followed by a link is the short form of an anonymous
+		// hyperlink target.
+		l.next()
+		if isSpace(l.peek()) &&
+			!lineIsBlank(l.lines[l.line][l.start+2:]) {
+			log.Debugln("Found anonymous target!")
+			return true
+		}
+		l.backup(1)
+	}
+	log.Debugln("Explicit markup not found!")
 	return false
 }
 
+// explicitMarkupKind is the result of explicitMarkupKindOf. It tells the parser
+// what kind of construct an explicit markup block contains.
+type explicitMarkupKind int
+
+const (
+	// explicitComment means the block is a comment. Blocks that do not
+	// match any of the other kinds are comments.
+	explicitComment explicitMarkupKind = iota
+
+	// explicitFootnote means the block begins with a footnote label, for
+	// example "[1]", "[#]", "[#note]", or "[*]".
+	explicitFootnote
+
+	// explicitCitation means the block begins with a citation label, for
+	// example "[CIT2002]".
+	explicitCitation
+
+	// explicitHyperlinkTarget means the block begins with "_" followed by
+	// a target name and a colon.
+	explicitHyperlinkTarget
+
+	// explicitAnonymousTarget means the block begins with "__:".
+	explicitAnonymousTarget
+
+	// explicitDirective means the block begins with a directive name
+	// followed by "::".
+	explicitDirective
+
+	// explicitSubstitutionDefinition means the block begins with
+	// substitution text enclosed by vertical bars.
+	explicitSubstitutionDefinition
+)
+
+var explicitMarkupKinds = [...]string{
+	"explicitComment",
+	"explicitFootnote",
+	"explicitCitation",
+	"explicitHyperlinkTarget",
+	"explicitAnonymousTarget",
+	"explicitDirective",
+	"explicitSubstitutionDefinition",
+}
+
+// String implements Stringer and returns the explicitMarkupKind as a string.
+func (k explicitMarkupKind) String() string { return explicitMarkupKinds[k] }
+
+// explicitMarkupKindOf returns the kind of the explicit markup block text, the
+// text after the ".." marker with the indentation of the block removed. The
+// constructs are recognized by the text at the start of the block.
+func explicitMarkupKindOf(text string) explicitMarkupKind {
+	switch {
+	case strings.HasPrefix(text, "["):
+		end := strings.Index(text, "]")
+		if end == -1 || !isExplicitMarkupEnd(text[end+1:]) {
+			break
+		}
+		if label := text[1:end]; isFootnoteLabel(label) {
+			return explicitFootnote
+		} else if isSimpleReferenceName(label) {
+			return explicitCitation
+		}
+	case strings.HasPrefix(text, "__:") && isExplicitMarkupEnd(text[3:]):
+		return explicitAnonymousTarget
+	case strings.HasPrefix(text, "_") && targetNameEnd(text[1:]) != -1:
+		return explicitHyperlinkTarget
+	case strings.HasPrefix(text, "|"):
+		end := strings.Index(text[1:], "|") + 1
+		if end < 2 || isSpace(rune(text[1])) || isSpace(rune(text[end-1])) {
+			break
+		}
+		if isExplicitMarkupEnd(text[end+1:]) {
+			return explicitSubstitutionDefinition
+		}
+	default:
+		end := strings.Index(text, "::")
+		if end > 0 && isSimpleReferenceName(text[:end]) &&
+			isExplicitMarkupEnd(text[end+2:]) {
+			return explicitDirective
+		}
+	}
+	return explicitComment
+}
+
+// isExplicitMarkupEnd returns true if text is empty or begins with whitespace.
+// The labels, names, and markers at the start of explicit markup blocks must
+// be followed by whitespace or the end of the block.
+func isExplicitMarkupEnd(text string) bool {
+	return text == "" || isSpace(rune(text[0]))
+}
+
+// isFootnoteLabel returns true if label is a footnote label: a number, "#"
+// optionally followed by a reference name, or "*".
+func isFootnoteLabel(label string) bool {
+	switch {
+	case label == "*" || label == "#":
+		return true
+	case strings.HasPrefix(label, "#"):
+		return isSimpleReferenceName(label[1:])
+	case label == "":
+		return false
+	}
+	for _, r := range label {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// isSimpleReferenceName returns true if name is a simple reference name:
+// alphanumerics separated by single hyphens, underscores, periods, colons, or
+// plus signs.
+func isSimpleReferenceName(name string) bool {
+	const separators = "-_.:+"
+	prev := '-'
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) &&
+			(!strings.ContainsRune(separators, r) ||
+				strings.ContainsRune(separators, prev)) {
+			return false
+		}
+		prev = r
+	}
+	return name != "" && !strings.ContainsRune(separators, prev)
+}
+
+// targetNameEnd returns the index of the colon that ends the hyperlink target
+// name at the start of text, or -1 if text does not begin with a target name
+// followed by a colon and whitespace. Colons in the name must be escaped with
+// a backslash, or the name must be enclosed in backquotes.
+func targetNameEnd(text string) int {
+	if strings.HasPrefix(text, "`") {
+		end := strings.Index(text[1:], "`") + 1
+		if end > 1 && strings.HasPrefix(text[end+1:], ":") &&
+			isExplicitMarkupEnd(text[end+2:]) {
+			return end + 1
+		}
+		return -1
+	}
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case ':':
+			if i > 0 && isExplicitMarkupEnd(text[i+1:]) {
+				return i
+			}
+			return -1
+		}
+	}
+	return -1
+}
+
+// splitHyperlinkTarget splits the text of a hyperlink target block, as passed
+// to explicitMarkupKindOf, into the target name and the link block.
+// The name is returned without backquotes and with whitespace normalized.
+//
+// The link block may be split over several indented lines. Whitespace is
+// removed from the link block to join the lines, so a URI can be wrapped
+// without adding spaces to it. Whitespace escaped with a backslash is kept.
+func splitHyperlinkTarget(block string) (name, link string) {
+	block = strings.TrimPrefix(block, "_")
+	end := targetNameEnd(block)
+	if end == -1 {
+		return "", ""
+	}
+	name = strings.Join(strings.Fields(strings.Trim(block[:end], "`")), " ")
+	return name, targetLink(block[end+1:])
+}
+
+// targetLink returns the link of a hyperlink target with the whitespace
+// removed and backslash escapes resolved.
+func targetLink(text string) string {
+	var buf []rune
+	escaped := false
+	for _, r := range text {
+		switch {
+		case escaped:
+			escaped = false
+			buf = append(buf, r)
+		case r == '\\':
+			escaped = true
+		case !unicode.IsSpace(r):
+			buf = append(buf, r)
+		}
+	}
+	return string(buf)
+}
+
 func isEnumList(l *lexer) (ret bool) {
 	bCount := 0
 	if isSection(l) {
@@ -473,11 +843,11 @@ func isEnumList(l *lexer) (ret bool) {
 		for {
 			bCount++
 			if nMark, _ := l.next(); !isArabic(nMark) {
-				if nMark == '.' || nMark == ' ' {
+				if nMark == '.' || nMark == ')' || nMark == ' ' {
 					log.Debugln("Found arabic enum list!")
 					ret = true
-					goto exit
 				}
+				goto exit
 			}
 		}
 	}
@@ -515,14 +885,7 @@ func isDefinitionTerm(l *lexer) bool {
 		return false
 	}
 	nL := l.peekNextLine()
-	sCount := 0
-	for {
-		if sCount < len(nL) && isSpace(rune(nL[sCount])) {
-			sCount++
-		} else {
-			break
-		}
-	}
+	sCount := lineIndent(nL, l.tabWidth)
 	log.Debugln("sCount =", sCount)
 	if sCount >= 2 {
 		log.Debugln("Found definition term!")
@@ -536,7 +899,7 @@ func isBlockquote(l *lexer) bool {
 	if !l.lastLineIsBlankLine() || l.lastItem.Type != itemSpace {
 		return false
 	}
-	if l.index != len(l.indentWidth) {
+	if l.measureIndent() != l.indentWidth {
 		return true
 	}
 	return false
@@ -554,19 +917,20 @@ func lexStart(l *lexer) stateFn {
 			!l.isEndOfLine() {
 			if l.index == 0 && l.mark != ' ' {
 				l.indentLevel = 0
-				l.indentWidth = ""
+				l.indentWidth = 0
 			}
 			log.Debugf("l.index: %d, l.width: %d, l.line: %d\n",
 				l.index, l.width, l.lineNumber())
-			if isComment(l) {
+			if isExplicitMarkup(l) {
 				return lexComment
 			} else if isBulletList(l) {
 				return lexBullet
 			} else if isEnumList(l) {
 				return lexEnumList
-			} else if isSection(l) {
+			} else if k := classifySection(l); k == sectionOverlined ||
+				k == sectionUnderlined {
 				return lexSection
-			} else if isTransition(l) {
+			} else if k == transition {
 				return lexTransition
 			} else if isSpace(l.mark) {
 				return lexSpace
@@ -622,15 +986,16 @@ func lexSpace(l *lexer) stateFn {
 	return lexStart
 }
 
-// lexSection is used after isSection() has determined that the next runes of
-// input are section.  From here, the lexTitle() and lexSectionAdornment() are
-// called based on the input.
+// lexSection is used after classifySection() has determined that the next
+// runes of input are section.  From here, the lexTitle() and
+// lexSectionAdornment() are called based on the input. An overline is lexed by
+// lexOverline, which lexes the title and the underline that must follow it.
 func lexSection(l *lexer) stateFn {
 	// log.Debugf("l.mark: %#U, l.index: %d, l.start: %d, l.width: %d, " +
 	// "l.line: %d\n", l.mark, l.index, l.start, l.width, l.lineNumber())
-	if isSectionAdornment(l.mark) {
-		if l.lastItem != nil && l.lastItem.Type != itemTitle {
-			return lexSectionAdornment
+	if l.isSectionAdornment(l.mark) && l.isAdornmentLine(l.currentLine()) {
+		if classifySection(l) == sectionOverlined {
+			return lexOverline
 		}
 		lexSectionAdornment(l)
 	} else if isSpace(l.mark) {
@@ -643,15 +1008,76 @@ func lexSection(l *lexer) stateFn {
 	return lexStart
 }
 
+// lexOverline emits the overline of an overlined section and moves the lexer
+// to the next line, the title is lexed by lexOverlineTitle.
+func lexOverline(l *lexer) stateFn {
+	lexSectionAdornment(l)
+	if l.isLastLine() {
+		// An overline followed by the end of input. The parser reports
+		// the invalid section marker.
+		return lexStart
+	}
+	l.next()
+	return lexOverlineTitle
+}
+
+// lexOverlineTitle lexes the line following an overline. The text of the line
+// is emitted as an itemTitle if the next line is an adornment line, the
+// underline is then lexed by lexOverlineUnderline. Otherwise the underline is
+// missing and the text is emitted as an itemParagraph, which the parser
+// reports as an incomplete section title. Spaces at the start of the line are
+// emitted as an itemSpace. A blank line or an adornment line is not a title,
+// it is lexed by lexStart.
+func lexOverlineTitle(l *lexer) stateFn {
+	if line := l.currentLine(); lineIsBlank(line) || l.isAdornmentLine(line) {
+		return lexStart
+	}
+	if isSpace(l.mark) {
+		lexSpace(l)
+	}
+	t := itemParagraph
+	if l.isAdornmentLine(l.peekNextLine()) {
+		t = itemTitle
+	}
+	for {
+		l.next()
+		if l.isEndOfLine() {
+			if t == itemTitle {
+				l.emitTrimmed(t)
+			} else {
+				l.emit(t)
+			}
+			break
+		}
+	}
+	if t == itemParagraph {
+		l.nextLine()
+		return lexStart
+	}
+	l.next()
+	return lexOverlineUnderline
+}
+
+// lexOverlineUnderline emits the underline of an overlined section. An
+// indented underline is lexed by lexStart.
+func lexOverlineUnderline(l *lexer) stateFn {
+	if isSpace(l.mark) {
+		return lexStart
+	}
+	lexSectionAdornment(l)
+	return lexStart
+}
+
 // lexTitle consumes input until newline and emits an itemTitle token. If
 // spaces are detected at the start of the line, an itemSpace is emitted.
-// Spaces after the title (and before newline) are ignored. On completion
-// control is returned to lexSection.
+// Whitespace after the title is not part of the itemTitle, so it does not count
+// towards the width of the title. On completion control is returned to
+// lexSection.
 func lexTitle(l *lexer) stateFn {
 	for {
 		l.next()
 		if l.isEndOfLine() {
-			l.emit(itemTitle)
+			l.emitTrimmed(itemTitle)
 			break
 		}
 	}
@@ -664,7 +1090,9 @@ func lexTitle(l *lexer) stateFn {
 func lexSectionAdornment(l *lexer) stateFn {
 	for {
 		if l.isEndOfLine() {
-			l.emit(itemSectionAdornment)
+			// Trailing whitespace is not part of the adornment, like
+			// in docutils, so it does not count towards its length.
+			l.emitTrimmed(itemSectionAdornment)
 			if l.mark == utf8.RuneError {
 				break
 			}
@@ -692,7 +1120,7 @@ func lexEnumList(l *lexer) stateFn {
 			if nMark, _ := l.next(); !isArabic(nMark) {
 				l.emit(itemEnumListArabic)
 				l.next()
-				if nMark == '.' {
+				if nMark == '.' || nMark == ')' {
 					l.emit(itemEnumListAffix)
 					l.next()
 				}
@@ -716,8 +1144,12 @@ func lexParagraph(l *lexer) stateFn {
 	return lexStart
 }
 
+// lexComment emits an itemCommentMark token for the ".." marker, or the "__"
+// marker of an anonymous target, and lexes the text of the comment. Every kind
+// of explicit markup block is lexed as a comment, the parser classifies the
+// text of the block with explicitMarkupKindOf.
 func lexComment(l *lexer) stateFn {
-	for l.mark == '.' {
+	for l.mark == '.' || l.mark == '_' {
 		l.next()
 	}
 	l.emit(itemCommentMark)
@@ -767,7 +1199,7 @@ func lexBullet(l *lexer) stateFn {
 	l.next()
 	l.emit(itemBullet)
 	lexSpace(l)
-	l.indentWidth += l.lastItem.Text + " "
+	l.indentWidth += utf8.RuneCountInString(l.lastItem.Text) + 1
 	lexParagraph(l)
 	l.indentLevel++
 	return lexStart
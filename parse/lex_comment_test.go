@@ -142,3 +142,36 @@ func TestLexCommentTrailingBulletGood0502(t *testing.T) {
 	items := lexTest(t, test)
 	equal(t, test.expectItems(), items)
 }
+
+func TestLexEmptyCommentBetweenBlockquotesGood0700(t *testing.T) {
+	// An empty comment separating two block quotes
+	testPath := testPathFromName("07.00-empty-comment-between-blockquotes")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexEmptyCommentBetweenBulletListsGood0701(t *testing.T) {
+	// An empty comment separating two bullet lists
+	testPath := testPathFromName("07.01-empty-comment-between-bullet-lists")
+	test := LoadLexTest(t, testPath)
+	items := lexTest(t, test)
+	equal(t, test.expectItems(), items)
+}
+
+func TestLexEmptyCommentAfterIndentedLine(t *testing.T) {
+	// The comment mark is shorter than the indentation of the line before
+	// it.
+	l := lex("empty comment", "Paragraph.\n\n    Quote.\n..\n")
+	var types []ElementType
+	for {
+		i := l.nextItem()
+		types = append(types, i.Type)
+		if i.Type == itemEOF {
+			break
+		}
+	}
+	if len(types) < 5 || types[4] != itemCommentMark {
+		t.Errorf("Got: %v, Expect: itemCommentMark after the quote", types)
+	}
+}
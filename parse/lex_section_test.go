@@ -4,7 +4,10 @@
 
 package parse
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 func TestLexSectionTitleGood0000(t *testing.T) {
 	// Basic title, underline, blankline, and paragraph test
@@ -297,3 +300,72 @@ func TestLexSectionTitleNumberedGood0100(t *testing.T) {
 	items := lexTest(t, test)
 	equal(t, test.expectItems(), items)
 }
+
+var overlineTests = []struct {
+	name    string
+	input   string
+	items   []ElementType // Expected items, without the itemEOF
+	message parserMessage // Expected parser message, if any
+}{
+	{
+		name:  "Overline, title, and underline",
+		input: "=====\nTitle\n=====",
+		items: []ElementType{itemSectionAdornment, itemTitle,
+			itemSectionAdornment},
+	},
+	{
+		name:  "Inset title",
+		input: "=======\n Title\n=======",
+		items: []ElementType{itemSectionAdornment, itemSpace, itemTitle,
+			itemSectionAdornment},
+	},
+	{
+		name:  "Title text beginning with a bullet",
+		input: "=======\n- Title\n=======",
+		items: []ElementType{itemSectionAdornment, itemTitle,
+			itemSectionAdornment},
+	},
+	{
+		name:    "Missing underline at the end of input",
+		input:   "=====\nTitle",
+		items:   []ElementType{itemSectionAdornment, itemParagraph},
+		message: severeIncompleteSectionTitle,
+	},
+	{
+		name:  "Missing underline before a blank line",
+		input: "=====\nTitle\n\nParagraph.",
+		items: []ElementType{itemSectionAdornment, itemParagraph,
+			itemBlankLine, itemParagraph},
+		message: severeMissingMatchingUnderlineForOverline,
+	},
+	{
+		name:  "Overline followed by the end of input",
+		input: "Title\n=====\n=====",
+		items: []ElementType{itemTitle, itemSectionAdornment,
+			itemSectionAdornment},
+		message: errorInvalidSectionOrTransitionMarker,
+	},
+}
+
+func TestLexSectionOverline(t *testing.T) {
+	for _, tt := range overlineTests {
+		var items []ElementType
+		l := lex(tt.name, tt.input)
+		for i := l.nextItem(); i.Type != itemEOF; i = l.nextItem() {
+			items = append(items, i.Type)
+		}
+		if !reflect.DeepEqual(items, tt.items) {
+			t.Errorf("Test: %q\n\t    Got: items = %v, Expect: %v\n\n",
+				tt.name, items, tt.items)
+		}
+		_, errors := Parse(tt.name, tt.input)
+		var msg parserMessage
+		if len(errors) > 0 {
+			msg = errors[0].(*SystemMessageNode).MessageType
+		}
+		if msg != tt.message {
+			t.Errorf("Test: %q\n\t    Got: message = %s, Expect: %s\n\n",
+				tt.name, msg, tt.message)
+		}
+	}
+}
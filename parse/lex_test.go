@@ -52,9 +52,9 @@ func equal(t *testing.T, expectItems []item, items []item) {
 		case ID:
 			got = pFieldVal.Interface().(ID).String()
 			exp = eFieldVal.Interface().(ID).String()
-		case itemElement:
-			got = pFieldVal.Interface().(itemElement).String()
-			exp = eFieldVal.Interface().(itemElement).String()
+		case ElementType:
+			got = pFieldVal.Interface().(ElementType).String()
+			exp = eFieldVal.Interface().(ElementType).String()
 		case Line:
 			got = pFieldVal.Interface().(Line).String()
 			exp = eFieldVal.Interface().(Line).String()
@@ -317,6 +317,60 @@ func TestLexerBackup(t *testing.T) {
 	}
 }
 
+// lexerStepTests step over runes of different byte widths with next, peek,
+// and backup. The index and mark are checked after each step.
+var lexerStepTests = []struct {
+	op    string // "next", "peek", or "backup"
+	index int
+	mark  rune
+}{
+	{"next", 1, '世'},
+	{"peek", 1, '世'},
+	{"next", 4, 'à'},
+	{"backup", 1, '世'},
+	{"peek", 1, '世'},
+	{"next", 4, 'à'},
+	{"next", 6, 'b'},
+	{"peek", 6, 'b'},
+	{"backup", 4, 'à'},
+	{"backup", 1, '世'},
+	{"backup", 0, 'a'},
+	{"backup", 0, 'a'},
+	{"next", 1, '世'},
+	{"next", 4, 'à'},
+	{"next", 6, 'b'},
+	{"next", 7, '界'},
+	{"peek", 7, '界'},
+	{"backup", 6, 'b'},
+	{"next", 7, '界'},
+	{"next", 10, utf8.RuneError},
+	{"backup", 7, '界'},
+	{"next", 10, utf8.RuneError},
+	{"next", 0, 'c'},
+	{"backup", 10, utf8.RuneError},
+	{"backup", 7, '界'},
+}
+
+func TestLexerStepMultiByte(t *testing.T) {
+	lex := newLexer("step multi-byte", "a世àb界\nc")
+	for num, tt := range lexerStepTests {
+		switch tt.op {
+		case "next":
+			lex.next()
+		case "peek":
+			lex.peek()
+		case "backup":
+			lex.backup(1)
+		}
+		if lex.index != tt.index || lex.mark != tt.mark {
+			t.Errorf("Test: step %d (%s)\n\t    "+
+				"Got: lex.index == %d, lex.mark == %#U, "+
+				"Expect: %d, %#U\n\n", num, tt.op, lex.index,
+				lex.mark, tt.index, tt.mark)
+		}
+	}
+}
+
 var lexerNextTests = []struct {
 	name      string
 	input     string
@@ -526,6 +580,58 @@ func TestLexerIsLastLine(t *testing.T) {
 	}
 }
 
+var measureIndentTests = []struct {
+	name      string
+	input     string
+	startLine int
+	tabWidth  int
+	indent    int
+}{
+	{
+		name:  "No indentation",
+		input: "Paragraph.", startLine: 1, indent: 0,
+	},
+	{
+		name:  "Space indentation",
+		input: "Paragraph.\n\n    Block quote.", startLine: 3, indent: 4,
+	},
+	{
+		name:  "Tab indentation",
+		input: "\tBlock quote.", startLine: 1, indent: 8,
+	},
+	{
+		name:  "Tab indentation with tab width",
+		input: "\t\tBlock quote.", startLine: 1, tabWidth: 4, indent: 8,
+	},
+	{
+		name:  "Spaces before a tab",
+		input: "   \tBlock quote.", startLine: 1, indent: 8,
+	},
+	{
+		name:  "Mixed indentation",
+		input: " \t  Block quote.", startLine: 1, tabWidth: 4, indent: 6,
+	},
+	{
+		name:  "Whitespace line",
+		input: "Paragraph.\n \t ", startLine: 2, indent: 9,
+	},
+}
+
+func TestLexerMeasureIndent(t *testing.T) {
+	for _, tt := range measureIndentTests {
+		lex := newLexer(tt.name, tt.input)
+		if tt.tabWidth > 0 {
+			lex.tabWidth = tt.tabWidth
+		}
+		lex.gotoLocation(0, tt.startLine)
+		if indent := lex.measureIndent(); indent != tt.indent {
+			t.Errorf("Test: %q\n\t    "+
+				"Got: measureIndent() == %d, Expect: %d\n\n",
+				lex.name, indent, tt.indent)
+		}
+	}
+}
+
 var peekNextLineTests = []struct {
 	name      string
 	input     string
@@ -590,6 +696,114 @@ func TestLexerPeekNextLine(t *testing.T) {
 	}
 }
 
+var lexerClassifySectionTests = []struct {
+	name      string
+	input     string
+	startLine int   // Begins at 1
+	lastItem  *item // The last item emitted by the lexer
+	kind      sectionKind
+}{
+	{
+		name:      "Paragraph",
+		input:     "Paragraph text.",
+		startLine: 1, kind: notSection,
+	},
+	{
+		name:      "Paragraph followed by blank line",
+		input:     "Paragraph text.\n\nParagraph 2.",
+		startLine: 1, kind: notSection,
+	},
+	{
+		name:      "Title with underline",
+		input:     "Title\n=====\n\nParagraph.",
+		startLine: 1, kind: sectionUnderlined,
+	},
+	{
+		name:      "Underline after title",
+		input:     "Title\n=====\n\nParagraph.",
+		startLine: 2, lastItem: &item{Type: itemTitle, Text: "Title"},
+		kind: sectionUnderlined,
+	},
+	{
+		name:      "Overline as first line of the document",
+		input:     "=====\nTitle\n=====\n\nParagraph.",
+		startLine: 1, kind: sectionOverlined,
+	},
+	{
+		name:      "Overline with missing underline",
+		input:     "=====\nTitle\n\nParagraph.",
+		startLine: 1, kind: sectionOverlined,
+	},
+	{
+		name:      "Transition as first line of the document",
+		input:     "=====\n\nParagraph.",
+		startLine: 1, kind: transition,
+	},
+	{
+		name:      "Transition as the only line of the document",
+		input:     "=====",
+		startLine: 1, kind: transition,
+	},
+	{
+		name:      "Transition between paragraphs",
+		input:     "Paragraph.\n\n=====\n\nParagraph 2.",
+		startLine: 3, lastItem: &item{Type: itemBlankLine, Text: "\n"},
+		kind: transition,
+	},
+}
+
+func TestLexerClassifySection(t *testing.T) {
+	for _, tt := range lexerClassifySectionTests {
+		lex := newLexer(tt.name, tt.input)
+		lex.gotoLocation(0, tt.startLine)
+		lex.lastItem = tt.lastItem
+		if kind := classifySection(lex); kind != tt.kind {
+			t.Errorf("Test: %q\n\t    "+
+				"Got: classifySection() == %s, Expect: %s\n\n",
+				tt.name, kind, tt.kind)
+		}
+	}
+}
+
+func TestElementTypeString(t *testing.T) {
+	if s := ElementType(0).String(); s != "itemEOF" {
+		t.Errorf("Got: ElementType(0).String() = %q, Expect: %q\n\n",
+			s, "itemEOF")
+	}
+	for num := range elements {
+		e := ElementType(num)
+		if got := ElementTypeFromString(e.String()); got != e {
+			t.Errorf("Test: %q\n\t    "+
+				"Got: ElementTypeFromString() = %d, Expect: %d\n\n",
+				e, got, e)
+		}
+	}
+	if got := ElementTypeFromString("itemUnknown"); got != -1 {
+		t.Errorf("Test: %q\n\t    "+
+			"Got: ElementTypeFromString() = %d, Expect: %d\n\n",
+			"itemUnknown", got, -1)
+	}
+}
+
+func TestElementTypeTable(t *testing.T) {
+	// The last element type must be the last entry of the elements table.
+	if int(itemMath)+1 != len(elements) {
+		t.Errorf("Got: len(elements) = %d, Expect: %d\n\n", len(elements),
+			int(itemMath)+1)
+	}
+	// The values of the existing element types are used by the fixtures.
+	if itemBullet != 17 || itemComment != 18 {
+		t.Errorf("Got: itemBullet = %d, itemComment = %d, "+
+			"Expect: 17, 18\n\n", itemBullet, itemComment)
+	}
+	for e := itemEOF; e <= itemMath; e++ {
+		if e.String() == "" {
+			t.Errorf("Got: ElementType(%d).String() = \"\", "+
+				"Expect: a name\n\n", int(e))
+		}
+	}
+}
+
 func TestLexId(t *testing.T) {
 	testPath := testPathFromName("00.00-title-paragraph")
 	test := LoadLexTest(t, testPath)
@@ -625,3 +839,192 @@ func TestLexStartPosition(t *testing.T) {
 		t.Error(`String StartPosition != "1"`)
 	}
 }
+
+var explicitMarkupKindTests = []struct {
+	name  string
+	input string
+	block string // The text of the block after the marker
+	kind  explicitMarkupKind
+}{
+	{
+		name:  "Comment",
+		input: ".. A comment",
+		block: "A comment",
+		kind:  explicitComment,
+	},
+	{
+		name:  "Empty comment",
+		input: "..",
+		block: "",
+		kind:  explicitComment,
+	},
+	{
+		name:  "Comment with indented block",
+		input: "..\n   A comment\n\n   continued\n\nParagraph",
+		block: "\nA comment\n\ncontinued",
+		kind:  explicitComment,
+	},
+	{
+		name:  "Footnote with number",
+		input: ".. [1] A footnote",
+		block: "[1] A footnote",
+		kind:  explicitFootnote,
+	},
+	{
+		name:  "Auto-numbered footnote with label",
+		input: ".. [#note] A footnote",
+		block: "[#note] A footnote",
+		kind:  explicitFootnote,
+	},
+	{
+		name:  "Auto-symbol footnote",
+		input: ".. [*] A footnote",
+		block: "[*] A footnote",
+		kind:  explicitFootnote,
+	},
+	{
+		name:  "Citation",
+		input: ".. [CIT2002] A citation",
+		block: "[CIT2002] A citation",
+		kind:  explicitCitation,
+	},
+	{
+		name:  "Bad label",
+		input: ".. [not a label] Text",
+		block: "[not a label] Text",
+		kind:  explicitComment,
+	},
+	{
+		name:  "Hyperlink target",
+		input: ".. _Python: http://www.python.org",
+		block: "_Python: http://www.python.org",
+		kind:  explicitHyperlinkTarget,
+	},
+	{
+		name:  "Hyperlink target on two lines",
+		input: ".. _a long target\n   name: http://www.python.org",
+		block: "_a long target\nname: http://www.python.org",
+		kind:  explicitHyperlinkTarget,
+	},
+	{
+		name:  "Hyperlink target with quoted name",
+		input: ".. _`FAQ: Questions`: faq.html",
+		block: "_`FAQ: Questions`: faq.html",
+		kind:  explicitHyperlinkTarget,
+	},
+	{
+		name:  "Hyperlink target without colon",
+		input: ".. _not a target",
+		block: "_not a target",
+		kind:  explicitComment,
+	},
+	{
+		name:  "Anonymous target",
+		input: ".. __: http://www.python.org",
+		block: "__: http://www.python.org",
+		kind:  explicitAnonymousTarget,
+	},
+	{
+		name:  "Directive",
+		input: ".. image:: picture.png\n   :alt: A picture",
+		block: "image:: picture.png\n:alt: A picture",
+		kind:  explicitDirective,
+	},
+	{
+		name:  "Directive without space",
+		input: ".. image::picture.png",
+		block: "image::picture.png",
+		kind:  explicitComment,
+	},
+	{
+		name:  "Substitution definition",
+		input: ".. |logo| image:: logo.png",
+		block: "|logo| image:: logo.png",
+		kind:  explicitSubstitutionDefinition,
+	},
+	{
+		name:  "Substitution text with surrounding space",
+		input: ".. | logo | image:: logo.png",
+		block: "| logo | image:: logo.png",
+		kind:  explicitComment,
+	},
+}
+
+func TestExplicitMarkupKindOf(t *testing.T) {
+	for _, tt := range explicitMarkupKindTests {
+		if kind := explicitMarkupKindOf(tt.block); kind != tt.kind {
+			t.Errorf("Test: %q\n\t    Got: kind == %s, "+
+				"Expect: %s\n\n", tt.name, kind, tt.kind)
+		}
+	}
+}
+
+func TestLexExplicitMarkup(t *testing.T) {
+	// The parser classifies explicit markup blocks, so the lexer emits an
+	// itemCommentMark for every kind of block.
+	for _, tt := range explicitMarkupKindTests {
+		l := lex(tt.name, tt.input)
+		if i := l.nextItem(); i.Type != itemCommentMark {
+			t.Errorf("Test: %q\n\t    Got: %s, Expect: %s\n\n",
+				tt.name, i.Type, itemCommentMark)
+		}
+	}
+}
+
+var splitHyperlinkTargetTests = []struct {
+	name   string
+	block  string // The text of the block after the marker
+	target string // The expected target name
+	link   string // The expected link block
+}{
+	{
+		name:   "Target on one line",
+		block:  "_Python: http://www.python.org/",
+		target: "Python",
+		link:   "http://www.python.org/",
+	},
+	{
+		name:   "URI split across indented lines",
+		block:  "_Python: http://www.python.org/\ndoc/\ncurrent/",
+		target: "Python",
+		link:   "http://www.python.org/doc/current/",
+	},
+	{
+		name:   "URI on the next line",
+		block:  "_Python:\nhttp://www.python.org/",
+		target: "Python",
+		link:   "http://www.python.org/",
+	},
+	{
+		name:   "Name split across lines",
+		block:  "_a long\ntarget name: http://example.com/",
+		target: "a long target name",
+		link:   "http://example.com/",
+	},
+	{
+		name:   "Quoted name with colon",
+		block:  "_`FAQ: Questions`: faq.html",
+		target: "FAQ: Questions",
+		link:   "faq.html",
+	},
+	{
+		name:   "Escaped space in link",
+		block:  `_file: my\ file.txt`,
+		target: "file",
+		link:   "my file.txt",
+	},
+}
+
+func TestSplitHyperlinkTarget(t *testing.T) {
+	for _, tt := range splitHyperlinkTargetTests {
+		target, link := splitHyperlinkTarget(tt.block)
+		if target != tt.target {
+			t.Errorf("Test: %q\n\t    Got: target == %q, "+
+				"Expect: %q\n\n", tt.name, target, tt.target)
+		}
+		if link != tt.link {
+			t.Errorf("Test: %q\n\t    Got: link == %q, "+
+				"Expect: %q\n\n", tt.name, link, tt.link)
+		}
+	}
+}
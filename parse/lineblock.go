@@ -0,0 +1,130 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// isLineBlockLine returns true if text begins with the line block marker "|"
+// followed by whitespace, or is only the marker.
+func isLineBlockLine(text string) bool {
+	return text == "|" || strings.HasPrefix(text, "| ") ||
+		strings.HasPrefix(text, "|\t")
+}
+
+// lineBlockLine is a line of a line block before the lines are nested. indent
+// is the number of spaces between the marker and the text of the line, or -1
+// for an empty line.
+type lineBlockLine struct {
+	text   *item
+	marker StartPosition
+	indent int
+}
+
+// lineBlock returns the LineBlockNode of the line block beginning with the
+// paragraph or definition term i, and skips the tokens of the block. The block
+// contains the lines beginning with the marker at the indentation of i, and
+// their continuation lines, which are indented more than the marker. The
+// block ends at the first blank line, or at the first line that is not a
+// line of the block. The indentation of the lines is measured from the marker,
+// so the block is parsed the same way at any indentation, for example in a
+// block quote.
+func (t *Tree) lineBlock(i *item) Node {
+	lines := strings.Split(t.text, "\n")
+	indent := lineIndent(lines[i.Line-1], t.tabWidth())
+	var bl []*lineBlockLine
+	end := int(i.Line) - 1
+	for ; end < len(lines) && !lineIsBlank(lines[end]); end++ {
+		line := lines[end]
+		text := strings.TrimLeft(line, " \t")
+		in := lineIndent(line, t.tabWidth())
+		if in == indent && isLineBlockLine(text) {
+			bl = append(bl, newLineBlockLine(line, text, end))
+			continue
+		}
+		if in <= indent {
+			break
+		}
+		// A continuation line of the previous line.
+		l := bl[len(bl)-1].text
+		l.Text += "\n" + strings.TrimRight(text, " \t")
+		l.Length = len(l.Text)
+	}
+	for t.peek(1).Type != itemEOF && t.peek(1).Line <= Line(end) {
+		t.next(1)
+	}
+	// Empty lines are nested like the line before them.
+	prev := 0
+	for _, l := range bl {
+		if l.indent == -1 {
+			l.indent = prev
+		}
+		prev = l.indent
+	}
+	n := newLineBlock(&item{Line: i.Line, StartPosition: bl[0].marker},
+		&t.id)
+	t.nestLineBlock(n, bl)
+	return n
+}
+
+// newLineBlockLine returns the lineBlockLine of the marked line with the index
+// num in the input. text is line without its indentation.
+func newLineBlockLine(line, text string, num int) *lineBlockLine {
+	marker := utf8.RuneCountInString(line) - utf8.RuneCountInString(text) + 1
+	// The marker is followed by whitespace, the indentation of the line
+	// begins after it.
+	rest := text[1:]
+	if rest != "" {
+		rest = rest[1:]
+	}
+	content := strings.TrimLeft(rest, " \t")
+	l := &lineBlockLine{
+		text: &item{
+			Text: strings.TrimRight(content, " \t"),
+			Line: Line(num + 1),
+			StartPosition: StartPosition(marker + 1 +
+				utf8.RuneCountInString(text[1:]) -
+				utf8.RuneCountInString(content)),
+		},
+		marker: StartPosition(marker),
+		indent: len(rest) - len(content),
+	}
+	l.text.Length = len(l.text.Text)
+	if l.text.Text == "" {
+		l.indent = -1
+	}
+	return l
+}
+
+// nestLineBlock adds the lines bl to the line block n, like the docutils
+// nest_line_block_segment. The least indented lines are added as LineNodes,
+// and each run of lines indented more than them is added as a nested
+// LineBlockNode.
+func (t *Tree) nestLineBlock(n *LineBlockNode, bl []*lineBlockLine) {
+	least := bl[0].indent
+	for _, l := range bl {
+		if l.indent < least {
+			least = l.indent
+		}
+	}
+	for k := 0; k < len(bl); {
+		if bl[k].indent == least {
+			n.NodeList.append(newLine(bl[k].text, &t.id))
+			k++
+			continue
+		}
+		j := k
+		for j < len(bl) && bl[j].indent > least {
+			j++
+		}
+		nested := newLineBlock(&item{Line: bl[k].text.Line,
+			StartPosition: bl[k].marker}, &t.id)
+		t.nestLineBlock(nested, bl[k:j])
+		n.NodeList.append(nested)
+		k = j
+	}
+}
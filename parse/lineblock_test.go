@@ -0,0 +1,96 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+func TestParseLineBlockInBlockQuote(t *testing.T) {
+	input := "Paragraph.\n\n    | One\n    | Two\n    | Three\n\n    Quote."
+	tr, errors := Parse("line-block-in-block-quote", input)
+	if len(errors) != 0 {
+		t.Errorf("Got: len(errors) = %d, Expect: 0", len(errors))
+	}
+	if len(tr.Nodes) != 2 {
+		t.Fatalf("Got: len(Nodes) = %d, Expect: 2", len(tr.Nodes))
+	}
+	bq, ok := tr.Nodes[1].(*BlockQuoteNode)
+	if !ok || len(bq.NodeList) != 2 {
+		t.Fatalf("Got: Nodes[1] = %#v, Expect: block quote with 2 nodes",
+			tr.Nodes[1])
+	}
+	lb, ok := bq.NodeList[0].(*LineBlockNode)
+	if !ok || len(lb.NodeList) != 3 {
+		t.Fatalf("Got: %#v, Expect: line block with 3 lines",
+			bq.NodeList[0])
+	}
+	if lb.Line != 3 || lb.StartPosition != 5 {
+		t.Errorf("Got: line block at %d:%d, Expect: 3:5", lb.Line,
+			lb.StartPosition)
+	}
+	for num, text := range []string{"One", "Two", "Three"} {
+		l, ok := lb.NodeList[num].(*LineNode)
+		if !ok || l.Text != text || l.Line != Line(num+3) ||
+			l.StartPosition != 7 {
+			t.Errorf("Got: NodeList[%d] = %#v, Expect: line %q at %d:7",
+				num, lb.NodeList[num], text, num+3)
+		}
+	}
+	if p, ok := bq.NodeList[1].(*ParagraphNode); !ok || p.Text != "Quote." {
+		t.Errorf("Got: %#v, Expect: paragraph \"Quote.\"", bq.NodeList[1])
+	}
+	if errs := tr.Validate(); len(errs) != 0 {
+		t.Errorf("Got: Validate() = %v, Expect: no errors", errs)
+	}
+}
+
+func TestParseLineBlockNesting(t *testing.T) {
+	input := "| One\n|   Two\n|     Three\n|   Four\n|\n| Five\n" +
+		"   continued\n\nParagraph."
+	tr, _ := Parse("line-block-nesting", input)
+	if len(tr.Nodes) != 2 {
+		t.Fatalf("Got: len(Nodes) = %d, Expect: 2", len(tr.Nodes))
+	}
+	// texts returns the text of the lines of lb, with the texts of the
+	// nested line blocks in brackets.
+	var texts func(lb *LineBlockNode) string
+	texts = func(lb *LineBlockNode) (s string) {
+		for _, n := range lb.NodeList {
+			switch n := n.(type) {
+			case *LineNode:
+				s += "(" + n.Text + ")"
+			case *LineBlockNode:
+				s += "[" + texts(n) + "]"
+			}
+		}
+		return
+	}
+	lb, ok := tr.Nodes[0].(*LineBlockNode)
+	if !ok {
+		t.Fatalf("Got: Nodes[0] = %s, Expect: NodeLineBlock",
+			tr.Nodes[0].NodeType())
+	}
+	expect := "(One)[(Two)[(Three)](Four)()](Five\ncontinued)"
+	if s := texts(lb); s != expect {
+		t.Errorf("Got: %q, Expect: %q", s, expect)
+	}
+}
+
+func TestIsLineBlockLine(t *testing.T) {
+	for _, tt := range []struct {
+		text string
+		is   bool
+	}{
+		{"| text", true},
+		{"|\ttext", true},
+		{"|", true},
+		{"|text", false},
+		{"||", false},
+	} {
+		if is := isLineBlockLine(tt.text); is != tt.is {
+			t.Errorf("Test: %q\n\t    Got: %t, Expect: %t\n\n", tt.text,
+				is, tt.is)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+func TestParseBulletListItemBody(t *testing.T) {
+	input := "- First paragraph.\n\n  Second paragraph.\n\n" +
+		"  - Nested item.\n\n- ``Next`` item."
+	tr, errors := Parse("list item body", input)
+	if len(errors) != 0 {
+		t.Fatalf("Got: len(errors) = %d, Expect: 0", len(errors))
+	}
+	if len(tr.Nodes) != 1 {
+		t.Fatalf("Got: len(Nodes) = %d, Expect: 1", len(tr.Nodes))
+	}
+	list := tr.Nodes[0].(*BulletListNode)
+	if len(list.NodeList) != 2 {
+		t.Fatalf("Got: len(NodeList) = %d, Expect: 2", len(list.NodeList))
+	}
+	item := list.NodeList[0].(*BulletListItemNode)
+	expect := []struct {
+		typ  NodeType
+		line Line
+		pos  StartPosition
+	}{
+		{NodeParagraph, 1, 3},
+		{NodeParagraph, 3, 3},
+		{NodeBulletList, 5, 0},
+	}
+	if len(item.NodeList) != len(expect) {
+		t.Fatalf("Got: len(item.NodeList) = %d, Expect: %d",
+			len(item.NodeList), len(expect))
+	}
+	for num, exp := range expect {
+		n := item.NodeList[num]
+		var line Line
+		var pos StartPosition
+		switch n := n.(type) {
+		case *ParagraphNode:
+			line, pos = n.Line, n.StartPosition
+		case *BulletListNode:
+			line = n.Line
+		}
+		if n.NodeType() != exp.typ || line != exp.line || pos != exp.pos {
+			t.Errorf("Got: NodeList[%d] = %s on line %d column %d, "+
+				"Expect: %s on line %d column %d", num,
+				n.NodeType(), line, pos, exp.typ, exp.line, exp.pos)
+		}
+	}
+	nested := item.NodeList[2].(*BulletListNode)
+	if len(nested.NodeList) != 1 {
+		t.Fatalf("Got: len(nested.NodeList) = %d, Expect: 1",
+			len(nested.NodeList))
+	}
+	p := nested.NodeList[0].(*BulletListItemNode).NodeList[0].(*ParagraphNode)
+	if p.Text != "Nested item." || p.Line != 5 || p.StartPosition != 5 {
+		t.Errorf("Got: %q on line %d column %d, Expect: %q on line 5 "+
+			"column 5", p.Text, p.Line, p.StartPosition, "Nested item.")
+	}
+	next := list.NodeList[1].(*BulletListItemNode)
+	if p, ok := next.NodeList[0].(*ParagraphNode); !ok ||
+		p.Text != "``Next`` item." {
+		t.Errorf("Got: %#v, Expect: paragraph %q", next.NodeList[0],
+			"``Next`` item.")
+	}
+	if errs := tr.Validate(); len(errs) != 0 {
+		t.Errorf("Got: Validate() = %v, Expect: no errors", errs)
+	}
+}
+
+var listUnindentTests = []struct {
+	name        string
+	input       string
+	nodes       []NodeType // The types of the top level nodes
+	messageType parserMessage
+	line        Line
+}{
+	{
+		name:        "Bullet list dedent without a blank line",
+		input:       "- First item.\n- Second item.\nParagraph.",
+		nodes:       []NodeType{NodeBulletList, NodeSystemMessage, NodeParagraph},
+		messageType: warningBulletListWithUnIndent,
+		line:        3,
+	},
+	{
+		name:        "Bullet list with a different bullet",
+		input:       "- First item.\n* Second item.",
+		nodes:       []NodeType{NodeBulletList, NodeSystemMessage, NodeBulletList},
+		messageType: warningBulletListWithUnIndent,
+		line:        2,
+	},
+	{
+		name:        "Enumerated list dedent without a blank line",
+		input:       "1. First item.\n2. Second item.\nParagraph.",
+		nodes:       []NodeType{NodeEnumList, NodeSystemMessage, NodeParagraph},
+		messageType: warningEnumListWithUnIndent,
+		line:        3,
+	},
+	{
+		name:        "Enumerated list format change",
+		input:       "1. First item.\n2) Second item.",
+		nodes:       []NodeType{NodeEnumList, NodeSystemMessage, NodeEnumList},
+		messageType: warningEnumListWithUnIndent,
+		line:        2,
+	},
+	{
+		name:        "Paragraph followed by a bullet list without a blank line",
+		input:       "Paragraph.\n- First item.",
+		nodes:       []NodeType{NodeParagraph, NodeSystemMessage, NodeBulletList},
+		messageType: warningParagraphWithoutBlankLine,
+		line:        2,
+	},
+	{
+		name:        "Paragraph followed by an enumerated list without a blank line",
+		input:       "Some\nparagraph.\n1. First item.",
+		nodes:       []NodeType{NodeParagraph, NodeSystemMessage, NodeEnumList},
+		messageType: warningParagraphWithoutBlankLine,
+		line:        3,
+	},
+	{
+		name:        "Paragraph followed by a comment without a blank line",
+		input:       "Paragraph.\n.. A comment.",
+		nodes:       []NodeType{NodeParagraph, NodeSystemMessage, NodeComment},
+		messageType: warningParagraphWithoutBlankLine,
+		line:        2,
+	},
+	{
+		name:        "Definition list followed by a bullet list without a blank line",
+		input:       "Term\n    Definition.\n- First item.",
+		nodes:       []NodeType{NodeDefinitionList, NodeSystemMessage, NodeBulletList},
+		messageType: warningDefinitionListWithUnIndent,
+		line:        3,
+	},
+	{
+		name:        "Field list followed by a bullet list without a blank line",
+		input:       ":Field: Body.\n- First item.",
+		nodes:       []NodeType{NodeFieldList, NodeSystemMessage, NodeBulletList},
+		messageType: warningFieldListWithUnIndent,
+		line:        2,
+	},
+	{
+		name:        "Line block followed by a comment without a blank line",
+		input:       "| A line.\n.. A comment.",
+		nodes:       []NodeType{NodeLineBlock, NodeSystemMessage, NodeComment},
+		messageType: warningLineBlockWithoutBlankLine,
+		line:        2,
+	},
+	{
+		name:  "Block quote followed by an enumerated list without a blank line",
+		input: "Paragraph.\n\n    Quote.\n1. First item.",
+		nodes: []NodeType{NodeParagraph, NodeBlockQuote, NodeSystemMessage,
+			NodeEnumList},
+		messageType: warningBlockQuoteWithUnIndent,
+		line:        4,
+	},
+}
+
+func TestParseListUnexpectedUnindent(t *testing.T) {
+	for _, tt := range listUnindentTests {
+		tr, _ := Parse(tt.name, tt.input)
+		if len(tr.Nodes) != len(tt.nodes) {
+			t.Errorf("Test: %q\n\t    Got: len(Nodes) = %d, Expect: %d\n\n",
+				tt.name, len(tr.Nodes), len(tt.nodes))
+			continue
+		}
+		for num, typ := range tt.nodes {
+			if tr.Nodes[num].NodeType() != typ {
+				t.Errorf("Test: %q\n\t    Got: Nodes[%d] = %s, "+
+					"Expect: %s\n\n", tt.name, num,
+					tr.Nodes[num].NodeType(), typ)
+			}
+		}
+		if len(tr.Diagnostics) != 1 {
+			t.Errorf("Test: %q\n\t    Got: len(Diagnostics) = %d, "+
+				"Expect: 1\n\n", tt.name, len(tr.Diagnostics))
+			continue
+		}
+		d := tr.Diagnostics[0]
+		if d.MessageType != tt.messageType || d.Line != tt.line {
+			t.Errorf("Test: %q\n\t    Got: %s on line %d, "+
+				"Expect: %s on line %d\n\n", tt.name, d.MessageType,
+				d.Line, tt.messageType, tt.line)
+		}
+	}
+}
+
+func TestParseEnumListFormatChange(t *testing.T) {
+	tr, _ := Parse("enum list format change", "1. First item.\n2) Second item.")
+	if len(tr.Nodes) != 3 {
+		t.Fatalf("Got: len(Nodes) = %d, Expect: 3", len(tr.Nodes))
+	}
+	first, second := tr.Nodes[0].(*EnumListNode), tr.Nodes[2].(*EnumListNode)
+	if first.Affix != enumAffixPeriod || len(first.NodeList) != 1 {
+		t.Errorf("Got: Affix = %s, len(NodeList) = %d, Expect: %s, 1",
+			first.Affix, len(first.NodeList), enumAffixPeriod)
+	}
+	if second.Affix != enumAffixParenthesisRight || len(second.NodeList) != 1 {
+		t.Errorf("Got: Affix = %s, len(NodeList) = %d, Expect: %s, 1",
+			second.Affix, len(second.NodeList), enumAffixParenthesisRight)
+	}
+	if errs := tr.Validate(); len(errs) != 0 {
+		t.Errorf("Got: Validate() = %v, Expect: no errors", errs)
+	}
+}
+
+func TestParseDefinitionListClassifiers(t *testing.T) {
+	input := "Paragraph.\n\nterm : classifier one : classifier two\n" +
+		"    First paragraph of the\n    definition.\n\n" +
+		"    Second paragraph.\n\nAfter the list."
+	tr, errors := Parse("definition list classifiers", input)
+	if len(errors) != 0 {
+		t.Fatalf("Got: len(errors) = %d, Expect: 0", len(errors))
+	}
+	if len(tr.Nodes) != 3 {
+		t.Fatalf("Got: len(Nodes) = %d, Expect: 3", len(tr.Nodes))
+	}
+	list := tr.Nodes[1].(*DefinitionListNode)
+	if len(list.NodeList) != 1 {
+		t.Fatalf("Got: len(NodeList) = %d, Expect: 1", len(list.NodeList))
+	}
+	item := list.NodeList[0].(*DefinitionListItemNode)
+	if item.Term.Text != "term" || item.Term.Length != 4 {
+		t.Errorf("Got: Term = %q, Length = %d, Expect: %q, 4",
+			item.Term.Text, item.Term.Length, "term")
+	}
+	expect := []struct {
+		text string
+		pos  StartPosition
+	}{
+		{"classifier one", 8},
+		{"classifier two", 25},
+	}
+	if len(item.Classifiers) != len(expect) {
+		t.Fatalf("Got: len(Classifiers) = %d, Expect: %d",
+			len(item.Classifiers), len(expect))
+	}
+	for num, exp := range expect {
+		c := item.Classifiers[num].(*ClassifierNode)
+		if c.Text != exp.text || c.StartPosition != exp.pos || c.Line != 3 {
+			t.Errorf("Got: Classifiers[%d] = %q on line %d column %d, "+
+				"Expect: %q on line 3 column %d", num, c.Text, c.Line,
+				c.StartPosition, exp.text, exp.pos)
+		}
+	}
+	def := item.Definition.NodeList
+	if len(def) != 2 {
+		t.Fatalf("Got: len(Definition.NodeList) = %d, Expect: 2", len(def))
+	}
+	paras := []struct {
+		text string
+		line Line
+	}{
+		{"First paragraph of the\ndefinition.", 4},
+		{"Second paragraph.", 7},
+	}
+	for num, exp := range paras {
+		p := def[num].(*ParagraphNode)
+		if p.Text != exp.text || p.Line != exp.line || p.StartPosition != 5 {
+			t.Errorf("Got: %q on line %d column %d, Expect: %q on line "+
+				"%d column 5", p.Text, p.Line, p.StartPosition, exp.text,
+				exp.line)
+		}
+	}
+	if p, ok := tr.Nodes[2].(*ParagraphNode); !ok || p.Text != "After the list." {
+		t.Errorf("Got: %#v, Expect: paragraph %q", tr.Nodes[2],
+			"After the list.")
+	}
+	if errs := tr.Validate(); len(errs) != 0 {
+		t.Errorf("Got: Validate() = %v, Expect: no errors", errs)
+	}
+	// The classifiers are numbered before the definition.
+	checkIDsInDocumentOrder(t, tr.Nodes)
+}
+
+func TestParseBulletListEndsAtSection(t *testing.T) {
+	tr, _ := Parse("list before section", "- One.\n\nTitle\n=====\n\n- Two.")
+	if len(tr.Nodes) != 2 {
+		t.Fatalf("Got: len(Nodes) = %d, Expect: 2", len(tr.Nodes))
+	}
+	if l := tr.Nodes[0].(*BulletListNode); len(l.NodeList) != 1 {
+		t.Errorf("Got: len(NodeList) = %d, Expect: 1", len(l.NodeList))
+	}
+	sec := tr.Nodes[1].(*SectionNode)
+	if len(sec.NodeList) != 1 || sec.NodeList[0].NodeType() != NodeBulletList {
+		t.Errorf("Got: section NodeList = %v, Expect: one bullet list",
+			sec.NodeList)
+	}
+}
+
+var enumListStartTests = []struct {
+	name   string
+	input  string
+	starts []int // The start of each enumerated list
+	items  []int // The number of items of each enumerated list
+}{
+	{"Start at one", "1. One.\n2. Two.", []int{1}, []int{2}},
+	{"Start at five", "5. Five.\n6. Six.\n7. Seven.", []int{5}, []int{3}},
+	{"Start at five with blank lines", "5. Five.\n\n6. Six.\n\n7. Seven.",
+		[]int{5}, []int{3}},
+	{"Non-consecutive items", "1. One.\n\n3. Three.\n\n4. Four.",
+		[]int{1, 3}, []int{1, 2}},
+	{"Affix change", "1. One.\n\n2) Two.", []int{1, 2}, []int{1, 1}},
+}
+
+func TestParseEnumListStart(t *testing.T) {
+	for _, tt := range enumListStartTests {
+		tr, errors := Parse(tt.name, tt.input)
+		if len(errors) != 0 {
+			t.Errorf("Test: %q\n\t    Got: len(errors) = %d, Expect: 0\n\n",
+				tt.name, len(errors))
+		}
+		var lists []*EnumListNode
+		for _, n := range tr.Nodes {
+			if l, ok := n.(*EnumListNode); ok {
+				lists = append(lists, l)
+			}
+		}
+		if len(lists) != len(tt.starts) {
+			t.Errorf("Test: %q\n\t    Got: %d lists, Expect: %d\n\n",
+				tt.name, len(lists), len(tt.starts))
+			continue
+		}
+		for num, l := range lists {
+			if l.Start != tt.starts[num] ||
+				len(l.NodeList) != tt.items[num] {
+				t.Errorf("Test: %q\n\t    Got: list %d Start = %d with "+
+					"%d items, Expect: %d with %d items\n\n",
+					tt.name, num, l.Start, len(l.NodeList),
+					tt.starts[num], tt.items[num])
+			}
+		}
+	}
+}
@@ -0,0 +1,294 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseLiteralBlockTrailingWhitespace(t *testing.T) {
+	input := "Art::\n\n    +--+  \n    |  |\t\n      \\/   \n\nAfter."
+	tr, errors := Parse("literal-block", input)
+	if len(errors) != 0 {
+		t.Fatalf("Got: len(errors) = %d, Expect: 0", len(errors))
+	}
+	if len(tr.Nodes) != 3 {
+		t.Fatalf("Got: len(Nodes) = %d, Expect: 3", len(tr.Nodes))
+	}
+	lb, ok := tr.Nodes[1].(*LiteralBlockNode)
+	if !ok {
+		t.Fatalf("Got: Nodes[1] = %s, Expect: NodeLiteralBlock",
+			tr.Nodes[1].NodeType())
+	}
+	text := "+--+  \n|  |\t\n  \\/   "
+	if lb.Text != text {
+		t.Errorf("Got: Text = %q, Expect: %q", lb.Text, text)
+	}
+	if lb.Line != 3 || lb.StartPosition != 5 {
+		t.Errorf("Got: literal block at %d:%d, Expect: 3:5", lb.Line,
+			lb.StartPosition)
+	}
+	if p, ok := tr.Nodes[2].(*ParagraphNode); !ok || p.Text != "After." {
+		t.Errorf("Got: Nodes[2] = %#v, Expect: paragraph \"After.\"",
+			tr.Nodes[2])
+	}
+}
+
+func TestParseParsedLiteralTrailingWhitespace(t *testing.T) {
+	input := ".. parsed-literal::\n\n   One  \n   Two\t\n"
+	tr, _ := Parse("parsed-literal", input)
+	if len(tr.Nodes) != 1 {
+		t.Fatalf("Got: len(Nodes) = %d, Expect: 1", len(tr.Nodes))
+	}
+	pl := tr.Nodes[0].(*ParsedLiteralNode)
+	if text := "One  \nTwo\t"; pl.Text != text {
+		t.Errorf("Got: Text = %q, Expect: %q", pl.Text, text)
+	}
+}
+
+var literalMarkerTests = []struct {
+	name  string
+	input string
+	para  string // Expected paragraph text, empty if there is no paragraph
+	line  Line   // Expected line of the literal block
+}{
+	{"Partially minimized", "Text::\n\n    code", "Text:", 3},
+	{"Fully minimized", "Text ::\n\n    code", "Text", 3},
+	{"Expanded", "Text\n\n::\n\n    code", "Text", 5},
+	{"Marker alone", "::\n\n    code", "", 3},
+	{"Multi-line paragraph", "One\ntwo ::\n\n    code", "One\ntwo", 4},
+}
+
+func TestParseLiteralBlockMarker(t *testing.T) {
+	for _, tt := range literalMarkerTests {
+		tr, _ := Parse(tt.name, tt.input)
+		nodes := tr.Nodes
+		if tt.para != "" {
+			p, ok := nodes[0].(*ParagraphNode)
+			if !ok || p.Text != tt.para || p.Length != len(tt.para) {
+				t.Errorf("Test: %q\n\t    Got: Nodes[0] = %#v, Expect: "+
+					"paragraph %q\n\n", tt.name, nodes[0], tt.para)
+			}
+			nodes = nodes[1:]
+		}
+		if len(nodes) != 1 {
+			t.Errorf("Test: %q\n\t    Got: %d nodes after the paragraph, "+
+				"Expect: 1\n\n", tt.name, len(nodes))
+			continue
+		}
+		lb, ok := nodes[0].(*LiteralBlockNode)
+		if !ok || lb.Text != "code" || lb.Line != tt.line {
+			t.Errorf("Test: %q\n\t    Got: %#v, Expect: literal block "+
+				"\"code\" on line %d\n\n", tt.name, nodes[0], tt.line)
+		}
+		if errs := tr.Validate(); len(errs) != 0 {
+			t.Errorf("Test: %q\n\t    Got: Validate() = %v, Expect: no "+
+				"errors\n\n", tt.name, errs)
+		}
+	}
+}
+
+var literalIndentTests = []struct {
+	name  string
+	input string
+	text  string        // Expected text of the literal block
+	pos   StartPosition // Expected column of the literal block
+}{
+	{"Two spaces", "Para::\n\n  one\n    two\n\nAfter.", "one\n  two", 3},
+	{"One space", "Para::\n\n one\n\nAfter.", "one", 2},
+	{"Least indented line last", "Para::\n\n   one\n  two\n\nAfter.",
+		" one\ntwo", 3},
+	{"In a block quote", "    Quote::\n\n      one\n        two\n\n    Quote.",
+		"one\n  two", 7},
+	{"In a bullet list item", "- Item::\n\n    one\n      two\n\n- Next",
+		"one\n  two", 5},
+}
+
+func TestParseLiteralBlockIndentation(t *testing.T) {
+	for _, tt := range literalIndentTests {
+		tr, _ := Parse(tt.name, tt.input)
+		var lb *LiteralBlockNode
+		Walk(tr.Nodes, func(n Node) bool {
+			if l, ok := n.(*LiteralBlockNode); ok && lb == nil {
+				lb = l
+			}
+			return true
+		})
+		if lb == nil {
+			t.Errorf("Test: %q\n\t    Got: no literal block, Expect: "+
+				"%q\n\n", tt.name, tt.text)
+			continue
+		}
+		if lb.Text != tt.text || lb.StartPosition != tt.pos {
+			t.Errorf("Test: %q\n\t    Got: %q at column %d, Expect: %q "+
+				"at column %d\n\n", tt.name, lb.Text,
+				lb.StartPosition, tt.text, tt.pos)
+		}
+	}
+}
+
+var missingLiteralBlockTests = []struct {
+	name  string
+	input string
+	types []NodeType // Expected types of the top level nodes
+	para  string     // Expected text of the first paragraph, if any
+	line  Line       // Expected line of the system message
+}{
+	{
+		name:  "Paragraph follows",
+		input: "Text::\n\nNot indented\n",
+		types: []NodeType{NodeParagraph, NodeSystemMessage,
+			NodeParagraph},
+		para: "Text:",
+		line: 3,
+	},
+	{
+		name:  "Fully minimized",
+		input: "Text ::\n\nNot indented\n",
+		types: []NodeType{NodeParagraph, NodeSystemMessage,
+			NodeParagraph},
+		para: "Text",
+		line: 3,
+	},
+	{
+		name:  "Marker alone",
+		input: "::\n\nNot indented\n",
+		types: []NodeType{NodeSystemMessage, NodeParagraph},
+		line:  3,
+	},
+	{
+		name:  "End of input",
+		input: "Text::",
+		types: []NodeType{NodeParagraph, NodeSystemMessage},
+		para:  "Text:",
+		line:  2,
+	},
+}
+
+func TestParseMissingLiteralBlock(t *testing.T) {
+	for _, tt := range missingLiteralBlockTests {
+		tr, errors := Parse(tt.name, tt.input)
+		if len(tr.Nodes) != len(tt.types) {
+			t.Errorf("Test: %q\n\t    Got: len(Nodes) = %d, "+
+				"Expect: %d\n\n", tt.name, len(tr.Nodes),
+				len(tt.types))
+			continue
+		}
+		for num, n := range tr.Nodes {
+			if n.NodeType() != tt.types[num] {
+				t.Errorf("Test: %q\n\t    Got: Nodes[%d] = %s, "+
+					"Expect: %s\n\n", tt.name, num,
+					n.NodeType(), tt.types[num])
+			}
+		}
+		if p, ok := tr.Nodes[0].(*ParagraphNode); ok && p.Text != tt.para {
+			t.Errorf("Test: %q\n\t    Got: Text = %q, Expect: %q\n\n",
+				tt.name, p.Text, tt.para)
+		}
+		if len(errors) != 1 {
+			t.Errorf("Test: %q\n\t    Got: len(errors) = %d, "+
+				"Expect: 1\n\n", tt.name, len(errors))
+			continue
+		}
+		m := errors[0].(*SystemMessageNode)
+		if m.MessageType != warningLiteralBlockExpected ||
+			m.Line != tt.line {
+			t.Errorf("Test: %q\n\t    Got: %s on line %d, "+
+				"Expect: %s on line %d\n\n", tt.name, m.MessageType,
+				m.Line, warningLiteralBlockExpected, tt.line)
+		}
+		if errs := tr.Validate(); len(errs) != 0 {
+			t.Errorf("Test: %q\n\t    Got: Validate() = %v, Expect: no "+
+				"errors\n\n", tt.name, errs)
+		}
+	}
+}
+
+var quotedLiteralBlockTests = []struct {
+	name  string
+	input string
+	types []NodeType // Expected types of the top level nodes
+	text  string     // Expected text of the literal block
+	line  Line       // Expected line of the system message, if any
+}{
+	{
+		name:  "Quoted with >",
+		input: "Text::\n\n> one\n>   two\n\nAfter.",
+		types: []NodeType{NodeParagraph, NodeLiteralBlock,
+			NodeParagraph},
+		text: "> one\n>   two",
+	},
+	{
+		name:  "Quoted with - at the end of the input",
+		input: "Text::\n\n- one\n- two",
+		types: []NodeType{NodeParagraph, NodeLiteralBlock},
+		text:  "- one\n- two",
+	},
+	{
+		name:  "Inconsistent quote character",
+		input: "Text::\n\n> one\n< two",
+		types: []NodeType{NodeParagraph, NodeLiteralBlock,
+			NodeSystemMessage, NodeParagraph},
+		text: "> one",
+		line: 4,
+	},
+}
+
+func TestParseQuotedLiteralBlock(t *testing.T) {
+	for _, tt := range quotedLiteralBlockTests {
+		tr, errors := Parse(tt.name, tt.input)
+		if len(tr.Nodes) != len(tt.types) {
+			t.Errorf("Test: %q\n\t    Got: len(Nodes) = %d, "+
+				"Expect: %d\n\n", tt.name, len(tr.Nodes),
+				len(tt.types))
+			continue
+		}
+		for num, n := range tr.Nodes {
+			if n.NodeType() != tt.types[num] {
+				t.Errorf("Test: %q\n\t    Got: Nodes[%d] = %s, "+
+					"Expect: %s\n\n", tt.name, num,
+					n.NodeType(), tt.types[num])
+			}
+		}
+		if p, ok := tr.Nodes[0].(*ParagraphNode); !ok || p.Text != "Text:" {
+			t.Errorf("Test: %q\n\t    Got: Nodes[0] = %#v, Expect: "+
+				"paragraph \"Text:\"\n\n", tt.name, tr.Nodes[0])
+		}
+		lb, ok := tr.Nodes[1].(*LiteralBlockNode)
+		if !ok || lb.Text != tt.text || lb.Line != 3 {
+			t.Errorf("Test: %q\n\t    Got: Nodes[1] = %#v, Expect: "+
+				"literal block %q on line 3\n\n", tt.name,
+				tr.Nodes[1], tt.text)
+		}
+		if tt.line == 0 {
+			if len(errors) != 0 {
+				t.Errorf("Test: %q\n\t    Got: len(errors) = %d, "+
+					"Expect: 0\n\n", tt.name, len(errors))
+			}
+		} else if len(errors) != 1 {
+			t.Errorf("Test: %q\n\t    Got: len(errors) = %d, "+
+				"Expect: 1\n\n", tt.name, len(errors))
+		} else if m := errors[0].(*SystemMessageNode); m.MessageType !=
+			errorInconsistentLiteralQuoting || m.Line != tt.line {
+			t.Errorf("Test: %q\n\t    Got: %s on line %d, "+
+				"Expect: %s on line %d\n\n", tt.name, m.MessageType,
+				m.Line, errorInconsistentLiteralQuoting, tt.line)
+		}
+		if errs := tr.Validate(); len(errs) != 0 {
+			t.Errorf("Test: %q\n\t    Got: Validate() = %v, Expect: no "+
+				"errors\n\n", tt.name, errs)
+		}
+	}
+}
+
+func TestParseQuotedLiteralBlockLineLength(t *testing.T) {
+	input := "Text::\n\n> " + strings.Repeat("x", 20)
+	_, errors := ParseWithOptions("quoted-line-length", input,
+		&ParseOptions{MaxLineLength: 10})
+	if len(errors) != 0 {
+		t.Errorf("Got: len(errors) = %d, Expect: 0", len(errors))
+	}
+}
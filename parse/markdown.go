@@ -0,0 +1,220 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RenderMarkdown writes the parsed document as Markdown to w.
+//
+// Sections are written as ATX headings with the level of the section, block
+// quotes are prefixed with "> ", and literal blocks are written as fenced code
+// blocks. Emphasis and strong emphasis use the same markup in Markdown and are
+// written unchanged, inline literals are converted to code spans. Elements
+// without a Markdown equivalent are written as HTML comments, and a
+// warningNoMarkdownEquivalent system message is added to Tree.Messages for
+// each of them.
+func (t *Tree) RenderMarkdown(w io.Writer) error {
+	text := t.markdownBlocks(t.Nodes)
+	if text == "" {
+		return nil
+	}
+	_, err := io.WriteString(w, text+"\n")
+	return err
+}
+
+// markdownBlocks returns the Markdown of the nodes in nl separated by blank
+// lines.
+func (t *Tree) markdownBlocks(nl NodeList) string {
+	var blocks []string
+	for _, n := range nl {
+		if n == nil {
+			continue
+		}
+		if b := t.markdownNode(n); b != "" {
+			blocks = append(blocks, b)
+		}
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// markdownNode returns the Markdown of the node n and its children.
+func (t *Tree) markdownNode(n Node) string {
+	switch n := n.(type) {
+	case *SectionNode:
+		h := strings.Repeat("#", n.Level) + " " + markdownInline(n.Title.Text)
+		if body := t.markdownBlocks(n.NodeList); body != "" {
+			return h + "\n\n" + body
+		}
+		return h
+	case *ParagraphNode:
+		return markdownInline(n.Text)
+	case *RubricNode:
+		// Markdown has no informal headings.
+		return "**" + markdownInline(n.Text) + "**"
+	case *BlockQuoteNode:
+		return prefixLines(t.markdownBlocks(n.NodeList), "> ", ">")
+	case *LiteralBlockNode:
+		return markdownCodeBlock(n.Text)
+	case *ParsedLiteralNode:
+		// Markup is not recognized in fenced code blocks.
+		return markdownCodeBlock(plainInline(n.Text))
+	case *BulletListNode:
+		var items []string
+		for _, item := range n.NodeList {
+			items = append(items, t.markdownListItem("- ", item))
+		}
+		return strings.Join(items, "\n")
+	case *EnumListNode:
+		affix := "."
+		if n.Affix != enumAffixPeriod {
+			affix = ")"
+		}
+		var items []string
+		for num, item := range n.NodeList {
+			marker := fmt.Sprintf("%d%s ", num+1, affix)
+			items = append(items, t.markdownListItem(marker, item))
+		}
+		return strings.Join(items, "\n")
+	case *TransitionNode:
+		return "---"
+	case *CommentNode:
+		return markdownComment(n.Text)
+	case *ImageNode:
+		img := "![](" + n.URI + ")"
+		if n.Target != "" && !strings.HasSuffix(n.Target, "_") {
+			img = "[" + img + "](" + n.Target + ")"
+		}
+		return img
+	case *SystemMessageNode, *BlankLineNode:
+		return ""
+	}
+	t.noMarkdownEquivalent(n)
+	return markdownComment(n.NodeType().String())
+}
+
+// markdownListItem returns the Markdown of the list item n with the list
+// marker. The lines following the first line are indented to the width of the
+// marker.
+func (t *Tree) markdownListItem(marker string, n Node) string {
+	var text string
+	if item, ok := n.(*BulletListItemNode); ok {
+		text = t.markdownBlocks(item.NodeList)
+	} else {
+		text = t.markdownNode(n)
+	}
+	indent := strings.Repeat(" ", utf8.RuneCountInString(marker))
+	return marker + strings.TrimPrefix(prefixLines(text, indent, ""), indent)
+}
+
+// noMarkdownEquivalent adds a warningNoMarkdownEquivalent system message for
+// the node n to Tree.Messages.
+func (t *Tree) noMarkdownEquivalent(n Node) {
+	var line Line
+	if l, ok := n.(interface {
+		LineNumber() Line
+	}); ok {
+		line = l.LineNumber()
+	}
+	t.positionMessage(warningNoMarkdownEquivalent, line, 0)
+}
+
+// prefixLines returns text with prefix added to each line. Blank lines are
+// prefixed with blankPrefix.
+func prefixLines(text, prefix, blankPrefix string) string {
+	lines := strings.Split(text, "\n")
+	for num, line := range lines {
+		if line == "" {
+			lines[num] = blankPrefix
+		} else {
+			lines[num] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// markdownComment returns text as an HTML comment. "--" is not allowed in
+// HTML comments and is replaced by "- -".
+func markdownComment(text string) string {
+	for strings.Contains(text, "--") {
+		text = strings.Replace(text, "--", "- -", -1)
+	}
+	return "<!-- " + text + " -->"
+}
+
+// markdownInline converts the inline markup of text to Markdown. Inline
+// literals are converted to code spans, the remaining text is returned as it
+// is.
+func markdownInline(text string) string {
+	var out []string
+	prev := 0
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' {
+			// An escaped whitespace character is removed with the
+			// backslash. Escaped line breaks are hard line breaks in
+			// Markdown.
+			if i+1 < len(text) && unicode.IsSpace(rune(text[i+1])) {
+				out = append(out, text[prev:i])
+				prev = i + 2
+			}
+			i++
+			continue
+		}
+		if !strings.HasPrefix(text[i:], "``") {
+			continue
+		}
+		before := eof
+		if i > 0 {
+			before, _ = utf8.DecodeLastRuneInString(text[:i])
+		}
+		if !isInlineStartContext(before) {
+			continue
+		}
+		end := findInlineEnd(text, i+2, "``")
+		if end == -1 {
+			break
+		}
+		out = append(out, text[prev:i], markdownCode(text[i+2:end]))
+		i = end + 1
+		prev = end + 2
+	}
+	return strings.Join(append(out, text[prev:]), "")
+}
+
+// markdownCodeBlock returns text as a fenced code block. The fence is longer
+// than any run of backticks in text.
+func markdownCodeBlock(text string) string {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + "\n" + text + "\n" + fence
+}
+
+// markdownCode returns text as a Markdown code span. The code span is
+// delimited by one more backtick than the longest run of backticks in text.
+func markdownCode(text string) string {
+	run, longest := 0, 0
+	for _, r := range text {
+		if r == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", longest+1)
+	if longest > 0 {
+		return fence + " " + text + " " + fence
+	}
+	return fence + text + fence
+}
@@ -0,0 +1,97 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"bytes"
+	"testing"
+)
+
+var renderMarkdownTests = []struct {
+	name   string
+	input  string
+	expect string
+}{
+	{
+		name: "Sections and paragraphs",
+		input: "Title\n=====\n\nPara *em* **strong** ``code``.\n\n" +
+			"Sub\n---\n\nText.",
+		expect: "# Title\n\nPara *em* **strong** `code`.\n\n" +
+			"## Sub\n\nText.\n",
+	},
+	{
+		name:   "Bullet list",
+		input:  "- one\n- two",
+		expect: "- one\n- two\n",
+	},
+	{
+		name:   "Block quote",
+		input:  "Para.\n\n    Quote.",
+		expect: "Para.\n\n> Quote.\n",
+	},
+	{
+		name:   "Escaped line break",
+		input:  "Some word\\\nnext word.",
+		expect: "Some wordnext word.\n",
+	},
+	{
+		name:   "Comment",
+		input:  ".. A -- comment",
+		expect: "<!-- A - - comment -->\n",
+	},
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	for _, tt := range renderMarkdownTests {
+		tr, _ := Parse(tt.name, tt.input)
+		var buf bytes.Buffer
+		if err := tr.RenderMarkdown(&buf); err != nil {
+			t.Errorf("Test: %q\n\t    Got: err = %s\n\n", tt.name, err)
+			continue
+		}
+		if buf.String() != tt.expect {
+			t.Errorf("Test: %q\n\t    Got: %q\n\t Expect: %q\n\n",
+				tt.name, buf.String(), tt.expect)
+		}
+	}
+}
+
+func TestRenderMarkdownNodes(t *testing.T) {
+	tr := New("nodes", "")
+	tr.Nodes = NodeList{
+		&EnumListNode{Type: NodeEnumList, NodeList: NodeList{
+			&ParagraphNode{Type: NodeParagraph, Text: "first"},
+			&ParagraphNode{Type: NodeParagraph, Text: "second"},
+		}},
+		&LiteralBlockNode{Type: NodeLiteralBlock, Text: "a ``` b"},
+		&BulletListNode{Type: NodeBulletList, NodeList: NodeList{
+			&BulletListItemNode{Type: NodeBulletListItem, NodeList: NodeList{
+				&ParagraphNode{Type: NodeParagraph, Text: "item"},
+				&BlockQuoteNode{Type: NodeBlockQuote, NodeList: NodeList{
+					&ParagraphNode{Type: NodeParagraph, Text: "quote"},
+				}},
+			}},
+		}},
+		&DefinitionListNode{Type: NodeDefinitionList, Line: 9},
+	}
+	expect := "1. first\n2. second\n\n````\na ``` b\n````\n\n" +
+		"- item\n\n  > quote\n\n<!-- NodeDefinitionList -->\n"
+	var buf bytes.Buffer
+	if err := tr.RenderMarkdown(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expect {
+		t.Errorf("Got: %q\n\t Expect: %q", buf.String(), expect)
+	}
+	if len(tr.Diagnostics) != 1 {
+		t.Fatalf("Got: len(Diagnostics) = %d, Expect: 1",
+			len(tr.Diagnostics))
+	}
+	if d := tr.Diagnostics[0]; d.MessageType != warningNoMarkdownEquivalent ||
+		d.Line != 9 {
+		t.Errorf("Got: Diagnostic = %s at line %d, Expect: %s at line 9",
+			d.MessageType, d.Line, warningNoMarkdownEquivalent)
+	}
+}
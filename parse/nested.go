@@ -0,0 +1,123 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"reflect"
+	"sort"
+)
+
+// parseNested parses text, the content of a directive or a list item beginning
+// at line, and returns the parsed nodes. indent is the width of the
+// indentation removed from the lines of text. The nodes are numbered after the
+// nodes of t, and their lines and columns are relative to the input of t. The
+// messages of the nested parser are added to t.
+func (t *Tree) parseNested(text string, line Line, indent int) NodeList {
+	if text == "" {
+		return nil
+	}
+	nt := t.nestedTree(text)
+	nt.Parse(text, nt)
+	return t.adoptNested(nt, line, indent)
+}
+
+// nestedTree returns a new Tree for parsing text with the options of t.
+func (t *Tree) nestedTree(text string) *Tree {
+	nt := New(t.Name, text)
+	opts := *t.Options
+	opts.PromoteTitle = false
+	opts.DocInfo = false
+	// The messages of nt are limited when they are added to t.
+	opts.MaxDiagnostics = 0
+	nt.Options = &opts
+	return nt
+}
+
+// adoptNested renumbers the nodes of the parsed nested Tree nt after the nodes
+// of t, makes their lines and columns relative to the input of t, and adds the
+// messages of nt to t, limited by the Options.MaxDiagnostics of t. The Names of
+// nt that are not already Names of t are added to the Names of t. The nodes of
+// nt are returned.
+func (t *Tree) adoptNested(nt *Tree, line Line, indent int) NodeList {
+	seen := make(map[Node]bool)
+	renumber := func(n Node) bool {
+		if seen[n] {
+			return false
+		}
+		seen[n] = true
+		v := reflect.ValueOf(n).Elem()
+		if f := v.FieldByName("ID"); f.IsValid() {
+			t.id++
+			f.SetInt(int64(t.id))
+		}
+		if f := v.FieldByName("Line"); f.IsValid() && f.Int() != 0 {
+			f.SetInt(f.Int() + int64(line) - 1)
+		}
+		if f := v.FieldByName("StartPosition"); f.IsValid() &&
+			f.Int() != 0 {
+			f.SetInt(f.Int() + int64(indent))
+		}
+		return true
+	}
+	Walk(nt.Nodes, renumber)
+	Walk(nt.Messages, renumber)
+
+	for _, d := range nt.Diagnostics {
+		d.Line = d.Node.Line
+		if d.StartPosition != 0 {
+			d.StartPosition += StartPosition(indent)
+		}
+		s := t.limitMessage(d.Node)
+		if s == nil {
+			continue
+		}
+		if s != d.Node {
+			d = &Diagnostic{
+				Source:        d.Source,
+				Line:          s.Line,
+				StartPosition: d.StartPosition,
+				MessageType:   s.MessageType,
+				Severity:      s.Severity,
+				Node:          s,
+			}
+		}
+		t.Messages = append(t.Messages, s)
+		t.Diagnostics = append(t.Diagnostics, d)
+	}
+	// The names of nt are registered in document order.
+	var names []string
+	for name, n := range nt.Names {
+		if t.Names[name] != n {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return nt.Names[names[i]].IDNumber() < nt.Names[names[j]].IDNumber()
+	})
+	for _, name := range names {
+		t.addName(name, nt.Names[name])
+	}
+	if nt.Halted {
+		t.Halted = true
+	}
+	return nt.Nodes
+}
+
+// numberNodes sets the IDs of the nodes in nl and their children that do not
+// have an ID. The nodes are numbered after the nodes of t.
+func (t *Tree) numberNodes(nl NodeList) {
+	Walk(nl, func(n Node) bool {
+		v := reflect.ValueOf(n)
+		if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+			return true
+		}
+		if f := v.Elem().FieldByName("ID"); f.IsValid() && f.Int() == 0 &&
+			f.CanSet() {
+			t.id++
+			f.SetInt(int64(t.id))
+		}
+		return true
+	})
+}
@@ -4,6 +4,12 @@
 
 package parse
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 // NodeType identifies the type of a parse tree node.
 type NodeType int
 
@@ -46,6 +52,123 @@ const (
 	NodeDefinitionListItem
 	NodeDefinitionTerm
 	NodeDefinition
+
+	// NodeBlankLine is a blank line of input. Blank lines are only added to
+	// the parse tree if ParseOptions.KeepBlankLines is set.
+	NodeBlankLine
+
+	// NodeImage is an image element created by the image directive.
+	NodeImage
+
+	// NodeFootnote is a footnote element.
+	NodeFootnote
+
+	// NodeText is inline text without markup.
+	NodeText
+
+	// NodeEmphasis is inline emphasis, "*text*".
+	NodeEmphasis
+
+	// NodeStrong is inline strong emphasis, "**text**".
+	NodeStrong
+
+	// NodeInlineLiteral is an inline literal, "``text``".
+	NodeInlineLiteral
+
+	// NodeProblematic is inline markup that could not be recognized.
+	NodeProblematic
+
+	// NodeTopic is a topic element created by the topic directive.
+	NodeTopic
+
+	// NodeSidebar is a sidebar element created by the sidebar directive.
+	NodeSidebar
+
+	// NodeRubric is an informal heading created by the rubric directive.
+	NodeRubric
+
+	// NodeParsedLiteral is a literal block with inline markup created by
+	// the parsed-literal directive.
+	NodeParsedLiteral
+
+	// NodeCompound is a compound paragraph created by the compound
+	// directive.
+	NodeCompound
+
+	// NodeContainer is a generic container of body elements created by the
+	// container directive.
+	NodeContainer
+
+	// NodeFootnoteRef is an inline footnote reference, "[1]_".
+	NodeFootnoteRef
+
+	// NodeCitationRef is an inline citation reference, "[CIT2002]_".
+	NodeCitationRef
+
+	// NodeCitation is a citation element.
+	NodeCitation
+
+	// NodeTarget is a hyperlink target, ".. _name: uri".
+	NodeTarget
+
+	// NodeDirective is a directive that could not be parsed, for example
+	// a directive of an unknown type.
+	NodeDirective
+
+	// NodeClassifier is a classifier of a definition list term.
+	NodeClassifier
+
+	// NodeDocInfo is the bibliographic data of a document, promoted from a
+	// field list at the beginning of the document.
+	NodeDocInfo
+
+	// NodeLine is a line of a line block.
+	NodeLine
+
+	// NodeTableRow is a row of a table, and NodeTableEntry is a cell of a
+	// row.
+	NodeTableRow
+	NodeTableEntry
+
+	// The node types of the body elements and the inline markup that are
+	// not parsed yet. They are declared so the parser and the fixtures can
+	// refer to them while the constructs are implemented.
+
+	// NodeEnumListItem is an item of an enumerated list.
+	NodeEnumListItem
+
+	// NodeFieldList is a field list, and NodeField is a field of the list.
+	NodeFieldList
+	NodeField
+
+	// NodeOptionList is an option list.
+	NodeOptionList
+
+	// NodeTable is a grid or simple table.
+	NodeTable
+
+	// NodeLineBlock is a line block, "| text".
+	NodeLineBlock
+
+	// NodeDoctestBlock is a doctest block, ">>> code".
+	NodeDoctestBlock
+
+	// NodeMathBlock is a block of math created by the math directive.
+	NodeMathBlock
+
+	// NodeSubstitutionDefinition is a substitution definition,
+	// ".. |name| directive::".
+	NodeSubstitutionDefinition
+
+	// NodeReference is an inline hyperlink reference, "name_".
+	NodeReference
+
+	// NodeInterpretedText is inline interpreted text, "`text`".
+	NodeInterpretedText
+
+	// NodeSubstitutionReference is an inline substitution reference,
+	// "|name|".
+	NodeSubstitutionReference
 )
 
 var nodeTypes = [...]string{
@@ -65,6 +188,42 @@ var nodeTypes = [...]string{
 	"NodeDefinitionListItem",
 	"NodeDefinitionTerm",
 	"NodeDefinition",
+	"NodeBlankLine",
+	"NodeImage",
+	"NodeFootnote",
+	"NodeText",
+	"NodeEmphasis",
+	"NodeStrong",
+	"NodeInlineLiteral",
+	"NodeProblematic",
+	"NodeTopic",
+	"NodeSidebar",
+	"NodeRubric",
+	"NodeParsedLiteral",
+	"NodeCompound",
+	"NodeContainer",
+	"NodeFootnoteRef",
+	"NodeCitationRef",
+	"NodeCitation",
+	"NodeTarget",
+	"NodeDirective",
+	"NodeClassifier",
+	"NodeDocInfo",
+	"NodeLine",
+	"NodeTableRow",
+	"NodeTableEntry",
+	"NodeEnumListItem",
+	"NodeFieldList",
+	"NodeField",
+	"NodeOptionList",
+	"NodeTable",
+	"NodeLineBlock",
+	"NodeDoctestBlock",
+	"NodeMathBlock",
+	"NodeSubstitutionDefinition",
+	"NodeReference",
+	"NodeInterpretedText",
+	"NodeSubstitutionReference",
 }
 
 // Type returns the type of a node element.
@@ -76,10 +235,55 @@ func (n NodeType) String() string {
 	return nodeTypes[n]
 }
 
+// MarshalText implements encoding.TextMarshaler and returns the name of the
+// NodeType.
+func (n NodeType) MarshalText() ([]byte, error) {
+	return []byte(n.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler and converts the name of a
+// NodeType back to the NodeType. An error is returned if the name is unknown.
+func (n *NodeType) UnmarshalText(text []byte) error {
+	for num, name := range nodeTypes {
+		if name == string(text) {
+			*n = NodeType(num)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown node type %q", text)
+}
+
 // Node is the interface used to implement parser nodes.
 type Node interface {
 	IDNumber() ID
 	NodeType() NodeType
+	Attrs() *Attributes
+	Accept(v Visitor)
+}
+
+// Attributes contains the common attributes of the elements of a document.
+// Every node embeds Attributes, so that targets, class directives, and roles
+// can attach them to any element.
+type Attributes struct {
+	// Ids contains the unique identifiers of the element.
+	Ids []string `json:"ids"`
+
+	// Names contains the reference names of the element.
+	Names []string `json:"names"`
+
+	// Classes contains the class names of the element, for example from a
+	// class directive.
+	Classes []string `json:"classes"`
+}
+
+// Attrs returns a pointer to the Attributes of a node.
+func (a *Attributes) Attrs() *Attributes {
+	return a
+}
+
+// IsEmpty returns true if no attributes are set.
+func (a Attributes) IsEmpty() bool {
+	return len(a.Ids) == 0 && len(a.Names) == 0 && len(a.Classes) == 0
 }
 
 // NodeList is a list of parser nodes that implement Node.
@@ -131,6 +335,32 @@ func (a EnumAffixType) String() string {
 	return enumAffixesTypes[a]
 }
 
+// FootnoteAutoType identifies how the number of a footnote reference is
+// assigned.
+type FootnoteAutoType int
+
+const (
+	// footnoteManual is a manually numbered reference, "[1]_".
+	footnoteManual FootnoteAutoType = iota
+
+	// footnoteAutoNumber is an auto-numbered reference, "[#]_" or
+	// "[#name]_".
+	footnoteAutoNumber
+
+	// footnoteAutoSymbol is an auto-symbol reference, "[*]_".
+	footnoteAutoSymbol
+)
+
+var footnoteAutoTypes = [...]string{
+	"footnoteManual",
+	"footnoteAutoNumber",
+	"footnoteAutoSymbol",
+}
+
+func (a FootnoteAutoType) String() string {
+	return footnoteAutoTypes[a]
+}
+
 // SectionNode is a a single section node. It contains overline, title, and
 // underline nodes. NodeList contains nodes that are children of the section.
 type SectionNode struct {
@@ -148,8 +378,11 @@ type SectionNode struct {
 	OverLine  *AdornmentNode `json:"overLine"`
 	UnderLine *AdornmentNode `json:"underLine"`
 
+	// Attributes contains the ids, names, and classes of the section.
+	Attributes `json:"attributes"`
+
 	// NodeList contains
-	NodeList `json:"nodeList"`
+	NodeList NodeList `json:"nodeList"`
 }
 
 // NodeType returns the Node type of the SectionNode.
@@ -157,6 +390,28 @@ func (s *SectionNode) NodeType() NodeType {
 	return s.Type
 }
 
+// OverlineText returns the overline of the section as it is written in the
+// input, the adornment rune repeated to the length of the overline. Trailing
+// whitespace is not part of an adornment. An empty string is returned if the
+// section has no overline.
+func (s *SectionNode) OverlineText() string {
+	return adornmentText(s.OverLine)
+}
+
+// UnderlineText returns the underline of the section as it is written in the
+// input, like OverlineText.
+func (s *SectionNode) UnderlineText() string {
+	return adornmentText(s.UnderLine)
+}
+
+// adornmentText returns the rune of the adornment a repeated to its length.
+func adornmentText(a *AdornmentNode) string {
+	if a == nil || a.Length <= 0 {
+		return ""
+	}
+	return strings.Repeat(string(a.Rune), a.Length)
+}
+
 func newSection(title *item, overSec *item, underSec *item,
 	indent *item, id *int) *SectionNode {
 
@@ -217,6 +472,7 @@ type TitleNode struct {
 	Length        int      `json:"length"`
 	Line          `json:"line"`
 	StartPosition `json:"startPosition"`
+	Attributes    `json:"attributes"`
 }
 
 // NodeType returns the Node type of the TitleNode.
@@ -232,6 +488,7 @@ type AdornmentNode struct {
 	Length        int      `json:"length"`
 	Line          `json:"line"`
 	StartPosition `json:"startPosition"`
+	Attributes    `json:"attributes"`
 }
 
 // NodeType returns the Node type of the AdornmentNode.
@@ -247,6 +504,11 @@ type ParagraphNode struct {
 	Length        int      `json:"length"`
 	Line          `json:"line"`
 	StartPosition `json:"startPosition"`
+
+	// NodeList contains the inline nodes of Text. It is only set if
+	// ParseOptions.ParseInline is set.
+	NodeList   NodeList `json:"nodeList"`
+	Attributes `json:"attributes"`
 }
 
 func newParagraph(i *item, id *int) *ParagraphNode {
@@ -275,7 +537,8 @@ type BlockQuoteNode struct {
 	Line          `json:"line"`
 	StartPosition `json:"startPosition"`
 	// NodeList contains Nodes parsed as children of the BlockQuoteNode.
-	NodeList `json:"nodeList"`
+	NodeList   NodeList `json:"nodeList"`
+	Attributes `json:"attributes"`
 }
 
 func newBlockQuote(i *item, indentLevel int, id *int) *BlockQuoteNode {
@@ -313,7 +576,8 @@ type SystemMessageNode struct {
 	// containing the first list item as a NodeParagraph which contains the
 	// message, and a NodeLiteralBlock which contains the input data
 	// causing the systemMessage to be generated.
-	NodeList `json:"nodeList"`
+	NodeList   NodeList `json:"nodeList"`
+	Attributes `json:"attributes"`
 }
 
 func newSystemMessage(i *item, m parserMessage, id *int) *SystemMessageNode {
@@ -340,6 +604,7 @@ type LiteralBlockNode struct {
 	Length        int      `json:"length"`
 	StartPosition `json:"startPosition"`
 	Line          `json:"line"`
+	Attributes    `json:"attributes"`
 }
 
 func newLiteralBlock(i *item, id *int) *LiteralBlockNode {
@@ -368,6 +633,7 @@ type TransitionNode struct {
 	Length        int      `json:"length"`
 	StartPosition `json:"startPosition"`
 	Line          `json:"line"`
+	Attributes    `json:"attributes"`
 }
 
 func newTransition(i *item, id *int) *TransitionNode {
@@ -387,6 +653,30 @@ func (t TransitionNode) NodeType() NodeType {
 	return t.Type
 }
 
+// BlankLineNode is a blank line of input. BlankLineNodes do not affect the
+// structure of the document, they are kept for tools that need to reproduce
+// the input from the parse tree.
+type BlankLineNode struct {
+	ID         `json:"id"`
+	Type       NodeType `json:"type"`
+	Line       `json:"line"`
+	Attributes `json:"attributes"`
+}
+
+func newBlankLine(i *item, id *int) *BlankLineNode {
+	*id++
+	return &BlankLineNode{
+		ID:   ID(*id),
+		Type: NodeBlankLine,
+		Line: i.Line,
+	}
+}
+
+// NodeType returns the Node type of the BlankLineNode.
+func (b BlankLineNode) NodeType() NodeType {
+	return b.Type
+}
+
 // CommentNode is a parsed comment element. Comment elements do not appear as
 // visible elements in document transformations.
 type CommentNode struct {
@@ -396,6 +686,7 @@ type CommentNode struct {
 	Length        int      `json:"length"`
 	StartPosition `json:"startPosition"`
 	Line          `json:"line"`
+	Attributes    `json:"attributes"`
 }
 
 func newComment(i *item, id *int) *CommentNode {
@@ -416,11 +707,12 @@ func (t CommentNode) NodeType() NodeType {
 }
 
 type BulletListNode struct {
-	ID       `json:"id"`
-	Type     NodeType `json:"type"`
-	Bullet   string   `json:"bullet"`
-	Line     `json:"line"`
-	NodeList `json:"nodeList"`
+	ID         `json:"id"`
+	Type       NodeType `json:"type"`
+	Bullet     string   `json:"bullet"`
+	Line       `json:"line"`
+	NodeList   NodeList `json:"nodeList"`
+	Attributes `json:"attributes"`
 }
 
 // newEnumListNode initializes a new EnumListNode.
@@ -439,10 +731,11 @@ func (b BulletListNode) NodeType() NodeType {
 }
 
 type BulletListItemNode struct {
-	ID       `json:"id"`
-	Type     NodeType `json:"type"`
-	Line     `json:"line"`
-	NodeList `json:"nodeList"`
+	ID         `json:"id"`
+	Type       NodeType `json:"type"`
+	Line       `json:"line"`
+	NodeList   NodeList `json:"nodeList"`
+	Attributes `json:"attributes"`
 }
 
 // newEnumListNode initializes a new EnumListNode.
@@ -464,10 +757,16 @@ type EnumListNode struct {
 	Type     NodeType      `json:"type"`
 	EnumType EnumListType  `json:"enumType"`
 	Affix    EnumAffixType `json:"affix"`
-	NodeList `json:"nodeList"`
+
+	// Start is the ordinal of the first enumerator of the list.
+	Start int `json:"start"`
+
+	NodeList   NodeList `json:"nodeList"`
+	Attributes `json:"attributes"`
 }
 
-// newEnumListNode initializes a new EnumListNode.
+// newEnumListNode initializes a new EnumListNode. The start of the list is the
+// ordinal of the enumerator enumList.
 func newEnumListNode(enumList *item, affix *item, id *int) *EnumListNode {
 	*id++
 	var enType EnumListType
@@ -480,17 +779,18 @@ func newEnumListNode(enumList *item, affix *item, id *int) *EnumListNode {
 	switch affix.Text {
 	case ".":
 		afType = enumAffixPeriod
-		// case "(":
-		// afType = enumAffixParenthesisSurround
-		// case ")":
-		// afType = enumAffixParenthesisRight
+	case ")":
+		afType = enumAffixParenthesisRight
 	}
 
+	start, _ := strconv.Atoi(enumList.Text)
+
 	return &EnumListNode{
 		ID:       ID(*id),
 		Type:     NodeEnumList,
 		EnumType: enType,
 		Affix:    afType,
+		Start:    start,
 	}
 }
 
@@ -500,10 +800,11 @@ func (e EnumListNode) NodeType() NodeType {
 }
 
 type DefinitionListNode struct {
-	ID       `json:"id"`
-	Type     NodeType `json:"type"`
-	Line     `json:"line"`
-	NodeList `json:"nodeList"`
+	ID         `json:"id"`
+	Type       NodeType `json:"type"`
+	Line       `json:"line"`
+	NodeList   NodeList `json:"nodeList"`
+	Attributes `json:"attributes"`
 }
 
 func newDefinitionList(i *item, id *int) *DefinitionListNode {
@@ -520,14 +821,22 @@ func (d DefinitionListNode) NodeType() NodeType {
 }
 
 type DefinitionListItemNode struct {
-	ID         `json:"id"`
-	Type       NodeType `json:"type"`
-	Line       `json:"line"`
-	Term       *DefinitionTermNode `json:"term"`
-	Definition *DefinitionNode     `json:"definition"`
+	ID          `json:"id"`
+	Type        NodeType `json:"type"`
+	Line        `json:"line"`
+	Term        *DefinitionTermNode `json:"term"`
+	Classifiers NodeList            `json:"classifiers"`
+	Definition  *DefinitionNode     `json:"definition"`
+	Attributes  `json:"attributes"`
 }
 
-func newDefinitionListItem(defTerm *item, def *item, id *int) *DefinitionListItemNode {
+// newDefinitionListItem returns a DefinitionListItemNode with the term defTerm,
+// the classifiers of the term, and an empty definition beginning on the line of
+// def. The nodes are numbered in document order, the classifiers after the term
+// and before the definition.
+func newDefinitionListItem(defTerm *item, classifiers []*item, def *item,
+	id *int) *DefinitionListItemNode {
+
 	*id++
 	n := &DefinitionListItemNode{
 		ID:   ID(*id),
@@ -543,6 +852,9 @@ func newDefinitionListItem(defTerm *item, def *item, id *int) *DefinitionListIte
 		StartPosition: defTerm.StartPosition,
 		Line:          defTerm.Line,
 	}
+	for _, c := range classifiers {
+		n.Classifiers.append(newClassifier(c, id))
+	}
 	*id++
 	nd := &DefinitionNode{
 		ID:   ID(*id),
@@ -565,6 +877,7 @@ type DefinitionTermNode struct {
 	Length        int      `json:"length"`
 	StartPosition `json:"startPosition"`
 	Line          `json:"line"`
+	Attributes    `json:"attributes"`
 }
 
 func (d DefinitionTermNode) NodeType() NodeType {
@@ -572,12 +885,801 @@ func (d DefinitionTermNode) NodeType() NodeType {
 }
 
 type DefinitionNode struct {
-	ID       `json:"id"`
-	Type     NodeType `json:"type"`
-	Line     `json:"line"`
-	NodeList  `json:"nodeList"`
+	ID         `json:"id"`
+	Type       NodeType `json:"type"`
+	Line       `json:"line"`
+	NodeList   NodeList `json:"nodeList"`
+	Attributes `json:"attributes"`
 }
 
 func (d DefinitionNode) NodeType() NodeType {
 	return d.Type
 }
+
+// ClassifierNode is a classifier of a definition list term. The classifiers
+// follow the term on its line, separated by " : ".
+type ClassifierNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Text          string   `json:"text"`
+	Length        int      `json:"length"`
+	StartPosition `json:"startPosition"`
+	Line          `json:"line"`
+	Attributes    `json:"attributes"`
+}
+
+func newClassifier(i *item, id *int) *ClassifierNode {
+	*id++
+	return &ClassifierNode{
+		ID:            ID(*id),
+		Type:          NodeClassifier,
+		Text:          i.Text,
+		Length:        i.Length,
+		StartPosition: i.StartPosition,
+		Line:          i.Line,
+	}
+}
+
+func (c ClassifierNode) NodeType() NodeType {
+	return c.Type
+}
+
+// ImageNode is an image element created by the image directive.
+type ImageNode struct {
+	ID   `json:"id"`
+	Type NodeType `json:"type"`
+
+	// URI is the location of the image file.
+	URI string `json:"uri"`
+
+	// Target is the value of the :target: option. The image is a reference
+	// to Target, which is a URI, or a reference name if it ends with an
+	// underscore. Target is empty if the option is not set.
+	Target string `json:"target"`
+
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	Attributes    `json:"attributes"`
+}
+
+func newImage(i *item, uri, target string, id *int) *ImageNode {
+	*id++
+	return &ImageNode{
+		ID:            ID(*id),
+		Type:          NodeImage,
+		URI:           uri,
+		Target:        target,
+		Line:          i.Line,
+		StartPosition: i.StartPosition,
+	}
+}
+
+// NodeType returns the Node type of the ImageNode.
+func (i ImageNode) NodeType() NodeType {
+	return i.Type
+}
+
+// FootnoteNode is a footnote element. The paragraph of the footnote body is
+// contained in NodeList.
+type FootnoteNode struct {
+	ID   `json:"id"`
+	Type NodeType `json:"type"`
+
+	// Label is the label of the footnote without the brackets: a number,
+	// "#" for an auto-numbered footnote, "#" followed by a reference name
+	// for a named auto-numbered footnote, or "*" for an auto-symbol
+	// footnote.
+	Label string `json:"label"`
+
+	// Number is the number of the footnote. Auto-numbered footnotes are
+	// numbered after parsing. Number is zero for auto-symbol footnotes.
+	Number int `json:"number"`
+
+	// Symbol is the symbol of an auto-symbol footnote. Symbols are
+	// assigned by resolveReferences.
+	Symbol string `json:"symbol"`
+
+	// BackRefs contains the ids of the references to the footnote.
+	BackRefs []string `json:"backRefs"`
+
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	NodeList      NodeList `json:"nodeList"`
+	Attributes    `json:"attributes"`
+}
+
+func newFootnote(i *item, label string, id *int) *FootnoteNode {
+	*id++
+	return &FootnoteNode{
+		ID:            ID(*id),
+		Type:          NodeFootnote,
+		Label:         label,
+		Line:          i.Line,
+		StartPosition: i.StartPosition,
+	}
+}
+
+// NodeType returns the Node type of the FootnoteNode.
+func (f FootnoteNode) NodeType() NodeType {
+	return f.Type
+}
+
+// TextNode is inline text without markup.
+type TextNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Text          string   `json:"text"`
+	Length        int      `json:"length"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	Attributes    `json:"attributes"`
+}
+
+// NodeType returns the Node type of the TextNode.
+func (t TextNode) NodeType() NodeType {
+	return t.Type
+}
+
+// EmphasisNode is inline emphasis. Text is the text between the start-string
+// and the end-string.
+type EmphasisNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Text          string   `json:"text"`
+	Length        int      `json:"length"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	Attributes    `json:"attributes"`
+}
+
+// NodeType returns the Node type of the EmphasisNode.
+func (e EmphasisNode) NodeType() NodeType {
+	return e.Type
+}
+
+// StrongNode is inline strong emphasis. Text is the text between the
+// start-string and the end-string.
+type StrongNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Text          string   `json:"text"`
+	Length        int      `json:"length"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	Attributes    `json:"attributes"`
+}
+
+// NodeType returns the Node type of the StrongNode.
+func (s StrongNode) NodeType() NodeType {
+	return s.Type
+}
+
+// InlineLiteralNode is an inline literal. Text is the text between the
+// start-string and the end-string.
+type InlineLiteralNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Text          string   `json:"text"`
+	Length        int      `json:"length"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	Attributes    `json:"attributes"`
+}
+
+// NodeType returns the Node type of the InlineLiteralNode.
+func (i InlineLiteralNode) NodeType() NodeType {
+	return i.Type
+}
+
+// ProblematicNode is inline markup that could not be recognized, for example
+// a start-string without an end-string. Text is the offending text, and
+// Message is the system message describing the problem. The system message is
+// added to Tree.Messages.
+type ProblematicNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Text          string   `json:"text"`
+	Length        int      `json:"length"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	Message       *SystemMessageNode `json:"message"`
+	Attributes    `json:"attributes"`
+}
+
+// NodeType returns the Node type of the ProblematicNode.
+func (p ProblematicNode) NodeType() NodeType {
+	return p.Type
+}
+
+// TopicNode is a topic element, a titled body element that is not a part of
+// the section structure. NodeList contains the parsed directive content.
+type TopicNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Title         string   `json:"title"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	NodeList      NodeList `json:"nodeList"`
+	Attributes    `json:"attributes"`
+}
+
+func newTopic(i *item, title string, id *int) *TopicNode {
+	*id++
+	return &TopicNode{
+		ID:            ID(*id),
+		Type:          NodeTopic,
+		Title:         title,
+		Line:          i.Line,
+		StartPosition: i.StartPosition,
+	}
+}
+
+// NodeType returns the Node type of the TopicNode.
+func (t TopicNode) NodeType() NodeType {
+	return t.Type
+}
+
+// SidebarNode is a sidebar element, a titled body element that is set apart
+// from the main text. Subtitle is the value of the :subtitle: option. NodeList
+// contains the parsed directive content.
+type SidebarNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Title         string   `json:"title"`
+	Subtitle      string   `json:"subtitle"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	NodeList      NodeList `json:"nodeList"`
+	Attributes    `json:"attributes"`
+}
+
+func newSidebar(i *item, title, subtitle string, id *int) *SidebarNode {
+	*id++
+	return &SidebarNode{
+		ID:            ID(*id),
+		Type:          NodeSidebar,
+		Title:         title,
+		Subtitle:      subtitle,
+		Line:          i.Line,
+		StartPosition: i.StartPosition,
+	}
+}
+
+// NodeType returns the Node type of the SidebarNode.
+func (s SidebarNode) NodeType() NodeType {
+	return s.Type
+}
+
+// RubricNode is an informal heading that is not a part of the section
+// structure. NodeList contains the inline nodes of Text.
+type RubricNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Text          string   `json:"text"`
+	Length        int      `json:"length"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	NodeList      NodeList `json:"nodeList"`
+	Attributes    `json:"attributes"`
+}
+
+func newRubric(i *item, text string, id *int) *RubricNode {
+	*id++
+	return &RubricNode{
+		ID:            ID(*id),
+		Type:          NodeRubric,
+		Text:          text,
+		Length:        len(text),
+		Line:          i.Line,
+		StartPosition: i.StartPosition,
+	}
+}
+
+// NodeType returns the Node type of the RubricNode.
+func (r RubricNode) NodeType() NodeType {
+	return r.Type
+}
+
+// ParsedLiteralNode is a literal block that allows inline markup. Text keeps
+// the whitespace and the line breaks of the directive content, and NodeList
+// contains the inline nodes of Text.
+type ParsedLiteralNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Text          string   `json:"text"`
+	Length        int      `json:"length"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	NodeList      NodeList `json:"nodeList"`
+	Attributes    `json:"attributes"`
+}
+
+func newParsedLiteral(i *item, text string, id *int) *ParsedLiteralNode {
+	*id++
+	return &ParsedLiteralNode{
+		ID:            ID(*id),
+		Type:          NodeParsedLiteral,
+		Text:          text,
+		Length:        len(text),
+		Line:          i.Line,
+		StartPosition: i.StartPosition,
+	}
+}
+
+// NodeType returns the Node type of the ParsedLiteralNode.
+func (p ParsedLiteralNode) NodeType() NodeType {
+	return p.Type
+}
+
+// CompoundNode is a compound paragraph, a group of body elements that form a
+// single logical paragraph. NodeList contains the parsed directive content.
+type CompoundNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	NodeList      NodeList `json:"nodeList"`
+	Attributes    `json:"attributes"`
+}
+
+func newCompound(i *item, id *int) *CompoundNode {
+	*id++
+	return &CompoundNode{
+		ID:            ID(*id),
+		Type:          NodeCompound,
+		Line:          i.Line,
+		StartPosition: i.StartPosition,
+	}
+}
+
+// NodeType returns the Node type of the CompoundNode.
+func (c CompoundNode) NodeType() NodeType {
+	return c.Type
+}
+
+// ContainerNode is a generic container of body elements. The class names of
+// the directive argument are added to the Classes of its Attributes. NodeList
+// contains the parsed directive content.
+type ContainerNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	NodeList      NodeList `json:"nodeList"`
+	Attributes    `json:"attributes"`
+}
+
+func newContainer(i *item, classes []string, id *int) *ContainerNode {
+	*id++
+	n := &ContainerNode{
+		ID:            ID(*id),
+		Type:          NodeContainer,
+		Line:          i.Line,
+		StartPosition: i.StartPosition,
+	}
+	n.Classes = classes
+	return n
+}
+
+// NodeType returns the Node type of the ContainerNode.
+func (c ContainerNode) NodeType() NodeType {
+	return c.Type
+}
+
+// SubstitutionDefinitionNode is a substitution definition, like
+// ".. |logo| image:: logo.png". Name is the substitution text between the
+// vertical bars, its whitespace normalized name is added to the Names of the
+// Attributes. NodeList contains the node of the embedded directive, which
+// replaces the references to the substitution.
+type SubstitutionDefinitionNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Name          string   `json:"name"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	NodeList      NodeList `json:"nodeList"`
+	Attributes    `json:"attributes"`
+}
+
+func newSubstitutionDefinition(i *item, name string,
+	id *int) *SubstitutionDefinitionNode {
+
+	*id++
+	n := &SubstitutionDefinitionNode{
+		ID:            ID(*id),
+		Type:          NodeSubstitutionDefinition,
+		Name:          name,
+		Line:          i.Line,
+		StartPosition: i.StartPosition,
+	}
+	n.Names = []string{strings.Join(strings.Fields(name), " ")}
+	return n
+}
+
+// NodeType returns the Node type of the SubstitutionDefinitionNode.
+func (s SubstitutionDefinitionNode) NodeType() NodeType {
+	return s.Type
+}
+
+// FootnoteRefNode is an inline footnote reference. Label is the text between
+// the brackets, and Auto is the numbering of the referenced footnote. Refid,
+// Number, and Symbol are set from the referenced footnote by
+// resolveReferences.
+type FootnoteRefNode struct {
+	ID            `json:"id"`
+	Type          NodeType         `json:"type"`
+	Label         string           `json:"label"`
+	Auto          FootnoteAutoType `json:"auto"`
+	Refid         string           `json:"refid"`
+	Number        int              `json:"number"`
+	Symbol        string           `json:"symbol"`
+	Length        int              `json:"length"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	Attributes    `json:"attributes"`
+}
+
+// NodeType returns the Node type of the FootnoteRefNode.
+func (f FootnoteRefNode) NodeType() NodeType {
+	return f.Type
+}
+
+// CitationRefNode is an inline citation reference. Label is the text between
+// the brackets, and Refid is the id of the referenced citation.
+type CitationRefNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Label         string   `json:"label"`
+	Refid         string   `json:"refid"`
+	Length        int      `json:"length"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	Attributes    `json:"attributes"`
+}
+
+// NodeType returns the Node type of the CitationRefNode.
+func (c CitationRefNode) NodeType() NodeType {
+	return c.Type
+}
+
+// ReferenceNode is an inline hyperlink phrase reference, "`text`_". Text is
+// the reference text. RefURI is the embedded URI of "`text <uri>`_", and
+// RefName is the reference name of the referenced target, it is the embedded
+// alias of "`text <alias_>`_" if there is one. Anonymous references,
+// "`text`__", have no RefName unless they embed an alias.
+type ReferenceNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Text          string   `json:"text"`
+	Anonymous     bool     `json:"anonymous"`
+	RefURI        string   `json:"refURI"`
+	RefName       string   `json:"refName"`
+	Length        int      `json:"length"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	Attributes    `json:"attributes"`
+}
+
+// NodeType returns the Node type of the ReferenceNode.
+func (r ReferenceNode) NodeType() NodeType {
+	return r.Type
+}
+
+// CitationNode is a citation element. The paragraph of the citation body is
+// contained in NodeList.
+type CitationNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Label         string   `json:"label"`
+	BackRefs      []string `json:"backRefs"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	NodeList      NodeList `json:"nodeList"`
+	Attributes    `json:"attributes"`
+}
+
+func newCitation(i *item, label string, id *int) *CitationNode {
+	*id++
+	return &CitationNode{
+		ID:            ID(*id),
+		Type:          NodeCitation,
+		Label:         label,
+		Line:          i.Line,
+		StartPosition: i.StartPosition,
+	}
+}
+
+// NodeType returns the Node type of the CitationNode.
+func (c CitationNode) NodeType() NodeType {
+	return c.Type
+}
+
+// TargetNode is a hyperlink target. Name is the reference name of the target,
+// it is empty for anonymous targets. RefURI is the link of an external
+// target, and RefName is the reference name of an indirect target. Both are
+// empty for internal targets.
+type TargetNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Name          string   `json:"name"`
+	Anonymous     bool     `json:"anonymous"`
+	RefURI        string   `json:"refURI"`
+	RefName       string   `json:"refName"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	Attributes    `json:"attributes"`
+}
+
+func newTarget(i *item, name string, anonymous bool, id *int) *TargetNode {
+	*id++
+	return &TargetNode{
+		ID:            ID(*id),
+		Type:          NodeTarget,
+		Name:          name,
+		Anonymous:     anonymous,
+		Line:          i.Line,
+		StartPosition: i.StartPosition,
+	}
+}
+
+// NodeType returns the Node type of the TargetNode.
+func (t TargetNode) NodeType() NodeType {
+	return t.Type
+}
+
+// DirectiveNode is a directive that could not be parsed into a node of its
+// own. It contains the parts of the directive block, and is followed by a
+// system message explaining why the directive was not parsed.
+type DirectiveNode struct {
+	ID            `json:"id"`
+	Type          NodeType          `json:"type"`
+	Name          string            `json:"name"`
+	Argument      string            `json:"argument"`
+	Options       map[string]string `json:"options"`
+	Content       string            `json:"content"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	Attributes    `json:"attributes"`
+}
+
+func newDirective(i *item, d *directive, id *int) *DirectiveNode {
+	*id++
+	return &DirectiveNode{
+		ID:            ID(*id),
+		Type:          NodeDirective,
+		Name:          d.name,
+		Argument:      d.argument,
+		Options:       d.options,
+		Content:       d.content,
+		Line:          i.Line,
+		StartPosition: i.StartPosition,
+	}
+}
+
+// NodeType returns the Node type of the DirectiveNode.
+func (d DirectiveNode) NodeType() NodeType {
+	return d.Type
+}
+
+// FieldListNode is a field list. The fields of the list are contained in
+// NodeList.
+type FieldListNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	NodeList      NodeList `json:"nodeList"`
+	Attributes    `json:"attributes"`
+}
+
+func newFieldList(i *item, id *int) *FieldListNode {
+	*id++
+	return &FieldListNode{
+		ID:            ID(*id),
+		Type:          NodeFieldList,
+		Line:          i.Line,
+		StartPosition: i.StartPosition,
+	}
+}
+
+// NodeType returns the Node type of the FieldListNode.
+func (f FieldListNode) NodeType() NodeType {
+	return f.Type
+}
+
+// FieldNode is a field of a field list. Name is the text between the colons of
+// the field marker, and the nodes of the field body are contained in
+// NodeList.
+type FieldNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Name          string   `json:"name"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	NodeList      NodeList `json:"nodeList"`
+	Attributes    `json:"attributes"`
+}
+
+func newField(i *item, id *int) *FieldNode {
+	*id++
+	return &FieldNode{
+		ID:            ID(*id),
+		Type:          NodeField,
+		Name:          i.Text,
+		Line:          i.Line,
+		StartPosition: i.StartPosition,
+	}
+}
+
+// NodeType returns the Node type of the FieldNode.
+func (f FieldNode) NodeType() NodeType {
+	return f.Type
+}
+
+// DocInfoNode is the bibliographic data of a document, promoted from a field
+// list at the beginning of the document. The text of the bibliographic fields
+// known to docutils is stored in the typed fields, Authors contains the names
+// of an "Authors" field. The other fields of the list, and the bibliographic
+// fields that could not be converted, are kept as FieldNodes in NodeList.
+type DocInfoNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Author        string   `json:"author"`
+	Authors       []string `json:"authors"`
+	Organization  string   `json:"organization"`
+	Contact       string   `json:"contact"`
+	Address       string   `json:"address"`
+	Version       string   `json:"version"`
+	Revision      string   `json:"revision"`
+	Status        string   `json:"status"`
+	Date          string   `json:"date"`
+	Copyright     string   `json:"copyright"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	NodeList      NodeList `json:"nodeList"`
+	Attributes    `json:"attributes"`
+}
+
+// NodeType returns the Node type of the DocInfoNode.
+func (d DocInfoNode) NodeType() NodeType {
+	return d.Type
+}
+
+// LineBlockNode is a line block. NodeList contains the LineNodes of the
+// block, and the nested LineBlockNodes of lines indented more than the least
+// indented lines of the block.
+type LineBlockNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	NodeList      NodeList `json:"nodeList"`
+	Attributes    `json:"attributes"`
+}
+
+func newLineBlock(i *item, id *int) *LineBlockNode {
+	*id++
+	return &LineBlockNode{
+		ID:            ID(*id),
+		Type:          NodeLineBlock,
+		Line:          i.Line,
+		StartPosition: i.StartPosition,
+	}
+}
+
+// NodeType returns the Node type of the LineBlockNode.
+func (l LineBlockNode) NodeType() NodeType {
+	return l.Type
+}
+
+// LineNode is a line of a line block. Text is the text after the "|" marker
+// and its indentation, and the continuation lines of the line, separated by
+// newlines. The text of an empty line is empty.
+type LineNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	Text          string   `json:"text"`
+	Length        int      `json:"length"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	Attributes    `json:"attributes"`
+}
+
+func newLine(i *item, id *int) *LineNode {
+	*id++
+	return &LineNode{
+		ID:            ID(*id),
+		Type:          NodeLine,
+		Text:          i.Text,
+		Length:        i.Length,
+		Line:          i.Line,
+		StartPosition: i.StartPosition,
+	}
+}
+
+// NodeType returns the Node type of the LineNode.
+func (l LineNode) NodeType() NodeType {
+	return l.Type
+}
+
+// TableNode is a grid table. NodeList contains the TableRowNodes of the table,
+// the first HeadRows rows are the header rows. ColWidths contains the width of
+// each column of the grid in display columns.
+type TableNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	HeadRows      int      `json:"headRows"`
+	ColWidths     []int    `json:"colWidths"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	NodeList      NodeList `json:"nodeList"`
+	Attributes    `json:"attributes"`
+}
+
+func newTable(i *item, id *int) *TableNode {
+	*id++
+	return &TableNode{
+		ID:            ID(*id),
+		Type:          NodeTable,
+		Line:          i.Line,
+		StartPosition: i.StartPosition,
+	}
+}
+
+// NodeType returns the Node type of the TableNode.
+func (t TableNode) NodeType() NodeType {
+	return t.Type
+}
+
+// TableRowNode is a row of a table. NodeList contains the TableEntryNodes of
+// the cells whose top border is the top border of the row.
+type TableRowNode struct {
+	ID         `json:"id"`
+	Type       NodeType `json:"type"`
+	Line       `json:"line"`
+	NodeList   NodeList `json:"nodeList"`
+	Attributes `json:"attributes"`
+}
+
+func newTableRow(i *item, id *int) *TableRowNode {
+	*id++
+	return &TableRowNode{
+		ID:   ID(*id),
+		Type: NodeTableRow,
+		Line: i.Line,
+	}
+}
+
+// NodeType returns the Node type of the TableRowNode.
+func (t TableRowNode) NodeType() NodeType {
+	return t.Type
+}
+
+// TableEntryNode is a cell of a table. MoreRows and MoreCols are the number of
+// additional rows and columns spanned by the cell. The body elements of the
+// cell are contained in NodeList.
+type TableEntryNode struct {
+	ID            `json:"id"`
+	Type          NodeType `json:"type"`
+	MoreRows      int      `json:"moreRows"`
+	MoreCols      int      `json:"moreCols"`
+	Line          `json:"line"`
+	StartPosition `json:"startPosition"`
+	NodeList      NodeList `json:"nodeList"`
+	Attributes    `json:"attributes"`
+}
+
+func newTableEntry(i *item, id *int) *TableEntryNode {
+	*id++
+	return &TableEntryNode{
+		ID:            ID(*id),
+		Type:          NodeTableEntry,
+		Line:          i.Line,
+		StartPosition: i.StartPosition,
+	}
+}
+
+// NodeType returns the Node type of the TableEntryNode.
+func (t TableEntryNode) NodeType() NodeType {
+	return t.Type
+}
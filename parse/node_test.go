@@ -1,6 +1,8 @@
 package parse
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 )
 
@@ -11,6 +13,67 @@ func TestNodeType(t *testing.T) {
 	}
 }
 
+func TestNodeTypeMarshalText(t *testing.T) {
+	for num := range nodeTypes {
+		n := NodeType(num)
+		text, err := n.MarshalText()
+		if err != nil {
+			t.Fatalf("%s: MarshalText error: %s", n, err)
+		}
+		var got NodeType
+		if err := got.UnmarshalText(text); err != nil {
+			t.Fatalf("%s: UnmarshalText error: %s", n, err)
+		}
+		if got != n {
+			t.Errorf("UnmarshalText(%q) == %s, expect %s", text, got, n)
+		}
+	}
+}
+
+func TestNodeTypeTable(t *testing.T) {
+	// The last node type must be the last entry of the nodeTypes table.
+	if int(NodeSubstitutionReference)+1 != len(nodeTypes) {
+		t.Errorf("len(nodeTypes) == %d, expect %d", len(nodeTypes),
+			int(NodeSubstitutionReference)+1)
+	}
+	// The values of the existing node types are used by the fixtures.
+	if NodeClassifier != 35 {
+		t.Errorf("NodeClassifier == %d, expect 35", NodeClassifier)
+	}
+	for n := NodeSection; n <= NodeSubstitutionReference; n++ {
+		if !strings.HasPrefix(n.String(), "Node") {
+			t.Errorf("NodeType(%d).String() == %q, expect a node type "+
+				"name", int(n), n.String())
+		}
+	}
+}
+
+func TestNodeTypeJSON(t *testing.T) {
+	data, err := json.Marshal(struct{ Type NodeType }{NodeComment})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"Type":"NodeComment"}` {
+		t.Errorf("json.Marshal == %s, expect %s", data,
+			`{"Type":"NodeComment"}`)
+	}
+	var n struct{ Type NodeType }
+	if err := json.Unmarshal(data, &n); err != nil {
+		t.Fatal(err)
+	}
+	if n.Type != NodeComment {
+		t.Errorf("json.Unmarshal Type == %s, expect %s", n.Type,
+			NodeComment)
+	}
+}
+
+func TestNodeTypeUnmarshalTextUnknown(t *testing.T) {
+	var n NodeType
+	if err := n.UnmarshalText([]byte("NodeUnknown")); err == nil {
+		t.Error("UnmarshalText(\"NodeUnknown\") did not return an error")
+	}
+}
+
 func TestAdornmentNodeType(t *testing.T) {
 	n := &AdornmentNode{Type: NodeAdornment}
 	if n.NodeType() != NodeAdornment {
@@ -74,9 +137,35 @@ func TestDefinitionNodeType(t *testing.T) {
 	}
 }
 
+func TestBlankLineNodeType(t *testing.T) {
+	n := &BlankLineNode{Type: NodeBlankLine}
+	if n.NodeType() != NodeBlankLine {
+		t.Error("n.Type != NodeBlankLine")
+	}
+}
+
 func TestBulletListType(t *testing.T) {
 	n := &BulletListNode{Type: NodeBulletList}
 	if n.NodeType() != NodeBulletList {
 		t.Error("n.Type != NodeBulletList")
 	}
 }
+
+func TestNodeAttributes(t *testing.T) {
+	for _, n := range []Node{&ParagraphNode{}, &SectionNode{}} {
+		if !n.Attrs().IsEmpty() {
+			t.Errorf("Got: %T.Attrs().IsEmpty() = false, Expect: true", n)
+		}
+		a := n.Attrs()
+		a.Ids = append(a.Ids, "title")
+		a.Names = append(a.Names, "Title")
+		a.Classes = append(a.Classes, "special")
+		if n.Attrs().IsEmpty() {
+			t.Errorf("Got: %T.Attrs().IsEmpty() = true, Expect: false", n)
+		}
+		if c := n.Attrs().Classes; len(c) != 1 || c[0] != "special" {
+			t.Errorf("Got: %T.Attrs().Classes = %v, Expect: [special]",
+				n, c)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseOptions contains the settings used to configure the parser. The zero
+// value of ParseOptions is the default configuration.
+type ParseOptions struct {
+	// MaxLineLength is the maximum number of characters allowed in a line of
+	// input. An info level system message is generated for each line that
+	// exceeds the limit. Lines of literal blocks and tables are not checked.
+	// A value of zero disables the check.
+	MaxLineLength int
+
+	// AdornmentChars is the set of characters recognized as section
+	// adornments and transition markers. Lines made of other characters are
+	// parsed as text. If nil, all of the 7-bit ASCII punctuation characters
+	// allowed by the reStructuredText specification are used.
+	AdornmentChars []rune
+
+	// ReportLevel is the lowest level of the system messages added to
+	// Tree.Messages and Tree.Diagnostics, using the docutils numbering: 1
+	// info, 2 warning, 3 error, and 4 severe. System messages in the
+	// document are not affected. A value of zero reports all messages.
+	ReportLevel int
+
+	// HaltLevel is the lowest level of the system messages that stop the
+	// parser, using the same numbering as ReportLevel. When the parser is
+	// halted, Tree.Halted is set and the remaining input is not parsed. A
+	// value of zero, or a value above 4, never halts the parser.
+	HaltLevel int
+
+	// MaxDiagnostics is the maximum number of system messages added to
+	// Tree.Messages and Tree.Diagnostics. When the limit is reached, an
+	// info level message noting that further messages are suppressed is
+	// added, and the remaining messages are dropped. Parsing continues, and
+	// system messages in the document are not affected. A value of zero
+	// does not limit the number of messages.
+	MaxDiagnostics int
+
+	// KeepBlankLines adds a BlankLineNode to the parse tree for each blank
+	// line of input. By default blank lines only separate the elements of
+	// the document and are discarded.
+	KeepBlankLines bool
+
+	// PromoteTitle promotes the title of a lone top-level section to the
+	// document title after parsing, like the docutils DocTitle transform.
+	// The title is moved to Tree.Title and the contents of the section
+	// become the top-level nodes. If the contents are again a lone section,
+	// its title is promoted to Tree.Subtitle in the same way. Title
+	// promotion is a transform in docutils and is not a part of the parser
+	// output, so it is disabled by default.
+	PromoteTitle bool
+
+	// DocInfo replaces a field list that is the first element of the
+	// document, after the promoted title if PromoteTitle is set, by a
+	// DocInfoNode, like the docutils DocInfo transform. The text of the
+	// bibliographic fields, such as Author and Date, is stored in the typed
+	// fields of the DocInfoNode. Like title promotion, it is disabled by
+	// default.
+	DocInfo bool
+
+	// ParseInline parses the inline markup of paragraphs into the NodeList
+	// of the ParagraphNodes. Emphasis, strong emphasis, inline literals,
+	// footnote and citation references, phrase references, and interpreted
+	// text with an explicit role are recognized, the other inline markup is
+	// kept as text. Inline markup that can not be recognized is added as a
+	// ProblematicNode with a warning.
+	ParseInline bool
+
+	// IndentedSections parses section titles in block quotes as sections
+	// nested in the block quote, when the adornments and the title of a
+	// section share the indentation of the block quote. The levels of the
+	// nested sections are counted from the block quote. By default, like
+	// docutils, section titles in block quotes are reported as unexpected.
+	IndentedSections bool
+
+	// TabWidth is the number of columns between tab stops. Tabs in the
+	// indentation of the input are expanded to the next tab stop when the
+	// indentation of lines is compared and when the level of block quotes
+	// and the length of adornments are computed. A value of zero uses the
+	// default of 8 columns, like docutils.
+	TabWidth int
+
+	// EastAsianWidth counts the characters of ambiguous East Asian width,
+	// like Greek and Cyrillic letters and box drawing characters, as two
+	// columns when the width of a section title is compared to the length
+	// of its adornments. This matches the display of East Asian fonts and
+	// terminals. Wide and fullwidth characters, like CJK ideographs, are
+	// always counted as two columns, like docutils does.
+	EastAsianWidth bool
+
+	// StrictAdornments reports section overlines and underlines that are
+	// shorter than the section title as errors instead of warnings. The
+	// section is parsed in both cases, like docutils does.
+	StrictAdornments bool
+
+	// TolerateIndentation disables the errors for indentation that can not
+	// be consistently interpreted, for example a block indented with a tab
+	// on one line and with spaces on another. The tabs are still expanded
+	// to the tab stops set by TabWidth.
+	TolerateIndentation bool
+
+	// Directives are handlers of directives added to the parser. A
+	// directive is parsed into a generic DirectiveNode, which is passed to
+	// the handler with the name of the directive. The handlers take
+	// precedence over the built-in directives of the same name, so a
+	// built-in directive can be replaced.
+	Directives []DirectiveHandler
+
+	// Roles are handlers of interpreted text roles added to the parser.
+	// Interpreted text with an explicit role, ":role:`text`" or
+	// "`text`:role:", is passed to the handler with the name of the role
+	// when inline markup is parsed with ParseInline. The handlers take
+	// precedence over the built-in roles of the same name.
+	Roles []RoleHandler
+
+	// Source is the name of the input used in diagnostics and positions,
+	// for example the file a document was included from. The name passed
+	// to Parse identifies the Tree, and is used when Source is empty.
+	Source string
+}
+
+// StrictMode returns options that mirror the docutils "--strict" setting. All
+// system messages are reported and the parser halts at the first of them, an
+// info message included (ReportLevel and HaltLevel 1). Short section
+// adornments are errors (StrictAdornments), and inconsistent indentation is
+// reported. The other options keep their default values.
+func StrictMode() *ParseOptions {
+	return &ParseOptions{ReportLevel: 1, HaltLevel: 1,
+		StrictAdornments: true}
+}
+
+// ForgivingMode returns options that report warnings and more severe system
+// messages like the docutils defaults, but never halt the parser, so that as
+// much of the input as possible is parsed (ReportLevel 2 and HaltLevel 5).
+// Info messages are not reported, short section adornments are warnings, and
+// inconsistent indentation is tolerated (TolerateIndentation). The other
+// options keep their default values.
+func ForgivingMode() *ParseOptions {
+	return &ParseOptions{ReportLevel: 2, HaltLevel: 5,
+		TolerateIndentation: true}
+}
+
+// source returns Options.Source, or the name of the Tree if it is not set.
+func (t *Tree) source() string {
+	if t.Options.Source != "" {
+		return t.Options.Source
+	}
+	return t.Name
+}
+
+// messageLevel returns the level of a system message severity using the
+// docutils numbering of ReportLevel and HaltLevel.
+func messageLevel(s systemMessageLevel) int {
+	return int(s) + 1
+}
+
+// checkLineLength generates an infoLineTooLong system message for each line of
+// input that is longer than Options.MaxLineLength. The system messages are
+// added to Tree.Messages only, they are not a part of the parsed document.
+func (t *Tree) checkLineLength() {
+	lines := strings.Split(t.text, "\n")
+	skip := literalLines(lines, t.tabWidth())
+	for num, line := range lines {
+		if skip[num] {
+			continue
+		}
+		if utf8.RuneCountInString(line) <= t.Options.MaxLineLength {
+			continue
+		}
+		s := newSystemMessage(&item{
+			Type: itemSystemMessage,
+			Line: Line(num + 1),
+		}, infoLineTooLong, &t.id)
+		msg := infoLineTooLong.Message()
+		s.NodeList = append(s.NodeList, newParagraph(&item{
+			Text:   msg,
+			Length: len(msg),
+		}, &t.id))
+		t.addMessage(s, StartPosition(t.Options.MaxLineLength+1))
+	}
+}
+
+// literalLines returns a map of line indexes that are part of literal blocks
+// or tables. Literal blocks are the indented or quoted lines following a line
+// ending with "::". Tables are the text blocks that begin with a table border.
+func literalLines(lines []string, tabWidth int) map[int]bool {
+	skip := make(map[int]bool)
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if isTableBorder(line) && (i == 0 || lineIsBlank(lines[i-1])) {
+			for ; i < len(lines) && !lineIsBlank(lines[i]); i++ {
+				skip[i] = true
+			}
+			continue
+		}
+		if !strings.HasSuffix(line, "::") {
+			continue
+		}
+		indent := lineIndent(lines[i], tabWidth)
+		if start, end := quotedLiteralLines(lines, i, indent,
+			tabWidth); start != -1 {
+
+			for ; start < end; start++ {
+				skip[start] = true
+			}
+			i = end - 1
+			continue
+		}
+		for j := i + 1; j < len(lines); j++ {
+			if lineIsBlank(lines[j]) {
+				continue
+			}
+			if lineIndent(lines[j], tabWidth) <= indent {
+				break
+			}
+			skip[j] = true
+			i = j
+		}
+	}
+	return skip
+}
+
+// isTableBorder returns true if line is the top border of a grid table or a
+// simple table.
+func isTableBorder(line string) bool {
+	if strings.HasPrefix(line, "+-") || strings.HasPrefix(line, "+=") {
+		return strings.Trim(line, "+-=") == ""
+	}
+	return strings.Trim(line, "= ") == "" && len(strings.Fields(line)) > 1
+}
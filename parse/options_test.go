@@ -0,0 +1,412 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestParseOptionsMaxLineLength(t *testing.T) {
+	name := "Test MaxLineLength with a long paragraph and literal block line"
+	input := "Short line.\n\n" +
+		"This paragraph line is longer than forty characters.\n\n" +
+		"Literal block::\n\n" +
+		"    This literal block line is longer than forty characters.\n\n" +
+		"Short line."
+	_, errors := ParseWithOptions(name, input,
+		&ParseOptions{MaxLineLength: 40})
+	if len(errors) != 1 {
+		t.Fatalf("Test: %q\n\t    "+
+			"Got: len(errors) = %d, Expect: %d\n\n",
+			name, len(errors), 1)
+	}
+	sm := errors[0].(*SystemMessageNode)
+	if sm.MessageType != infoLineTooLong {
+		t.Errorf("Test: %q\n\t    "+
+			"Got: MessageType = %q, Expect: %q\n\n",
+			name, sm.MessageType, infoLineTooLong)
+	}
+	if sm.Severity != levelInfo {
+		t.Errorf("Test: %q\n\t    "+
+			"Got: Severity = %q, Expect: %q\n\n",
+			name, sm.Severity, levelInfo)
+	}
+	if sm.Line != 3 {
+		t.Errorf("Test: %q\n\t    "+
+			"Got: Line = %d, Expect: %d\n\n", name, sm.Line, 3)
+	}
+}
+
+func TestParseOptionsMaxLineLengthDisabled(t *testing.T) {
+	name := "Test MaxLineLength disabled by default"
+	input := "This paragraph line is longer than forty characters."
+	if _, errors := Parse(name, input); len(errors) != 0 {
+		t.Errorf("Test: %q\n\t    "+
+			"Got: len(errors) = %d, Expect: %d\n\n",
+			name, len(errors), 0)
+	}
+}
+
+func TestParseOptionsAdornmentChars(t *testing.T) {
+	name := "Test AdornmentChars restricted to '=' and '-'"
+	input := "Title\n=====\n\nParagraph.\n\nNot a title\n~~~~~~~~~~~"
+	tr, errors := ParseWithOptions(name, input,
+		&ParseOptions{AdornmentChars: []rune{'=', '-'}})
+	if len(errors) != 0 {
+		t.Errorf("Test: %q\n\t    Got: len(errors) = %d, Expect: %d\n\n",
+			name, len(errors), 0)
+	}
+	if len(tr.Nodes) != 1 {
+		t.Fatalf("Test: %q\n\t    Got: len(Nodes) = %d, Expect: %d\n\n",
+			name, len(tr.Nodes), 1)
+	}
+	sec := tr.Nodes[0].(*SectionNode)
+	if len(sec.NodeList) != 2 {
+		t.Fatalf("Test: %q\n\t    "+
+			"Got: len(SectionNode.NodeList) = %d, Expect: %d\n\n",
+			name, len(sec.NodeList), 2)
+	}
+	p, ok := sec.NodeList[1].(*ParagraphNode)
+	if !ok || p.Text != "Not a title\n~~~~~~~~~~~" {
+		t.Errorf("Test: %q\n\t    Got: %#v, Expect: %q paragraph\n\n",
+			name, sec.NodeList[1], "Not a title\n~~~~~~~~~~~")
+	}
+}
+
+var parseOptionsModeTests = []struct {
+	name    string
+	input   string
+	opts    *ParseOptions
+	halted  bool
+	errors  int           // The expected number of reported messages
+	message parserMessage // The expected type of the first message
+	nodes   int           // The expected nodes in the first section
+}{
+	{
+		name:    "Short underline in strict mode",
+		input:   "Title\n===\n\nParagraph.",
+		opts:    StrictMode(),
+		halted:  true,
+		errors:  1,
+		message: errorShortUnderline,
+		nodes:   1,
+	},
+	{
+		name:    "Short underline in forgiving mode",
+		input:   "Title\n===\n\nParagraph.",
+		opts:    ForgivingMode(),
+		errors:  1,
+		message: warningShortUnderline,
+		nodes:   2,
+	},
+	{
+		name:    "Duplicate title in strict mode",
+		input:   "Title\n=====\n\nTitle\n=====",
+		opts:    StrictMode(),
+		halted:  true,
+		errors:  1,
+		message: infoDuplicateImplicitTargetName,
+		nodes:   0,
+	},
+	{
+		name:   "Duplicate title in forgiving mode",
+		input:  "Title\n=====\n\nTitle\n=====",
+		opts:   ForgivingMode(),
+		errors: 0,
+		nodes:  0,
+	},
+	{
+		name:    "Inconsistent indentation in strict mode",
+		input:   "Title\n=====\n\n    Line one.\n\tLine two.",
+		opts:    StrictMode(),
+		halted:  true,
+		errors:  1,
+		message: errorInconsistentIndentation,
+		nodes:   1,
+	},
+	{
+		name:   "Inconsistent indentation in forgiving mode",
+		input:  "Title\n=====\n\n    Line one.\n\tLine two.",
+		opts:   ForgivingMode(),
+		errors: 0,
+		nodes:  1,
+	},
+}
+
+func TestParseOptionsModes(t *testing.T) {
+	for _, tt := range parseOptionsModeTests {
+		tr, errors := ParseWithOptions(tt.name, tt.input, tt.opts)
+		if tr.Halted != tt.halted {
+			t.Errorf("Test: %q\n\t    Got: Halted = %t, Expect: %t\n\n",
+				tt.name, tr.Halted, tt.halted)
+		}
+		if len(errors) != tt.errors {
+			t.Errorf("Test: %q\n\t    Got: len(errors) = %d, "+
+				"Expect: %d\n\n", tt.name, len(errors), tt.errors)
+		}
+		if len(tr.Diagnostics) != tt.errors {
+			t.Errorf("Test: %q\n\t    Got: len(Diagnostics) = %d, "+
+				"Expect: %d\n\n", tt.name, len(tr.Diagnostics),
+				tt.errors)
+		} else if tt.errors > 0 &&
+			tr.Diagnostics[0].MessageType != tt.message {
+			t.Errorf("Test: %q\n\t    Got: MessageType = %s, "+
+				"Expect: %s\n\n", tt.name,
+				tr.Diagnostics[0].MessageType, tt.message)
+		}
+		sec := tr.Nodes[0].(*SectionNode)
+		if len(sec.NodeList) != tt.nodes {
+			t.Errorf("Test: %q\n\t    Got: len(NodeList) = %d, "+
+				"Expect: %d\n\n", tt.name, len(sec.NodeList),
+				tt.nodes)
+		}
+	}
+}
+
+var parseOptionsKeepBlankLinesTests = []struct {
+	name   string
+	input  string
+	keep   bool
+	types  []NodeType // The expected types of the top level nodes
+	errors int        // The expected number of system messages
+}{
+	{
+		name:  "Blank lines discarded",
+		input: "Paragraph one.\n\nParagraph two.",
+		types: []NodeType{NodeParagraph, NodeParagraph},
+	},
+	{
+		name:  "Blank lines kept",
+		input: "Paragraph one.\n\nParagraph two.",
+		keep:  true,
+		types: []NodeType{NodeParagraph, NodeBlankLine, NodeParagraph},
+	},
+	{
+		name:  "Transition between kept blank lines",
+		input: "Paragraph one.\n\n----------\n\nParagraph two.",
+		keep:  true,
+		types: []NodeType{NodeParagraph, NodeBlankLine, NodeTransition,
+			NodeBlankLine, NodeParagraph},
+	},
+	{
+		name:  "Transition after kept blank line at beginning",
+		input: "\n----------\n\nParagraph.",
+		keep:  true,
+		types: []NodeType{NodeBlankLine, NodeSystemMessage,
+			NodeTransition, NodeBlankLine, NodeParagraph},
+		errors: 1,
+	},
+}
+
+func TestParseOptionsKeepBlankLines(t *testing.T) {
+	for _, tt := range parseOptionsKeepBlankLinesTests {
+		tr, errors := ParseWithOptions(tt.name, tt.input,
+			&ParseOptions{KeepBlankLines: tt.keep})
+		if len(errors) != tt.errors {
+			t.Errorf("Test: %q\n\t    Got: len(errors) = %d, "+
+				"Expect: %d\n\n", tt.name, len(errors), tt.errors)
+		}
+		if len(tr.Nodes) != len(tt.types) {
+			t.Errorf("Test: %q\n\t    Got: len(Nodes) = %d, "+
+				"Expect: %d\n\n", tt.name, len(tr.Nodes),
+				len(tt.types))
+			continue
+		}
+		for num, n := range tr.Nodes {
+			if n.NodeType() != tt.types[num] {
+				t.Errorf("Test: %q\n\t    Got: Nodes[%d] = %s, "+
+					"Expect: %s\n\n", tt.name, num,
+					n.NodeType(), tt.types[num])
+			}
+		}
+	}
+}
+
+var parseOptionsIndentedSectionsTests = []struct {
+	name   string
+	input  string
+	titles []string // The expected titles of the nested sections
+	levels []int    // The expected levels of the nested sections
+}{
+	{
+		name: "Underlined titles in a block quote",
+		input: "Para.\n\n    Title\n    =====\n\n    Body.\n\n" +
+			"    Sub\n    ---\n\n    Sub body.\n\nAfter.",
+		titles: []string{"Title", "Sub"},
+		levels: []int{1, 2},
+	},
+	{
+		name:   "Overlined title in a block quote",
+		input:  "Para.\n\n    =====\n    Title\n    =====\n\n    Body.",
+		titles: []string{"Title"},
+		levels: []int{1},
+	},
+}
+
+func TestParseOptionsIndentedSections(t *testing.T) {
+	for _, tt := range parseOptionsIndentedSectionsTests {
+		tr, errors := ParseWithOptions(tt.name, tt.input,
+			&ParseOptions{IndentedSections: true})
+		if len(errors) != 0 {
+			t.Errorf("Test: %q\n\t    Got: len(errors) = %d, "+
+				"Expect: 0\n\n", tt.name, len(errors))
+			continue
+		}
+		bq, ok := tr.Nodes[1].(*BlockQuoteNode)
+		if !ok {
+			t.Errorf("Test: %q\n\t    Got: Nodes[1] = %s, "+
+				"Expect: NodeBlockQuote\n\n", tt.name,
+				tr.Nodes[1].NodeType())
+			continue
+		}
+		var titles []string
+		var levels []int
+		Walk(bq.NodeList, func(n Node) bool {
+			if s, ok := n.(*SectionNode); ok {
+				titles = append(titles, s.Title.Text)
+				levels = append(levels, s.Level)
+			}
+			return true
+		})
+		if !reflect.DeepEqual(titles, tt.titles) ||
+			!reflect.DeepEqual(levels, tt.levels) {
+			t.Errorf("Test: %q\n\t    Got: sections = %q %v, "+
+				"Expect: %q %v\n\n", tt.name, titles, levels,
+				tt.titles, tt.levels)
+		}
+		// The body of the block quote is nested in the first section.
+		sec := bq.NodeList[0].(*SectionNode)
+		if p, ok := sec.NodeList[0].(*ParagraphNode); !ok ||
+			p.Text != "Body." {
+			t.Errorf("Test: %q\n\t    Got: NodeList[0] = %#v, "+
+				"Expect: paragraph \"Body.\"\n\n", tt.name,
+				sec.NodeList[0])
+		}
+		if errs := tr.Validate(); len(errs) != 0 {
+			t.Errorf("Test: %q\n\t    Got: Validate() = %v, "+
+				"Expect: no errors\n\n", tt.name, errs)
+		}
+	}
+}
+
+func TestParseOptionsIndentedSectionsDisabled(t *testing.T) {
+	_, errors := Parse("indented section", "Para.\n\n    Title\n    =====")
+	if len(errors) != 1 || errors[0].(*SystemMessageNode).MessageType !=
+		severeUnexpectedSectionTitle {
+		t.Errorf("Got: errors = %v, Expect: %s", errors,
+			severeUnexpectedSectionTitle)
+	}
+}
+
+func TestParseOptionsTabWidth(t *testing.T) {
+	input := "Para.\n\n\tQuoted text.\n\nAfter."
+	for _, tt := range []struct {
+		tabWidth int
+		level    int // The expected level of the block quote
+	}{
+		{tabWidth: 4, level: 1},
+		{tabWidth: 8, level: 2},
+		{tabWidth: 0, level: 2},
+	} {
+		tr, errors := ParseWithOptions("tab width", input,
+			&ParseOptions{TabWidth: tt.tabWidth})
+		if len(errors) != 0 {
+			t.Errorf("TabWidth %d: Got: len(errors) = %d, Expect: 0",
+				tt.tabWidth, len(errors))
+			continue
+		}
+		bq, ok := tr.Nodes[1].(*BlockQuoteNode)
+		if !ok {
+			t.Errorf("TabWidth %d: Got: Nodes[1] = %s, "+
+				"Expect: NodeBlockQuote", tt.tabWidth,
+				tr.Nodes[1].NodeType())
+			continue
+		}
+		if bq.Level != tt.level {
+			t.Errorf("TabWidth %d: Got: Level = %d, Expect: %d",
+				tt.tabWidth, bq.Level, tt.level)
+		}
+	}
+}
+
+func TestParseOptionsMaxDiagnostics(t *testing.T) {
+	// Each section title has a short underline.
+	var input string
+	for num := 0; num < 10; num++ {
+		input += "Title " + strconv.Itoa(num) + "\n=====\n\nText.\n\n"
+	}
+	for _, tt := range []struct {
+		max    int
+		nDiags int // The expected number of diagnostics
+	}{
+		{max: 0, nDiags: 10},
+		{max: 3, nDiags: 4},
+		{max: 10, nDiags: 10},
+	} {
+		tr, _ := ParseWithOptions("max diagnostics", input,
+			&ParseOptions{MaxDiagnostics: tt.max})
+		if len(tr.Diagnostics) != tt.nDiags ||
+			len(tr.Messages) != tt.nDiags {
+			t.Errorf("MaxDiagnostics %d: Got: len(Diagnostics) = %d, "+
+				"len(Messages) = %d, Expect: %d", tt.max,
+				len(tr.Diagnostics), len(tr.Messages), tt.nDiags)
+			continue
+		}
+		last := tr.Diagnostics[len(tr.Diagnostics)-1]
+		if suppressed := last.MessageType == infoTooManyMessages; suppressed !=
+			(tt.nDiags > tt.max && tt.max > 0) {
+			t.Errorf("MaxDiagnostics %d: Got: last diagnostic = %s",
+				tt.max, last.MessageType)
+		}
+		// The parse is completed, and the messages in the document are
+		// kept.
+		if len(tr.Nodes) != 10 {
+			t.Errorf("MaxDiagnostics %d: Got: len(Nodes) = %d, Expect: 10",
+				tt.max, len(tr.Nodes))
+		}
+		var inDoc int
+		Walk(tr.Nodes, func(n Node) bool {
+			if _, ok := n.(*SystemMessageNode); ok {
+				inDoc++
+			}
+			return true
+		})
+		if inDoc != 10 {
+			t.Errorf("MaxDiagnostics %d: Got: %d system messages in the "+
+				"document, Expect: 10", tt.max, inDoc)
+		}
+	}
+}
+
+func TestParseOptionsMaxDiagnosticsNested(t *testing.T) {
+	// Each topic contains three section titles with a short underline.
+	var input string
+	for topic := 0; topic < 2; topic++ {
+		input += ".. topic:: Topic " + strconv.Itoa(topic) + "\n\n"
+		for num := 0; num < 3; num++ {
+			input += "   Title " + strconv.Itoa(num) +
+				"\n   =====\n\n   Text.\n\n"
+		}
+	}
+	tr, _ := ParseWithOptions("max diagnostics nested", input,
+		&ParseOptions{MaxDiagnostics: 2})
+	if len(tr.Diagnostics) != 3 || len(tr.Messages) != 3 {
+		t.Fatalf("Got: len(Diagnostics) = %d, len(Messages) = %d, "+
+			"Expect: 3", len(tr.Diagnostics), len(tr.Messages))
+	}
+	var notices int
+	for _, d := range tr.Diagnostics {
+		if d.MessageType == infoTooManyMessages {
+			notices++
+		}
+	}
+	if last := tr.Diagnostics[2]; notices != 1 ||
+		last.MessageType != infoTooManyMessages {
+		t.Errorf("Got: %d notices, last diagnostic = %s, Expect: one "+
+			"notice as the last diagnostic", notices, last.MessageType)
+	}
+}
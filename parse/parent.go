@@ -0,0 +1,38 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+// Parent returns the node containing n, for example the SectionNode of a
+// paragraph in the body of a section, or the BulletListItemNode of a nested
+// list. nil is returned for the nodes of Tree.Nodes, the root of the document,
+// and for nodes that are not in the tree. The parents are recorded by the
+// first call of Parent; changes made to the tree afterwards are not reflected.
+func (t *Tree) Parent(n Node) Node {
+	if t.parents == nil {
+		t.linkParents()
+	}
+	return t.parents[n]
+}
+
+// linkParents records the parent of each node of the document, as returned by
+// children. A node that was already recorded is not visited again, so a node
+// reachable from two parents cannot cause a cycle.
+func (t *Tree) linkParents() {
+	t.parents = make(map[Node]Node)
+	var link func(parent Node, nl NodeList)
+	link = func(parent Node, nl NodeList) {
+		for _, n := range nl {
+			if n == nil {
+				continue
+			}
+			if _, ok := t.parents[n]; ok {
+				continue
+			}
+			t.parents[n] = parent
+			link(n, children(n))
+		}
+	}
+	link(nil, t.Nodes)
+}
@@ -0,0 +1,42 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+func TestTreeParent(t *testing.T) {
+	tr, _ := Parse("parent", "Section\n=======\n\n- Nested paragraph.\n")
+	var para Node
+	Walk(tr.Nodes, func(n Node) bool {
+		if p, ok := n.(*ParagraphNode); ok && p.Text == "Nested paragraph." {
+			para = p
+		}
+		return true
+	})
+	if para == nil {
+		t.Fatal("Got: no nested paragraph, Expect: a ParagraphNode")
+	}
+	expect := []NodeType{NodeBulletListItem, NodeBulletList, NodeSection}
+	n := para
+	for _, typ := range expect {
+		n = tr.Parent(n)
+		if n == nil {
+			t.Fatalf("Got: Parent = nil, Expect: %s", typ)
+		}
+		if n.NodeType() != typ {
+			t.Fatalf("Got: Parent = %s, Expect: %s", n.NodeType(), typ)
+		}
+	}
+	if n != tr.Nodes[0] {
+		t.Errorf("Got: section %p, Expect: Nodes[0] %p", n, tr.Nodes[0])
+	}
+	if p := tr.Parent(n); p != nil {
+		t.Errorf("Got: Parent of the section = %s, Expect: nil", p.NodeType())
+	}
+	if p := tr.Parent(&ParagraphNode{}); p != nil {
+		t.Errorf("Got: Parent of a node not in the tree = %s, Expect: nil",
+			p.NodeType())
+	}
+}
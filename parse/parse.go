@@ -10,6 +10,10 @@
 package parse
 
 import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
 	"code.google.com/p/go.text/unicode/norm"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/demizer/go-elog"
@@ -60,16 +64,57 @@ const (
 	infoOverlineTooShortForTitle
 	infoUnexpectedTitleOverlineOrTransition
 	infoUnderlineTooShortForTitle
+	infoLineTooLong
+	infoDuplicateImplicitTargetName
+	infoTooManyMessages
 	warningShortOverline
 	warningShortUnderline
+	warningInvalidUTF8
+	warningNoMarkdownEquivalent
+	warningInlineEmphasisStart
+	warningInlineStrongStart
+	warningInlineLiteralStart
+	warningUnreferencedSymbolFootnote
+	warningBulletListWithUnIndent
+	warningEnumListWithUnIndent
 	warningExplicitMarkupWithUnIndent
+	warningParagraphWithoutBlankLine
+	warningDefinitionListWithUnIndent
+	warningFieldListWithUnIndent
+	warningLineBlockWithoutBlankLine
+	warningBlockQuoteWithUnIndent
+	warningLiteralBlockExpected
+	warningDuplicateExplicitTargetName
 	errorInvalidSectionOrTransitionMarker
+	errorShortOverline
+	errorShortUnderline
+	errorInconsistentIndentation
+	errorInconsistentLiteralQuoting
+	errorClassDirectiveArgument
+	errorNoElementFollowingClassDirective
+	errorImageDirectiveArgument
+	errorIncludeDirectiveArgument
+	errorIncludeDirectiveParsed
+	errorTopicDirectiveArgument
+	errorSidebarDirectiveArgument
+	errorRubricDirectiveArgument
+	errorUnknownDirectiveType
+	errorDirectiveContent
+	errorInvalidSubstitutionDefinition
+	errorUnknownFootnoteReference
+	errorUnknownCitationReference
+	errorUnknownInterpretedTextRole
+	errorTransitionAtBeginning
+	errorTransitionAtEnd
+	errorAdjacentTransitions
+	errorMalformedTable
 	severeUnexpectedSectionTitle
 	severeUnexpectedSectionTitleOrTransition
 	severeIncompleteSectionTitle
 	severeMissingMatchingUnderlineForOverline
 	severeOverlineUnderlineMismatch
 	severeTitleLevelInconsistent
+	severeIncludeDirectivePath
 )
 
 var parserErrors = [...]string{
@@ -77,16 +122,57 @@ var parserErrors = [...]string{
 	"infoOverlineTooShortForTitle",
 	"infoUnexpectedTitleOverlineOrTransition",
 	"infoUnderlineTooShortForTitle",
+	"infoLineTooLong",
+	"infoDuplicateImplicitTargetName",
+	"infoTooManyMessages",
 	"warningShortOverline",
 	"warningShortUnderline",
+	"warningInvalidUTF8",
+	"warningNoMarkdownEquivalent",
+	"warningInlineEmphasisStart",
+	"warningInlineStrongStart",
+	"warningInlineLiteralStart",
+	"warningUnreferencedSymbolFootnote",
+	"warningBulletListWithUnIndent",
+	"warningEnumListWithUnIndent",
 	"warningExplicitMarkupWithUnIndent",
+	"warningParagraphWithoutBlankLine",
+	"warningDefinitionListWithUnIndent",
+	"warningFieldListWithUnIndent",
+	"warningLineBlockWithoutBlankLine",
+	"warningBlockQuoteWithUnIndent",
+	"warningLiteralBlockExpected",
+	"warningDuplicateExplicitTargetName",
 	"errorInvalidSectionOrTransitionMarker",
+	"errorShortOverline",
+	"errorShortUnderline",
+	"errorInconsistentIndentation",
+	"errorInconsistentLiteralQuoting",
+	"errorClassDirectiveArgument",
+	"errorNoElementFollowingClassDirective",
+	"errorImageDirectiveArgument",
+	"errorIncludeDirectiveArgument",
+	"errorIncludeDirectiveParsed",
+	"errorTopicDirectiveArgument",
+	"errorSidebarDirectiveArgument",
+	"errorRubricDirectiveArgument",
+	"errorUnknownDirectiveType",
+	"errorDirectiveContent",
+	"errorInvalidSubstitutionDefinition",
+	"errorUnknownFootnoteReference",
+	"errorUnknownCitationReference",
+	"errorUnknownInterpretedTextRole",
+	"errorTransitionAtBeginning",
+	"errorTransitionAtEnd",
+	"errorAdjacentTransitions",
+	"errorMalformedTable",
 	"severeUnexpectedSectionTitle",
 	"severeUnexpectedSectionTitleOrTransition",
 	"severeIncompleteSectionTitle",
 	"severeMissingMatchingUnderlineForOverline",
 	"severeOverlineUnderlineMismatch",
 	"severeTitleLevelInconsistent",
+	"severeIncludeDirectivePath",
 }
 
 // String implements Stringer and returns the parserMessage as a string. The
@@ -108,15 +194,106 @@ func (p parserMessage) Message() (s string) {
 	case infoUnderlineTooShortForTitle:
 		s = "Possible title underline, too short for the title.\n" +
 			"Treating it as ordinary text because it's so short."
-	case warningShortOverline:
+	case infoLineTooLong:
+		s = "Line exceeds the maximum line length."
+	case infoDuplicateImplicitTargetName:
+		s = "Duplicate implicit target name."
+	case infoTooManyMessages:
+		s = "Too many system messages; further messages are suppressed."
+	case warningShortOverline, errorShortOverline:
 		s = "Title overline too short."
-	case warningShortUnderline:
+	case warningShortUnderline, errorShortUnderline:
 		s = "Title underline too short."
+	case warningInvalidUTF8:
+		s = "Invalid UTF-8 byte sequence replaced with U+FFFD."
+	case warningNoMarkdownEquivalent:
+		s = "Element has no Markdown equivalent, " +
+			"it is rendered as an HTML comment."
+	case warningInlineEmphasisStart:
+		s = "Inline emphasis start-string without end-string."
+	case warningInlineStrongStart:
+		s = "Inline strong start-string without end-string."
+	case warningInlineLiteralStart:
+		s = "Inline literal start-string without end-string."
+	case warningUnreferencedSymbolFootnote:
+		s = "Auto-symbol footnote is not referenced."
+	case warningBulletListWithUnIndent:
+		s = "Bullet list ends without a blank line; " +
+			"unexpected unindent."
+	case warningEnumListWithUnIndent:
+		s = "Enumerated list ends without a blank line; " +
+			"unexpected unindent."
 	case warningExplicitMarkupWithUnIndent:
 		s = "Explicit markup ends without a blank line; " +
 			"unexpected unindent."
+	case warningParagraphWithoutBlankLine:
+		s = "Paragraph ends without a blank line; " +
+			"unexpected unindent."
+	case warningDefinitionListWithUnIndent:
+		s = "Definition list ends without a blank line; " +
+			"unexpected unindent."
+	case warningFieldListWithUnIndent:
+		s = "Field list ends without a blank line; " +
+			"unexpected unindent."
+	case warningLineBlockWithoutBlankLine:
+		s = "Line block ends without a blank line."
+	case warningBlockQuoteWithUnIndent:
+		s = "Block quote ends without a blank line; " +
+			"unexpected unindent."
+	case warningLiteralBlockExpected:
+		s = "Literal block expected; none found."
+	case warningDuplicateExplicitTargetName:
+		s = "Duplicate explicit target name."
 	case errorInvalidSectionOrTransitionMarker:
 		s = "Invalid section title or transition marker."
+	case errorInconsistentIndentation:
+		s = "Inconsistent use of tabs and spaces in indentation."
+	case errorInconsistentLiteralQuoting:
+		s = "Inconsistent literal block quoting."
+	case errorClassDirectiveArgument:
+		s = "Error in \"class\" directive:\n" +
+			"1 argument(s) required, 0 supplied."
+	case errorNoElementFollowingClassDirective:
+		s = "No suitable element following \"class\" directive."
+	case errorImageDirectiveArgument:
+		s = "Error in \"image\" directive:\n" +
+			"1 argument(s) required, 0 supplied."
+	case errorIncludeDirectiveArgument:
+		s = "Error in \"include\" directive:\n" +
+			"1 argument(s) required, 0 supplied."
+	case errorIncludeDirectiveParsed:
+		s = "Error in \"include\" directive:\n" +
+			"Only \"literal\" and \"code\" includes are supported."
+	case errorTopicDirectiveArgument:
+		s = "Error in \"topic\" directive:\n" +
+			"1 argument(s) required, 0 supplied."
+	case errorSidebarDirectiveArgument:
+		s = "Error in \"sidebar\" directive:\n" +
+			"1 argument(s) required, 0 supplied."
+	case errorRubricDirectiveArgument:
+		s = "Error in \"rubric\" directive:\n" +
+			"1 argument(s) required, 0 supplied."
+	case errorUnknownDirectiveType:
+		s = "Unknown directive type."
+	case errorDirectiveContent:
+		s = "Content block expected for the directive; none found."
+	case errorInvalidSubstitutionDefinition:
+		s = "Substitution definition empty or invalid."
+	case errorUnknownFootnoteReference:
+		s = "Footnote reference without a corresponding footnote."
+	case errorUnknownCitationReference:
+		s = "Citation reference without a corresponding citation."
+	case errorUnknownInterpretedTextRole:
+		s = "Unknown interpreted text role."
+	case errorTransitionAtBeginning:
+		s = "Document or section may not begin with a transition."
+	case errorTransitionAtEnd:
+		s = "Document may not end with a transition."
+	case errorAdjacentTransitions:
+		s = "At least one body element must separate transitions; " +
+			"adjacent transitions are not allowed."
+	case errorMalformedTable:
+		s = "Malformed table."
 	case severeUnexpectedSectionTitle:
 		s = "Unexpected section title."
 	case severeUnexpectedSectionTitleOrTransition:
@@ -129,21 +306,24 @@ func (p parserMessage) Message() (s string) {
 		s = "Title overline & underline mismatch."
 	case severeTitleLevelInconsistent:
 		s = "Title level inconsistent."
+	case severeIncludeDirectivePath:
+		s = "Problems with \"include\" directive path."
 	}
 	return
 }
 
-// Level returns the parserMessage level.
+// Level returns the parserMessage level. The level is determined by the
+// position of the parserMessage in the list of parserMessage constants, so new
+// messages must be added to the group of their level.
 func (p parserMessage) Level() (s systemMessageLevel) {
-	lvl := int(p)
 	switch {
-	case lvl > 0 && lvl <= 3:
+	case p > parserMessageNil && p <= infoTooManyMessages:
 		s = levelInfo
-	case lvl <= 6:
+	case p <= warningDuplicateExplicitTargetName:
 		s = levelWarning
-	case lvl == 7:
+	case p <= errorMalformedTable:
 		s = levelError
-	case lvl >= 8:
+	default:
 		s = levelSevere
 	}
 	return
@@ -270,20 +450,61 @@ exit:
 // Parse is the entry point for the reStructuredText parser. Errors generated
 // by the parser are returned as a NodeList.
 func Parse(name, text string) (t *Tree, errors NodeList) {
+	return ParseWithOptions(name, text, nil)
+}
+
+// ParseWithOptions is like Parse, but the behavior of the parser is configured
+// with opts. If opts is nil, the default options are used.
+func ParseWithOptions(name, text string, opts *ParseOptions) (t *Tree,
+	errors NodeList) {
+
 	t = New(name, text)
+	if opts != nil {
+		t.Options = opts
+	}
+	text, invalid := replaceInvalidUTF8(stripBOM(text))
 	if !norm.NFC.IsNormalString(text) {
 		text = norm.NFC.String(text)
 	}
 	t.Parse(text, t)
+	if len(invalid) > 0 {
+		t.invalidUTF8(invalid)
+	}
+	t.checkBody()
+	if t.Options.PromoteTitle && !t.Halted {
+		t.promoteTitle()
+	}
+	if t.Options.DocInfo && !t.Halted {
+		t.docInfo()
+	}
 	errors = t.Messages
 	return
 }
 
+// checkBody runs the passes over the parsed body of the document: the
+// indentation and line length checks, the numbering of footnotes, and, if
+// Options.ParseInline is set, the parsing of inline markup and the resolving of
+// references. The passes are skipped if the parser is halted.
+func (t *Tree) checkBody() {
+	if !t.Halted {
+		t.checkIndentation()
+		t.numberFootnotes()
+	}
+	if t.Options.ParseInline && !t.Halted {
+		t.parseInline()
+		t.resolveReferences()
+	}
+	if t.Options.MaxLineLength > 0 && !t.Halted {
+		t.checkLineLength()
+	}
+}
+
 // New returns a fresh parser tree.
 func New(name, text string) *Tree {
 	return &Tree{
 		Name:          name,
 		text:          text,
+		Options:       new(ParseOptions),
 		sectionLevels: new(sectionLevels),
 		indentWidth:   indentWidth,
 	}
@@ -301,20 +522,44 @@ const (
 // Tree contains the parser tree. The Nodes field contains the parsed nodes of
 // the input input data.
 type Tree struct {
-	Name               string    // The name of the current parser input
-	Nodes              NodeList  // The root node list
-	Messages           NodeList  // Messages generated by the parser
-	nodeTarget         *NodeList // Used to append nodes to a target NodeList
-	text               string    // The input text
+	Name               string               // The name of the current parser input
+	Nodes              NodeList             // The root node list
+	Messages           NodeList             // Messages generated by the parser
+	Diagnostics        []*Diagnostic        // Positions of the Messages
+	Options            *ParseOptions        // Options used by the parser
+	Fset               *FileSet             // Positions of the input offsets
+	Halted             bool                 // Parsing stopped at Options.HaltLevel
+	Title              *TitleNode           // The document title if promoted
+	Subtitle           *TitleNode           // The document subtitle if promoted
+	FootnoteReferences []*FootnoteReference // Resolved footnote references
+	Names              map[string]Node      // Nodes by reference name, see addName
+	nodeTarget         *NodeList            // Used to append nodes to a target NodeList
+	text               string               // The input text
 	lex                *lexer
 	token              [9]*item
-	sectionLevels      *sectionLevels // Encountered section levels
-	sections           []*SectionNode // Pointers to encountered sections
-	id                 int            // Consecutive id of the node in the tree
+	tokenLog           []*item         // Tokens received since the first mark
+	tokenNext          int             // Index in tokenLog of the next token
+	marks              int             // Marks not yet reset or released
+	sectionLevels      *sectionLevels  // Encountered section levels
+	titleNames         map[string]bool // Implicit target names of the titles
+	id                 int             // Consecutive id of the node in the tree
 	indentWidth        int
 	indentLevel        int
 	openDefinitionList *NodeList
 	openBulletList     *NodeList
+	bulletListTarget   *NodeList      // The NodeList of the open bullet list
+	openEnumList       *EnumListNode  // The open enumerated list
+	enumListTarget     *NodeList      // The NodeList of the open enumerated list
+	enumAffix          string         // The affix of the open enumerated list
+	enumOrdinal        int            // The ordinal of the last enumerator
+	pendingClasses     []string       // Classes of a class directive
+	pendingClassItem   *item          // The class directive
+	pendingClassTarget *NodeList      // The NodeList of the class directive
+	indentedLevels     *sectionLevels // Section levels of a block quote
+	indentedTarget     *NodeList      // The NodeList of the block quote
+	suppressed         bool           // Options.MaxDiagnostics was reached
+	parents            map[Node]Node  // The parents of the nodes
+	dedentedLines      map[Line]bool  // Lines checked by dedentBlock
 }
 
 // startParse initializes the parser, using the lexer.
@@ -326,14 +571,22 @@ func (t *Tree) startParse(lex *lexer) {
 // returned on success or failure. Users of the Parse package should use the
 // Top level Parse function.
 func (t *Tree) Parse(text string, treeSet *Tree) (tree *Tree) {
-	t.startParse(lex(t.Name, text))
 	t.text = text
+	t.Fset = newFileSet(t.source(), text)
+	if lineIsBlank(text) {
+		// The lexer emits space tokens for lines of whitespace that
+		// are not followed by a newline, a document of only whitespace
+		// has no elements.
+		return t
+	}
+	t.startParse(lexWithOptions(t.Name, text, t.Options.AdornmentChars,
+		t.tabWidth()))
 	t.parse(treeSet)
 	return t
 }
 
 // parse is where items are retrieved from the parser and dispatched according
-// to the itemElement type.
+// to the ElementType.
 func (t *Tree) parse(tree *Tree) {
 
 	t.nodeTarget = &t.Nodes
@@ -344,33 +597,58 @@ func (t *Tree) parse(tree *Tree) {
 		token := t.next(1)
 		log.Infof("\nParser got token: %#+v\n\n", token)
 
+		// A field marker or a line block marker begins a field list
+		// or a line block, whether the lexer found a paragraph or a
+		// definition term.
+		marked := (token.Type == itemParagraph ||
+			token.Type == itemDefinitionTerm) && isMarkedBlock(token.Text)
+
 		// FIXME: Hackish. Need to find a better way...
 		if t.indentLevel > 0 && token.StartPosition == 1 &&
 			token.Type != itemSpace && token.Type != itemBlankLine &&
-			token.Type != itemDefinitionTerm {
+			(token.Type != itemDefinitionTerm || marked) {
 			t.indentLevel = 0
 			t.openDefinitionList = nil
 			t.nodeTarget = &t.Nodes
+			t.indentedLevels = nil
 		}
 
+		t.checkBlankLine(token)
+
 		switch token.Type {
 		case itemParagraph:
-			n = t.paragraph(token)
+			if marked {
+				n = t.markedBlock(token)
+				break
+			}
+			n = t.paragraphBlock(token)
 		case itemTransition:
-			n = newTransition(token, &t.id)
+			n = t.transition(token)
 		case itemCommentMark:
+			if token.StartPosition == 1 {
+				// Explicit markup at the left margin, like an
+				// empty comment, ends the open lists.
+				t.openBulletList = nil
+				t.openDefinitionList = nil
+			}
 			n = t.comment(token)
-		case itemSectionAdornment:
-			n = t.section(token)
-		case itemEnumListArabic:
-			n = t.enumList(token)
-			// FIXME: This is only until enumerated list are
-			// properly implemented.
 			if n == nil {
+				// A class directive, the classes are added to
+				// the next element.
 				continue
 			}
+		case itemSectionAdornment:
+			n = t.section(token)
+		case itemEnumListArabic:
+			// The enumerated list and its items are added to the
+			// NodeList by enumList.
+			t.enumList(token)
+			continue
 		case itemSpace:
-			if t.peekBack(1).Type == itemBlankLine && t.indentLevel == 0 {
+			// An indented block at the beginning of the input is a
+			// block quote.
+			if b := t.peekBack(1); (b == nil || b.Type == itemBlankLine) &&
+				t.indentLevel == 0 {
 				n = t.blockquote(token)
 			}
 			if n == nil {
@@ -380,13 +658,22 @@ func (t *Tree) parse(tree *Tree) {
 				// nodeTarget below.
 				continue
 			}
-		case itemTitle, itemBlankLine:
+		case itemBlankLine:
+			if !t.Options.KeepBlankLines {
+				continue
+			}
+			n = newBlankLine(token, &t.id)
+		case itemTitle:
 			// itemTitle is consumed when evaluating
 			// itemSectionAdornment
 			continue
 		case itemBlockQuote:
 			n = t.blockquote(token)
 		case itemDefinitionTerm:
+			if marked {
+				n = t.markedBlock(token)
+				break
+			}
 			if t.openDefinitionList == nil && t.indentLevel == 0 {
 				n = t.definitionList(token)
 				t.openDefinitionList = &n.(*DefinitionListNode).NodeList
@@ -401,30 +688,138 @@ func (t *Tree) parse(tree *Tree) {
 			var nn Node
 			if t.openBulletList == nil && t.indentLevel == 0 {
 				nn = t.bulletList(token)
+				t.applyPendingClasses(nn)
 				t.nodeTarget.append(nn.(Node))
 				t.openBulletList = &nn.(*BulletListNode).NodeList
+				t.bulletListTarget = t.nodeTarget
 			}
-			n = t.bulletListItem(token)
 			t.nodeTarget = t.openBulletList
+			n = t.bulletListItem(token)
 			t.indentLevel++
 		}
 
+		t.applyPendingClasses(n.(Node))
 		t.nodeTarget.append(n.(Node))
 		// Set the loop to append items to the NodeList of the new
 		// section
 		switch n.(Node).NodeType() {
 		case NodeSection:
 			t.nodeTarget = &n.(*SectionNode).NodeList
+			// A section title ends the open lists.
+			t.openBulletList = nil
+			t.openEnumList = nil
+			t.openDefinitionList = nil
 		case NodeBlockQuote:
 			t.nodeTarget = &n.(*BlockQuoteNode).NodeList
 		case NodeDefinitionListItem:
 			t.nodeTarget = &n.(*DefinitionListItemNode).Definition.NodeList
 		case NodeBulletListItem:
 			t.nodeTarget = &n.(*BulletListItemNode).NodeList
+		case NodeSystemMessage:
+			if n.(*SystemMessageNode).Severity == levelSevere {
+				t.recover()
+			}
 		}
+		if t.Halted {
+			t.halt()
+			return
+		}
+	}
+
+	t.pendingClassesAtEnd()
+	t.transitionAtEnd()
+}
+
+// halt is used when a system message at or above Options.HaltLevel has been
+// generated. The remaining tokens are received from the lexer and discarded.
+func (t *Tree) halt() {
+	log.Debugln("Parser halted")
+	for p := t.peek(1); p != nil && p.Type != itemEOF; p = t.peek(1) {
+		t.next(1)
 	}
 }
 
+// recover is used after a severe system message to skip any tokens of the
+// malformed construct that were not consumed while generating the message.
+// Tokens are skipped up to the next blank line or the end of the line of the
+// last consumed token, so that parsing resumes at block level with the next
+// body element. The nodeTarget is not changed, so the following elements are
+// added to the same NodeList as the system message.
+func (t *Tree) recover() {
+	if t.token[zed] == nil {
+		return
+	}
+	last := t.token[zed].Line
+	for {
+		p := t.peek(1)
+		if p == nil || p.Type == itemBlankLine || p.Type == itemEOF ||
+			p.Line > last {
+			return
+		}
+		log.Debugf("Recovery skipped token: %#+v\n", p)
+		t.next(1)
+	}
+}
+
+// nextToken returns the next token of the token stream. While a mark is
+// outstanding, the tokens received from the lexer are kept in Tree.tokenLog, so
+// the tokens read again after a reset are taken from the log. The log is
+// dropped once no mark is outstanding and its tokens have been read again.
+func (t *Tree) nextToken() *item {
+	if t.tokenNext < len(t.tokenLog) {
+		t.tokenNext++
+		i := t.tokenLog[t.tokenNext-1]
+		t.trimTokenLog()
+		return i
+	}
+	i := t.lex.nextItem()
+	if i != nil && t.marks > 0 {
+		t.tokenLog = append(t.tokenLog, i)
+		t.tokenNext++
+	}
+	return i
+}
+
+// trimTokenLog drops Tree.tokenLog if no mark is outstanding and all tokens of
+// the log have been read.
+func (t *Tree) trimTokenLog() {
+	if t.marks == 0 && t.tokenNext == len(t.tokenLog) {
+		t.tokenLog, t.tokenNext = nil, 0
+	}
+}
+
+// tokenMark is a position in the token stream returned by Tree.mark.
+type tokenMark struct {
+	token [9]*item // The token buffer at the mark
+	next  int      // Index in Tree.tokenLog of the next token
+}
+
+// mark returns the current position in the token stream. The position can be
+// restored with reset, so a construct can be parsed speculatively and the
+// tokens it consumed can be read again if it fails. Every mark must be ended
+// with reset or release, the tokens are recorded until then.
+func (t *Tree) mark() tokenMark {
+	t.marks++
+	return tokenMark{token: t.token, next: t.tokenNext}
+}
+
+// reset restores the position in the token stream saved by mark and ends the
+// mark. The tokens consumed since the mark are read again by next and peek.
+// Nodes created since the mark are not removed.
+func (t *Tree) reset(m tokenMark) {
+	t.token = m.token
+	t.tokenNext = m.next
+	t.marks--
+	t.trimTokenLog()
+}
+
+// release ends the mark m without restoring its position, after the construct
+// was parsed successfully.
+func (t *Tree) release(m tokenMark) {
+	t.marks--
+	t.trimTokenLog()
+}
+
 // backup shifts the token buffer right one position.
 func (t *Tree) backup() {
 	t.token[0] = nil
@@ -441,7 +836,7 @@ func (t *Tree) peekBack(pos int) *item {
 	return t.token[zed-pos]
 }
 
-func (t *Tree) peekBackTo(item itemElement) (tok *item) {
+func (t *Tree) peekBackTo(item ElementType) (tok *item) {
 	for i := zed - 1; i >= 0; i-- {
 		if t.token[i] != nil && t.token[i].Type == item {
 			tok = t.token[i]
@@ -468,16 +863,16 @@ func (t *Tree) peek(pos int) *item {
 				continue
 			}
 			log.Debugln("Getting next item")
-			t.token[zed+i] = t.lex.nextItem()
+			t.token[zed+i] = t.nextToken()
 			nItem = t.token[zed+i]
 		}
 	}
 	return nItem
 }
 
-// peekSkip looks ahead one position skipiing a specified itemElement. If that
+// peekSkip looks ahead one position skipiing a specified ElementType. If that
 // element is found, a pointer is returned, otherwise nil is returned.
-func (t *Tree) peekSkip(iSkip itemElement) *item {
+func (t *Tree) peekSkip(iSkip ElementType) *item {
 	var nItem *item
 	count := 1
 	for {
@@ -503,7 +898,7 @@ func (t *Tree) next(pos int) *item {
 		t.token[x+1] = nil
 	}
 	if t.token[zed] == nil && t.lex != nil {
-		t.token[zed] = t.lex.nextItem()
+		t.token[zed] = t.nextToken()
 	}
 	pos--
 	if pos > 0 {
@@ -522,6 +917,13 @@ func (t *Tree) clearTokens(begin, end int) {
 // section is responsible for parsing the title, overline, and underline tokens
 // returned from the parser. If there are errors parsing these elements, than a
 // systemMessage is generated and added to Tree.Nodes.
+//
+// The system messages are placed like docutils places them. A message about a
+// title that can not be parsed replaces the section in the NodeList of the
+// parent. The messages about a section that was parsed, a short adornment and
+// then a duplicate title, are the first nodes of the NodeList of the section,
+// before the body of the section. These messages are numbered after the title
+// and the adornments, so the ids of the nodes follow the document order.
 func (t *Tree) section(i *item) Node {
 	var overAdorn, indent, title, underAdorn *item
 
@@ -543,6 +945,9 @@ func (t *Tree) section(i *item) Node {
 			}
 			return t.systemMessage(infoOverlineTooShortForTitle)
 		} else if pBack != nil && pBack.Type == itemSpace {
+			if n := t.indentedSection(i); n != nil {
+				return n
+			}
 			// Indented section (error)
 			// The section title has an indented overline
 			m := severeUnexpectedSectionTitleOrTransition
@@ -573,11 +978,14 @@ func (t *Tree) section(i *item) Node {
 		if pBack.Type == itemSpace {
 			pBack := t.peekBack(2)
 			if pBack != nil && pBack.Type == itemTitle {
+				if n := t.indentedSection(i); n != nil {
+					return n
+				}
 				// The section underline is indented
 				m := severeUnexpectedSectionTitle
 				return t.systemMessage(m)
 			}
-		} else if tZedLen < 3 && tZedLen != pBack.Length {
+		} else if tZedLen < 3 && tZedLen < t.titleWidth(pBack) {
 			// Short underline
 			return t.systemMessage(infoUnderlineTooShortForTitle)
 		}
@@ -590,11 +998,17 @@ func (t *Tree) section(i *item) Node {
 		// If a section contains an itemParagraph, it is because the
 		// underline is missing, therefore we generate an error based
 		// on what follows the itemParagraph.
-		t.next(2) // Move the token buffer past the error tokens
 		if tZedLen < 3 && tZedLen != pFor.Length {
+			t.next(2)
 			t.backup()
 			return t.systemMessage(infoOverlineTooShortForTitle)
-		} else if p := t.peek(1); p != nil && p.Type == itemBlankLine {
+		}
+		// Move the token buffer to the title text, past the error
+		// tokens.
+		for t.token[zed] != pFor {
+			t.next(1)
+		}
+		if p := t.peek(1); p != nil && p.Type == itemBlankLine {
 			m := severeMissingMatchingUnderlineForOverline
 			return t.systemMessage(m)
 		}
@@ -642,22 +1056,203 @@ func (t *Tree) section(i *item) Node {
 
 	// The following checks have to be made after the SectionNode has been
 	// initialized so that any parserMessages can be appended to the
-	// SectionNode.NodeList.
-	oLen := title.Length
+	// SectionNode.NodeList, and are numbered after the nodes of the
+	// section title.
+	// The width of the title is its display width, wide characters use
+	// two columns.
+	tWidth := t.titleWidth(title)
+	oLen := tWidth
 	if indent != nil {
-		oLen = indent.Length + title.Length
+		oLen = lineIndent(indent.Text, t.tabWidth()) + tWidth
 	}
 
 	if overAdorn != nil && oLen > overAdorn.Length {
 		m := warningShortOverline
+		if t.Options.StrictAdornments {
+			m = errorShortOverline
+		}
 		sec.NodeList = append(sec.NodeList, t.systemMessage(m))
-	} else if overAdorn == nil && title.Length != underAdorn.Length {
+	} else if overAdorn == nil && tWidth > underAdorn.Length {
 		m := warningShortUnderline
+		if t.Options.StrictAdornments {
+			m = errorShortUnderline
+		}
 		sec.NodeList = append(sec.NodeList, t.systemMessage(m))
 	}
+
+	// Section titles are implicit targets, so a title that was already used
+	// by a previous section is reported.
+	name := titleName(sec.Title.Text)
+	if t.titleNames[name] {
+		sec.NodeList = append(sec.NodeList,
+			t.duplicateNameMessage(sec.Title))
+	}
+	if t.titleNames == nil {
+		t.titleNames = make(map[string]bool)
+	}
+	t.titleNames[name] = true
+
 	return sec
 }
 
+// indentedSection returns the section with the indented adornment i when
+// Options.IndentedSections is set, and the adornments and the title of the
+// section begin in the same column. The section is added to the levels of the
+// sections in the current block quote. nil is returned if the section can not
+// be parsed as an indented section, the caller then reports the unexpected
+// section title.
+func (t *Tree) indentedSection(i *item) Node {
+	if !t.Options.IndentedSections || t.indentLevel == 0 {
+		return nil
+	}
+	var overAdorn, title, underAdorn *item
+	if p := t.peek(2); p != nil && p.Type == itemTitle {
+		overAdorn, title, underAdorn = i, p, t.peek(4)
+		if t.peek(1).Type != itemSpace || underAdorn == nil ||
+			underAdorn.Type != itemSectionAdornment ||
+			underAdorn.Text != overAdorn.Text ||
+			underAdorn.StartPosition != i.StartPosition {
+			return nil
+		}
+	} else {
+		title, underAdorn = t.peekBack(2), i
+	}
+	if title.StartPosition != i.StartPosition {
+		return nil
+	}
+	if overAdorn != nil {
+		t.next(4)
+	}
+
+	if t.indentedLevels == nil {
+		t.indentedLevels = new(sectionLevels)
+		t.indentedTarget = t.nodeTarget
+	}
+	undoID := t.id
+	sec := newSection(title, overAdorn, underAdorn, nil, &t.id)
+	if msg := t.indentedLevels.Add(sec); msg != parserMessageNil {
+		t.id = undoID
+		return t.systemMessage(severeTitleLevelInconsistent)
+	}
+	if sec.Level == 1 {
+		t.nodeTarget = t.indentedTarget
+	} else {
+		lSec := t.indentedLevels.LastSectionByLevel(sec.Level - 1)
+		t.nodeTarget = &lSec.NodeList
+	}
+	return sec
+}
+
+// duplicateNameMessage returns an infoDuplicateImplicitTargetName system
+// message for the section title title. The message is added to Tree.Messages.
+func (t *Tree) duplicateNameMessage(title *TitleNode) Node {
+	s := newSystemMessage(&item{
+		Type: itemSystemMessage,
+		Line: title.Line,
+	}, infoDuplicateImplicitTargetName, &t.id)
+	msg := "Duplicate implicit target name: \"" +
+		titleName(title.Text) + "\"."
+	s.NodeList = append(s.NodeList, newParagraph(&item{
+		Text:   msg,
+		Length: len(msg),
+	}, &t.id))
+	t.addMessage(s, title.StartPosition)
+	return s
+}
+
+// titleName returns the implicit target name of the section title text. The
+// name is the normalized text of the title without inline markup, so titles
+// that differ only in markup, case, or whitespace have the same name.
+func titleName(text string) string {
+	return normalizeName(plainInline(text))
+}
+
+// normalizeName returns the reference name of text. Reference names are case
+// insensitive and whitespace is normalized to a single space.
+func normalizeName(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// transition returns a TransitionNode for the itemTransition i. If the
+// transition begins the document or a section, or directly follows another
+// transition, an error level system message is added to the current NodeList
+// before the transition.
+//
+// Transitions must be at least four characters long. A shorter marker that is
+// followed by a blank line and the same marker is an overline and underline
+// with a blank title, so a severeIncompleteSectionTitle message is returned
+// instead.
+func (t *Tree) transition(i *item) Node {
+	if i.Length < 4 {
+		bl, uLin := t.peek(1), t.peek(2)
+		if bl != nil && bl.Type == itemBlankLine && uLin != nil &&
+			uLin.Type == itemTransition && uLin.Text == i.Text {
+			t.next(2)
+			return t.systemMessage(severeIncompleteSectionTitle)
+		}
+		// A shorter marker is ordinary text, like the "::" marker
+		// of a literal block on a line of its own.
+		i.Type = itemParagraph
+		return t.paragraphBlock(i)
+	}
+	if last := lastBodyNode(*t.nodeTarget); last == nil {
+		m := errorTransitionAtBeginning
+		t.nodeTarget.append(t.itemMessage(m, i))
+	} else if last.NodeType() == NodeTransition {
+		m := errorAdjacentTransitions
+		t.nodeTarget.append(t.itemMessage(m, i))
+	}
+	return newTransition(i, &t.id)
+}
+
+// transitionAtEnd adds an errorTransitionAtEnd system message after the last
+// node of the document if it is a transition. The last node of the document
+// may be nested in the last section.
+func (t *Tree) transitionAtEnd() {
+	nl := &t.Nodes
+	for {
+		sec, ok := lastBodyNode(*nl).(*SectionNode)
+		if !ok || lastBodyNode(sec.NodeList) == nil {
+			break
+		}
+		nl = &sec.NodeList
+	}
+	tn, ok := lastBodyNode(*nl).(*TransitionNode)
+	if !ok {
+		return
+	}
+	nl.append(t.itemMessage(errorTransitionAtEnd, &item{
+		Type: itemTransition,
+		Line: tn.Line,
+	}))
+}
+
+// lastBodyNode returns the last node of nl that is not a BlankLineNode, or
+// nil if there is none.
+func lastBodyNode(nl NodeList) Node {
+	for num := len(nl) - 1; num >= 0; num-- {
+		if nl[num] != nil && nl[num].NodeType() != NodeBlankLine {
+			return nl[num]
+		}
+	}
+	return nil
+}
+
+// itemMessage returns a system message of type err for the item i. The message
+// is added to Tree.Messages.
+func (t *Tree) itemMessage(err parserMessage, i *item) Node {
+	s := newSystemMessage(&item{
+		Type: itemSystemMessage,
+		Line: i.Line,
+	}, err, &t.id)
+	s.NodeList = append(s.NodeList, newParagraph(&item{
+		Text:   err.Message(),
+		Length: len(err.Message()),
+	}, &t.id))
+	t.addMessage(s, i.StartPosition)
+	return s
+}
+
 func (t *Tree) comment(i *item) Node {
 	var n Node
 	if t.peek(1).Type == itemBlankLine {
@@ -704,11 +1299,137 @@ func (t *Tree) comment(i *item) Node {
 		} else {
 			log.Debugln("Found NodeComment")
 		}
+		t.commentBody(i, nPara)
+		// Directives, footnotes, and citations must begin on the line
+		// of the explicit markup start.
+		kind := explicitComment
+		if nPara.Line == i.Line {
+			kind = explicitMarkupKindOf(nPara.Text)
+		}
+		switch kind {
+		case explicitDirective:
+			d, _ := parseDirective(nPara.Text)
+			n = t.directive(d, i)
+			t.registerName(d, n)
+			return n
+		case explicitSubstitutionDefinition:
+			name, body := splitSubstitution(nPara.Text)
+			return t.substitutionDefinition(name, body, i)
+		case explicitFootnote:
+			label, body := splitBracketLabel(nPara.Text)
+			return t.footnote(label, body, i)
+		case explicitCitation:
+			label, body := splitBracketLabel(nPara.Text)
+			return t.citation(label, body, i)
+		case explicitHyperlinkTarget, explicitAnonymousTarget:
+			return t.target(nPara.Text, i)
+		}
+		if i.Text == "__" {
+			return t.target(nPara.Text, i)
+		}
 		n = newComment(nPara, &t.id)
 	}
 	return n
 }
 
+// directive returns the node of the directive d at the explicit markup start
+// i. Directives with a DirectiveHandler are parsed by the handler, the other
+// known directives by the parser. A system message is returned for an unknown
+// directive. nil is returned for a class directive, its classes are added to
+// the next element.
+func (t *Tree) directive(d *directive, i *item) Node {
+	if h := t.directiveHandler(d.name); h != nil {
+		return t.handleDirective(h, d, i)
+	}
+	if m := t.checkDirective(d, i); m != nil {
+		return m
+	}
+	switch d.name {
+	case "class":
+		return t.classDirective(strings.Fields(d.argument), i)
+	case "topic":
+		return t.topicDirective(d, i)
+	case "sidebar":
+		return t.sidebarDirective(d, i)
+	case "rubric":
+		return t.rubricDirective(d, i)
+	case "parsed-literal":
+		return t.parsedLiteralDirective(d, i)
+	case "compound":
+		return t.compoundDirective(d, i)
+	case "container":
+		return t.containerDirective(d, i)
+	}
+	return nil
+}
+
+// registerName adds the reference name of the :name: option of the directive
+// d to the Names of its node n, and registers n in Tree.Names with addName, so
+// the directive can be referenced like a hyperlink target. Nothing is
+// registered if the directive could not be parsed and n is a system message.
+func (t *Tree) registerName(d *directive, n Node) {
+	name := normalizeName(d.options["name"])
+	if name == "" || n == nil || n.NodeType() == NodeSystemMessage {
+		return
+	}
+	if a := n.Attrs(); a != nil {
+		a.Names = append(a.Names, name)
+	}
+	t.addName(name, n)
+}
+
+// addName registers the node n with the reference name name in Tree.Names. A
+// name that is already registered keeps its first node, and a
+// warningDuplicateExplicitTargetName message is added to Tree.Messages.
+func (t *Tree) addName(name string, n Node) {
+	if _, ok := t.Names[name]; ok {
+		msg := "Duplicate explicit target name: \"" + name + "\"."
+		s := newSystemMessage(&item{
+			Type: itemSystemMessage,
+			Line: nodeLine(n),
+		}, warningDuplicateExplicitTargetName, &t.id)
+		s.NodeList = append(s.NodeList, newParagraph(&item{
+			Text:   msg,
+			Length: len(msg),
+		}, &t.id))
+		t.addMessage(s, 0)
+		return
+	}
+	if t.Names == nil {
+		t.Names = make(map[string]Node)
+	}
+	t.Names[name] = n
+}
+
+// commentBody adds the indented blocks following the comment block nPara to
+// the text of nPara. An explicit markup block ends at the first line that is
+// not indented relative to the comment mark i, so indented blocks separated
+// from the comment by blank lines are part of the comment body and are not
+// parsed. The token buffer limits the blank lines between the blocks to two.
+func (t *Tree) commentBody(i *item, nPara *item) {
+	for {
+		blanks := 0
+		for blanks < 2 && t.peek(blanks+1).Type == itemBlankLine {
+			blanks++
+		}
+		if blanks == 0 || t.peek(blanks+1).Type != itemSpace ||
+			t.peek(blanks+1).Length < int(i.StartPosition) {
+			break
+		}
+		if z := t.peek(blanks + 2).Type; z != itemBlockQuote &&
+			z != itemParagraph {
+			break
+		}
+		t.next(blanks + 2)
+		nPara.Text += strings.Repeat("\n", blanks+1) + t.token[zed].Text
+		for t.peek(1).Type == itemSpace && t.peek(2).Type == itemParagraph {
+			t.next(2)
+			nPara.Text += "\n" + t.token[zed].Text
+		}
+	}
+	nPara.Length = len(nPara.Text)
+}
+
 // systemMessage generates a Node based on the passed parserMessage. The
 // generated message is returned as a SystemMessageNode.
 func (t *Tree) systemMessage(err parserMessage) Node {
@@ -787,7 +1508,8 @@ func (t *Tree) systemMessage(err parserMessage) Node {
 		t.token[zed].Length = infoTextLen
 		t.token[zed].Line = s.Line
 		t.backup()
-	case warningShortOverline, severeOverlineUnderlineMismatch:
+	case warningShortOverline, errorShortOverline,
+		severeOverlineUnderlineMismatch:
 		backToken = zed - 2
 		if t.peekBack(2).Type == itemSpace {
 			backToken = zed - 3
@@ -801,7 +1523,8 @@ func (t *Tree) systemMessage(err parserMessage) Node {
 			underLine
 		s.Line = t.token[backToken].Line
 		lbTextLen = len(lbText)
-	case warningShortUnderline, severeUnexpectedSectionTitle:
+	case warningShortUnderline, errorShortUnderline,
+		severeUnexpectedSectionTitle:
 		backToken = zed - 1
 		if t.peekBack(1).Type == itemSpace {
 			backToken = zed - 2
@@ -809,6 +1532,10 @@ func (t *Tree) systemMessage(err parserMessage) Node {
 		lbText = t.token[backToken].Text + "\n" + t.token[zed].Text
 		lbTextLen = len(lbText)
 		s.Line = t.token[zed-1].Line
+	case warningBulletListWithUnIndent, warningEnumListWithUnIndent:
+		if p := t.peek(1); p != nil {
+			s.Line = p.Line
+		}
 	case warningExplicitMarkupWithUnIndent:
 		s.Line = t.token[zed+1].Line
 	case errorInvalidSectionOrTransitionMarker:
@@ -817,9 +1544,15 @@ func (t *Tree) systemMessage(err parserMessage) Node {
 		lbTextLen = len(lbText)
 	case severeIncompleteSectionTitle,
 		severeMissingMatchingUnderlineForOverline:
-		lbText = t.token[zed-2].Text + "\n" +
-			t.token[zed-1].Text + t.token[zed].Text
-		s.Line = t.token[zed-2].Line
+		if over := t.token[zed-1]; over.Type == itemSectionAdornment {
+			// The title text is not inset
+			lbText = over.Text + "\n" + t.token[zed].Text
+			s.Line = over.Line
+		} else {
+			lbText = t.token[zed-2].Text + "\n" +
+				t.token[zed-1].Text + t.token[zed].Text
+			s.Line = t.token[zed-2].Line
+		}
 		lbTextLen = len(lbText)
 	case severeUnexpectedSectionTitleOrTransition:
 		lbText = t.token[zed].Text
@@ -847,32 +1580,167 @@ func (t *Tree) systemMessage(err parserMessage) Node {
 		s.NodeList = append(s.NodeList, lb)
 	}
 
-	t.Messages.append(s)
+	t.addMessage(s, t.tokenPosition(s.Line))
 
 	return s
 }
 
-var lastEnum *EnumListNode
-
-func (t *Tree) enumList(i *item) (n Node) {
-	// FIXME: This function is COMPLETELY not final. It is only setup for
-	// passing section test TitleNumberedGood0100.
-	var eNode *EnumListNode
-	var affix *item
-	if lastEnum == nil {
-		t.next(1)
-		affix = t.token[zed]
-		t.next(1)
-		eNode = newEnumListNode(i, affix, &t.id)
+// enumList adds the item of the enumerator i to the open enumerated list. A
+// new EnumListNode is added to the nodeTarget if there is no open list, or if
+// the enumerator does not continue the open list because its ordinal does not
+// follow the ordinal of the previous item or its affix is different. The text
+// of the item is added to the list as a paragraph.
+//
+// FIXME: The body of the item is only the text on the line of the
+// enumerator, and only arabic numerals are supported.
+func (t *Tree) enumList(i *item) {
+	affix := &item{Text: "."}
+	if p := t.peek(1); p != nil && p.Type == itemEnumListAffix {
+		affix = t.next(1)
+	}
+	for p := t.peek(1); p != nil && p.Type == itemSpace; p = t.peek(1) {
 		t.next(1)
-		eNode.NodeList.append(newParagraph(t.token[zed], &t.id))
-	} else {
-		t.next(3)
-		lastEnum.NodeList.append(newParagraph(t.token[zed], &t.id))
-		return nil
 	}
-	lastEnum = eNode
-	return eNode
+	ordinal, _ := strconv.Atoi(i.Text)
+	if t.openEnumList != nil &&
+		(ordinal != t.enumOrdinal+1 || affix.Text != t.enumAffix) {
+		// The items are separated by a blank line, otherwise
+		// listEnd would have ended the list.
+		t.openEnumList = nil
+	}
+	if t.openEnumList == nil {
+		t.openEnumList = newEnumListNode(i, affix, &t.id)
+		t.applyPendingClasses(t.openEnumList)
+		t.nodeTarget.append(t.openEnumList)
+		t.enumListTarget = t.nodeTarget
+		t.enumAffix = affix.Text
+	}
+	t.enumOrdinal = ordinal
+	if p := t.peek(1); p != nil && p.Type == itemParagraph && p.Line == i.Line {
+		t.openEnumList.NodeList.append(newParagraph(t.next(1), &t.id))
+	}
+	end, unindent := t.listEnd(func(k int) bool {
+		p := t.peek(k)
+		if p.Type != itemEnumListArabic {
+			return false
+		}
+		affix := "."
+		if a := t.peek(k + 1); a != nil && a.Type == itemEnumListAffix {
+			affix = a.Text
+		}
+		ordinal, _ := strconv.Atoi(p.Text)
+		return affix == t.enumAffix && ordinal == t.enumOrdinal+1
+	})
+	if unindent {
+		t.enumListTarget.append(t.systemMessage(warningEnumListWithUnIndent))
+	}
+	if end {
+		t.openEnumList = nil
+	}
+}
+
+// listEnd is used after the last token of a list item to determine if the
+// list ends with the item. The list continues if the next token is a blank
+// line, or if cont reports the token at peek position k to be the next item
+// of the list. A list that ends without a blank line is reported with
+// unindent, unless it is followed by a section title.
+func (t *Tree) listEnd(cont func(k int) bool) (end, unindent bool) {
+	k := 1
+	if p := t.peek(k); p != nil && p.Type == itemSpace {
+		k++
+	}
+	p := t.peek(k)
+	switch {
+	case p == nil || p.Type == itemEOF || p.Type == itemBlankLine:
+		return false, false
+	case cont(k):
+		return false, false
+	case p.Type == itemTitle || p.Type == itemSectionAdornment:
+		return true, false
+	}
+	return true, true
+}
+
+// blankLineMessages are the system messages of body elements that must be
+// followed by a blank line, by the node type of the element. The messages are
+// the docutils messages of the elements. The bullet and enumerated lists and
+// explicit markup report a missing blank line themselves with the unexpected
+// unindent warnings.
+var blankLineMessages = map[NodeType]parserMessage{
+	NodeParagraph:      warningParagraphWithoutBlankLine,
+	NodeDefinitionList: warningDefinitionListWithUnIndent,
+	NodeFieldList:      warningFieldListWithUnIndent,
+	NodeLineBlock:      warningLineBlockWithoutBlankLine,
+	NodeBlockQuote:     warningBlockQuoteWithUnIndent,
+}
+
+// blankLineElements are the items beginning a body element that must be
+// separated from the preceding body element by a blank line.
+var blankLineElements = map[ElementType]bool{
+	itemBullet:         true,
+	itemEnumListArabic: true,
+	itemCommentMark:    true,
+}
+
+// checkBlankLine adds a warning to the current NodeList if the body element
+// beginning with item i directly follows the preceding body element on the
+// line above, without a blank line in between. A preceding paragraph must be
+// at the same indentation as i. The element is still parsed after the warning.
+func (t *Tree) checkBlankLine(i *item) {
+	if !blankLineElements[i.Type] || len(*t.nodeTarget) == 0 {
+		return
+	}
+	if b := t.peekBack(1); b == nil || b.Type == itemBlankLine ||
+		b.Type == itemSpace {
+		return
+	}
+	prev := (*t.nodeTarget)[len(*t.nodeTarget)-1]
+	msg, ok := blankLineMessages[prev.NodeType()]
+	if !ok {
+		return
+	}
+	if p, ok := prev.(*ParagraphNode); ok && (p.StartPosition !=
+		i.StartPosition ||
+		int(p.Line)+strings.Count(p.Text, "\n") != int(i.Line)-1) {
+		return
+	}
+	t.nodeTarget.append(t.systemMessage(msg))
+}
+
+// paragraphBlock returns the ParagraphNode of the paragraph beginning with
+// item i. If the paragraph introduces a literal block, the paragraph is added
+// to the current NodeList and the LiteralBlockNode is returned. A paragraph
+// that is a malformed grid table is replaced by a system message.
+func (t *Tree) paragraphBlock(i *item) Node {
+	n := t.paragraph(i)
+	if m := t.checkGridTable(n.(*ParagraphNode)); m != nil {
+		return m
+	}
+	nl := t.literalBlock(n.(*ParagraphNode))
+	if nl == nil {
+		return n
+	}
+	// The paragraph introducing the literal block is added before it.
+	for _, p := range nl[:len(nl)-1] {
+		t.applyPendingClasses(p)
+		t.nodeTarget.append(p)
+	}
+	return nl[len(nl)-1]
+}
+
+// isMarkedBlock returns true if text, the first line of a paragraph or a
+// definition term, begins a field list or a line block.
+func isMarkedBlock(text string) bool {
+	return fieldMarkerEnd(text) != -1 || isLineBlockLine(text)
+}
+
+// markedBlock returns the FieldListNode or the LineBlockNode beginning with
+// the paragraph or definition term i.
+func (t *Tree) markedBlock(i *item) Node {
+	if isLineBlockLine(i.Text) {
+		return t.lineBlock(i)
+	}
+	return t.fieldList(i)
 }
 
 func (t *Tree) paragraph(i *item) Node {
@@ -902,6 +1770,196 @@ func (t *Tree) paragraph(i *item) Node {
 	return sec
 }
 
+// minimizeLiteralMarker removes the "::" marker of a literal block from the
+// end of the text of the paragraph p. A marker preceded by whitespace is
+// removed with the whitespace, and "text::" becomes "text:". false is returned
+// if the paragraph is only the marker, the paragraph is then omitted from the
+// document.
+func minimizeLiteralMarker(p *ParagraphNode) bool {
+	text := strings.TrimSuffix(p.Text, "::")
+	switch trimmed := strings.TrimRight(text, " \t\n"); {
+	case trimmed == "":
+		return false
+	case trimmed != text:
+		p.Text = trimmed
+	default:
+		p.Text = text + ":"
+	}
+	p.Length = len(p.Text)
+	return true
+}
+
+// literalBlock returns the nodes of the paragraph p and the literal block
+// following it if p ends with "::", and skips the tokens of the block. The "::"
+// marker is minimized by minimizeLiteralMarker, a paragraph of only the marker
+// is omitted and only the LiteralBlockNode is returned. nil is returned if p
+// does not introduce a literal block. The lines of the block are kept verbatim,
+// including their trailing whitespace, only the common indentation is removed.
+// If no indented block follows p, the block can be a quoted literal block, see
+// quotedLiteralLines. The quote characters are kept in the text of the block,
+// and an errorInconsistentLiteralQuoting system message follows the block if it
+// ends at a line with another quote character. If no block follows p, the
+// marker is minimized and a warningLiteralBlockExpected system message is
+// returned in place of the block.
+func (t *Tree) literalBlock(p *ParagraphNode) NodeList {
+	if !strings.HasSuffix(p.Text, "::") {
+		return nil
+	}
+	lines := strings.Split(t.text, "\n")
+	first := int(p.Line) - 1 + strings.Count(p.Text, "\n")
+	if first < 0 || first >= len(lines) {
+		return nil
+	}
+	paraIndent := lineIndent(lines[first], t.tabWidth())
+	start, end := -1, -1
+	for num := first + 1; num < len(lines); num++ {
+		if lineIsBlank(lines[num]) {
+			continue
+		}
+		if lineIndent(lines[num], t.tabWidth()) <= paraIndent {
+			break
+		}
+		if start == -1 {
+			start = num
+		}
+		end = num + 1
+	}
+	quoted := false
+	if start == -1 {
+		start, end = quotedLiteralLines(lines, first, paraIndent,
+			t.tabWidth())
+		if start == -1 {
+			return t.missingLiteralBlock(p, lines, first)
+		}
+		quoted = true
+	}
+	if start == first+1 {
+		// The literal block must be separated from the paragraph by
+		// a blank line.
+		return nil
+	}
+	for t.peek(1).Type != itemEOF && t.peek(1).Line <= Line(end) {
+		t.next(1)
+	}
+	nl := t.literalMarker(p)
+	text, indent := t.dedentBlock(lines[start:end], Line(start+1))
+	nl = append(nl, newLiteralBlock(&item{
+		Type:          itemLiteralBlock,
+		Text:          text,
+		Length:        len(text),
+		Line:          Line(start + 1),
+		StartPosition: StartPosition(indent + 1),
+	}, &t.id))
+	if quoted && end < len(lines) && !lineIsBlank(lines[end]) {
+		nl = append(nl, t.positionMessage(errorInconsistentLiteralQuoting,
+			Line(end+1), 0))
+	}
+	return nl
+}
+
+// literalMarker minimizes the "::" marker of the paragraph p with
+// minimizeLiteralMarker and returns p in a NodeList. nil is returned if p is
+// only the marker, the id of the omitted paragraph is then used by the next
+// node.
+func (t *Tree) literalMarker(p *ParagraphNode) NodeList {
+	if minimizeLiteralMarker(p) {
+		return NodeList{p}
+	}
+	t.id = int(p.ID) - 1
+	return nil
+}
+
+// quotedLiteralLines returns the line indexes start and end of the quoted
+// literal block following the line index first of lines, the last line of a
+// paragraph ending with "::" and indented by indent columns. The block begins
+// after blank lines with a line of the same indentation starting with a quote
+// character, and continues with the following lines starting with the same
+// character. It ends at a blank line or at the first line that is not quoted
+// with the character. start is -1 if no quoted literal block follows.
+func quotedLiteralLines(lines []string, first, indent,
+	tabWidth int) (start, end int) {
+
+	start = first + 1
+	for start < len(lines) && lineIsBlank(lines[start]) {
+		start++
+	}
+	if start == first+1 || start == len(lines) ||
+		lineIndent(lines[start], tabWidth) != indent {
+		return -1, -1
+	}
+	quote, _ := utf8.DecodeRuneInString(strings.TrimLeft(lines[start], " \t"))
+	if !isQuoteChar(quote) {
+		return -1, -1
+	}
+	for end = start + 1; end < len(lines); end++ {
+		if lineIndent(lines[end], tabWidth) != indent ||
+			!strings.HasPrefix(strings.TrimLeft(lines[end], " \t"),
+				string(quote)) {
+			break
+		}
+	}
+	return start, end
+}
+
+// isQuoteChar returns true if r can quote the lines of a quoted literal block.
+// The quote characters are the 7-bit ASCII punctuation characters, like the
+// characters of section adornments.
+func isQuoteChar(r rune) bool {
+	for _, c := range sectionAdornments {
+		if r == c {
+			return true
+		}
+	}
+	return false
+}
+
+// missingLiteralBlock returns the nodes of the paragraph p ending with "::" on
+// the line index first of lines, when no literal block follows it. The marker
+// is minimized like the marker of a literal block, so "Text::" becomes "Text:",
+// and a warningLiteralBlockExpected system message follows the paragraph. The
+// message is on the line of the next element, or on the line after p at the end
+// of the input.
+func (t *Tree) missingLiteralBlock(p *ParagraphNode, lines []string,
+	first int) NodeList {
+
+	nl := t.literalMarker(p)
+	line := Line(first + 2)
+	for num := first + 1; num < len(lines); num++ {
+		if !lineIsBlank(lines[num]) {
+			line = Line(num + 1)
+			break
+		}
+	}
+	return append(nl, t.positionMessage(warningLiteralBlockExpected, line, 0))
+}
+
+// blockQuoteIndent returns the width of the indentation of the block quote
+// beginning on line. The body of the block quote, the lines up to the first line
+// that is not blank and is indented less than line, is dedented by dedentBlock,
+// so inconsistent indentation in the body is reported. The body of a block
+// quote nested in a block quote was already dedented with the enclosing quote
+// and is not reported again.
+func (t *Tree) blockQuoteIndent(line Line) int {
+	lines := strings.Split(t.text, "\n")
+	start := int(line) - 1
+	in := lineIndent(lines[start], t.tabWidth())
+	if t.dedentedLines[line] {
+		return in
+	}
+	end := start + 1
+	for num := end; num < len(lines); num++ {
+		if lineIsBlank(lines[num]) {
+			continue
+		}
+		if lineIndent(lines[num], t.tabWidth()) < in {
+			break
+		}
+		end = num + 1
+	}
+	_, indent := t.dedentBlock(lines[start:end], line)
+	return indent
+}
+
 func (t *Tree) blockquote(i *item) Node {
 	log.Debugln("Got type", i.Type)
 	s := i
@@ -911,16 +1969,20 @@ func (t *Tree) blockquote(i *item) Node {
 		// indent level calculation.
 		s = t.peekBackTo(itemSpace)
 	}
-	level := s.Length / t.indentWidth
+	level := lineIndent(s.Text, t.tabWidth()) / t.indentWidth
 
 	log.Debugf("t.indentLevel == level :: %d == %d\n", t.indentLevel, level)
 	if t.indentLevel == level {
+		if isMarkedBlock(i.Text) {
+			return t.markedBlock(i)
+		}
 		i.Type = itemParagraph
 		return newParagraph(i, &t.id)
 	}
 
 	if i.Type == itemSpace {
 		if t.peek(1).Type != itemBlockQuote {
+			level = t.blockQuoteIndent(i.Line) / t.indentWidth
 			t.indentLevel = level
 			return newBlockQuote(
 				&item{Type: itemBlockQuote, Line: i.Line},
@@ -943,6 +2005,7 @@ func (t *Tree) blockquote(i *item) Node {
 	if levelChanged {
 		// FIXME: Code a token ring insertion API
 		t.token[zed+1] = &n
+		level = t.blockQuoteIndent(i.Line) / t.indentWidth
 		t.indentLevel = level
 		sec = newBlockQuote(&item{Type: itemBlockQuote, Line: i.Line,
 			StartPosition: i.StartPosition, Length: i.Length}, level,
@@ -963,16 +2026,137 @@ func (t *Tree) definitionList(i *item) Node {
 	return sec
 }
 
+// definitionListItem returns the DefinitionListItemNode of the term i. The
+// classifiers following the term are split from its text, and the definition
+// is parsed into the NodeList of the DefinitionNode, so the definition can
+// contain several paragraphs and nested blocks.
 func (t *Tree) definitionListItem(i *item) Node {
-	// TODO: Check for proper indentation!
-	def := t.peek(2)
-	return newDefinitionListItem(i, def, &t.id)
+	term, classifiers := splitClassifiers(i)
+	n := newDefinitionListItem(term, classifiers, &item{Line: i.Line + 1},
+		&t.id)
+	body, indent := t.definitionBody(i)
+	n.Definition.NodeList = t.parseNested(body, i.Line+1, indent)
+	return n
+}
+
+// splitClassifiers returns the term and the classifiers of the definition list
+// term i. The classifiers follow the term and are separated from it, and from
+// each other, by a colon surrounded by spaces.
+func splitClassifiers(i *item) (term *item, classifiers []*item) {
+	parts := strings.Split(i.Text, " : ")
+	pos := i.StartPosition
+	for num, p := range parts {
+		text := strings.TrimSpace(p)
+		lead := utf8.RuneCountInString(p) -
+			utf8.RuneCountInString(strings.TrimLeft(p, " "))
+		c := &item{
+			Type:          i.Type,
+			Text:          text,
+			Length:        utf8.RuneCountInString(text),
+			Line:          i.Line,
+			StartPosition: pos + StartPosition(lead),
+		}
+		pos += StartPosition(utf8.RuneCountInString(p) + len(" : "))
+		if num == 0 {
+			term = c
+			continue
+		}
+		classifiers = append(classifiers, c)
+	}
+	return
+}
+
+// definitionBody returns the text of the definition of the term i, and skips
+// the tokens of the definition. The definition begins on the line after i,
+// and ends at the first line that is not blank and is not indented more than
+// i. The common indentation of the definition is removed, indent is its
+// width.
+func (t *Tree) definitionBody(i *item) (body string, indent int) {
+	lines := strings.Split(t.text, "\n")
+	var bodyLines []string
+	end := int(i.Line)
+	for num := end; num < len(lines); num++ {
+		if lineIsBlank(lines[num]) {
+			continue
+		}
+		if lineIndent(lines[num], t.tabWidth()) < int(i.StartPosition) {
+			break
+		}
+		bodyLines = append(bodyLines, lines[end:num+1]...)
+		end = num + 1
+	}
+	for t.peek(1).Type != itemEOF && t.peek(1).Line <= Line(end) {
+		t.next(1)
+	}
+	return t.dedentBlock(bodyLines, i.Line+1)
 }
 
 func (t *Tree) bulletList(i *item) Node {
 	return newBulletListNode(i, &t.id)
 }
 
+// bulletListItem returns the BulletListItemNode of the bullet i. The body of
+// the item is parsed into the NodeList of the item, so the item can contain
+// several paragraphs and nested blocks, like a nested list.
 func (t *Tree) bulletListItem(i *item) Node {
-	return newBulletListItemNode(i, &t.id)
+	n := newBulletListItemNode(i, &t.id)
+	body, indent := t.listItemBody(i)
+	n.NodeList = t.parseNested(body, i.Line, indent)
+	end, unindent := t.listEnd(func(k int) bool {
+		p := t.peek(k)
+		return p.Type == itemBullet && p.Text == i.Text &&
+			p.StartPosition == i.StartPosition
+	})
+	if unindent {
+		t.bulletListTarget.append(t.systemMessage(warningBulletListWithUnIndent))
+	}
+	if end {
+		t.openBulletList = nil
+	}
+	return n
+}
+
+// listItemBody returns the text of the body of the list item beginning with
+// the bullet or enumerator i, and skips the tokens of the body. The body
+// begins with the text after i, and ends at the first line that is not blank
+// and is indented less than the text after i. If there is no text after i,
+// the indentation of the next line must be greater than the indentation of i.
+// The common indentation of the body is removed, indent is its width.
+func (t *Tree) listItemBody(i *item) (body string, indent int) {
+	lines := strings.Split(t.text, "\n")
+	start := int(i.Line) - 1
+	if start < 0 || start >= len(lines) {
+		return "", 0
+	}
+	first := []rune(lines[start])
+	markEnd := int(i.StartPosition) - 1 + utf8.RuneCountInString(i.Text)
+	if markEnd > len(first) {
+		markEnd = len(first)
+	}
+	text := strings.TrimLeft(string(first[markEnd:]), " \t")
+	col := len(first) - utf8.RuneCountInString(text)
+	minIndent := col
+	if text == "" {
+		minIndent = int(i.StartPosition)
+	}
+	bodyLines := []string{strings.Repeat(" ", col) + text}
+	end := start + 1
+	for num := start + 1; num < len(lines); num++ {
+		if lineIsBlank(lines[num]) {
+			continue
+		}
+		if lineIndent(lines[num], t.tabWidth()) < minIndent {
+			break
+		}
+		if text == "" && len(bodyLines) == 1 {
+			// The first line of the body sets its indentation.
+			minIndent = lineIndent(lines[num], t.tabWidth())
+		}
+		bodyLines = append(bodyLines, lines[end:num+1]...)
+		end = num + 1
+	}
+	for t.peek(1).Type != itemEOF && t.peek(1).Line <= Line(end) {
+		t.next(1)
+	}
+	return t.dedentBlock(bodyLines, i.Line)
 }
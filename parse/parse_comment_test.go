@@ -63,6 +63,15 @@ func TestParseTwoCommentsNoBlankLineBad0001(t *testing.T) {
 	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
 }
 
+func TestParseEmptyCommentNoBlankLineBad0002(t *testing.T) {
+	// An empty comment followed by an unindented line
+	testPath := testPathFromName("00.02-empty-comment-no-blankline")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
 func TestParseCommentWithLiteralMarkGood0002(t *testing.T) {
 	// A comment ending with a literal block mark.
 	testPath := testPathFromName("00.02-comment-with-literal-mark")
@@ -161,3 +170,31 @@ func TestParseCommentTrailingBulletGood0502(t *testing.T) {
 	eNodes := test.expectNodes()
 	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
 }
+
+func TestParseCommentBodyAfterBlankLineGood0600(t *testing.T) {
+	// The indented block after a blank line is a part of the comment
+	testPath := testPathFromName("06.00-comment-body-after-blank-line")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseEmptyCommentBetweenBlockquotesGood0700(t *testing.T) {
+	// An empty comment separating two block quotes
+	testPath := testPathFromName("07.00-empty-comment-between-blockquotes")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseEmptyCommentBetweenBulletListsGood0701(t *testing.T) {
+	// An empty comment ends a bullet list, the next bullet begins a new
+	// list
+	testPath := testPathFromName("07.01-empty-comment-between-bullet-lists")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
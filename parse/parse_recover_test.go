@@ -0,0 +1,65 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+var parseRecoverTests = []struct {
+	name  string
+	input string
+}{
+	{
+		name:  "Overline and underline mismatch",
+		input: "=======\n Title\n-------\n\nParagraph.",
+	},
+	{
+		name:  "Indented section title",
+		input: "Test.\n\n    Title\n    =====\n    Paragraph.",
+	},
+	{
+		name: "Inconsistent title level without a blank line",
+		input: "Title 1\n=======\n\nTitle 2\n-------\n\n" +
+			"Title 3\n=======\n\nTitle 4\n```````\nParagraph.",
+	},
+}
+
+// lastNode returns the last node of the document, descending into the last
+// node of sections and block quotes.
+func lastNode(nl NodeList) (n Node) {
+	for len(nl) > 0 {
+		n = nl[len(nl)-1]
+		switch nn := n.(type) {
+		case *SectionNode:
+			nl = nn.NodeList
+		case *BlockQuoteNode:
+			nl = nn.NodeList
+		default:
+			return
+		}
+	}
+	return
+}
+
+func TestParseRecoverAfterSevereMessage(t *testing.T) {
+	for _, tt := range parseRecoverTests {
+		tr, errors := Parse(tt.name, tt.input)
+		if len(errors) == 0 {
+			t.Errorf("Test: %q\n\t    Got: no system messages\n\n",
+				tt.name)
+			continue
+		}
+		if sm := errors[0].(*SystemMessageNode); sm.Severity != levelSevere {
+			t.Errorf("Test: %q\n\t    "+
+				"Got: Severity = %q, Expect: %q\n\n",
+				tt.name, sm.Severity, levelSevere)
+		}
+		p, ok := lastNode(tr.Nodes).(*ParagraphNode)
+		if !ok || p.Text != "Paragraph." {
+			t.Errorf("Test: %q\n\t    "+
+				"Got: last node = %#v, Expect: %q paragraph\n\n",
+				tt.name, lastNode(tr.Nodes), "Paragraph.")
+		}
+	}
+}
@@ -6,7 +6,11 @@
 
 package parse
 
-import "testing"
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
 
 func TestParseSectionTitleGood0000(t *testing.T) {
 	// Basic title, underline, blankline, and paragraph test
@@ -335,3 +339,341 @@ func TestParseSectionTitleNumberedGood0100(t *testing.T) {
 	eNodes := test.expectNodes()
 	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
 }
+
+func TestParseSectionTitleGood0400(t *testing.T) {
+	// Tests a repeated level one section title. The second section gets an
+	// info message about the duplicate implicit target name.
+	testPath := testPathFromName("04.00-duplicate-title")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	checkParseNodes(t, eNodes, pTree.Nodes, testPath)
+}
+
+func TestParseSectionTitleGood0000Tolerant(t *testing.T) {
+	// Expected nodes with shifted positions match when positions are
+	// ignored.
+	testPath := testPathFromName("00.00-title-paragraph")
+	test := LoadParseTest(t, testPath)
+	pTree := parseTest(t, test)
+	eNodes := test.expectNodes()
+	title := eNodes[0].(map[string]interface{})["title"]
+	title.(map[string]interface{})["startPosition"] = float64(5)
+	checkParseNodesTolerant(t, eNodes, pTree.Nodes, testPath)
+}
+
+var sectionMessagePlacementTests = []struct {
+	name  string
+	input string
+	// The types and ids of the nodes of the NodeList of the last section.
+	types []NodeType
+	ids   []ID
+	// The types of the system messages of the last section, in order.
+	messages []parserMessage
+}{
+	{
+		name:     "Short underline",
+		input:    "Title\n===\n\nParagraph.",
+		types:    []NodeType{NodeSystemMessage, NodeParagraph},
+		ids:      []ID{4, 7},
+		messages: []parserMessage{warningShortUnderline},
+	},
+	{
+		name:     "Short overline",
+		input:    "===\nTitle\n===\n\nParagraph.",
+		types:    []NodeType{NodeSystemMessage, NodeParagraph},
+		ids:      []ID{5, 8},
+		messages: []parserMessage{warningShortOverline},
+	},
+	{
+		name:  "Short underline of a duplicate title",
+		input: "Title\n=====\n\nOne.\n\nTitle\n===\n\nTwo.",
+		types: []NodeType{NodeSystemMessage, NodeSystemMessage,
+			NodeParagraph},
+		ids: []ID{8, 11, 13},
+		messages: []parserMessage{warningShortUnderline,
+			infoDuplicateImplicitTargetName},
+	},
+}
+
+func TestParseSectionMessagePlacement(t *testing.T) {
+	for _, tt := range sectionMessagePlacementTests {
+		tr, _ := Parse(tt.name, tt.input)
+		sec := tr.Nodes[len(tr.Nodes)-1].(*SectionNode)
+		if len(sec.NodeList) != len(tt.types) {
+			t.Errorf("Test: %q\n\t    Got: len(NodeList) = %d, "+
+				"Expect: %d\n\n", tt.name, len(sec.NodeList),
+				len(tt.types))
+			continue
+		}
+		var messages []parserMessage
+		for num, n := range sec.NodeList {
+			if n.NodeType() != tt.types[num] ||
+				n.IDNumber() != tt.ids[num] {
+				t.Errorf("Test: %q\n\t    Got: NodeList[%d] = %s "+
+					"with id %s, Expect: %s with id %d\n\n",
+					tt.name, num, n.NodeType(), n.IDNumber(),
+					tt.types[num], tt.ids[num])
+			}
+			if sm, ok := n.(*SystemMessageNode); ok {
+				messages = append(messages, sm.MessageType)
+			}
+		}
+		if !reflect.DeepEqual(messages, tt.messages) {
+			t.Errorf("Test: %q\n\t    Got: messages = %v, "+
+				"Expect: %v\n\n", tt.name, messages, tt.messages)
+		}
+	}
+}
+
+var titleNameTests = []struct {
+	name  string
+	input string
+}{
+	{"Trailing space", "Title\n=====\n\nOne.\n\nTitle \n=====\n\nTwo."},
+	{"Inner whitespace", "A  title\n========\n\nOne.\n\nA title\n=======\n\nTwo."},
+	{"Inline markup", "*Title*\n=======\n\nOne.\n\nTitle\n=====\n\nTwo."},
+	{"Case", "Title\n=====\n\nOne.\n\nTITLE\n=====\n\nTwo."},
+}
+
+func TestParseSectionTitleName(t *testing.T) {
+	for _, tt := range titleNameTests {
+		tr, _ := Parse(tt.name, tt.input)
+		if len(tr.Nodes) != 2 {
+			t.Errorf("Test: %q\n\t    Got: len(Nodes) = %d, Expect: 2\n\n",
+				tt.name, len(tr.Nodes))
+			continue
+		}
+		first := tr.Nodes[0].(*SectionNode)
+		second := tr.Nodes[1].(*SectionNode)
+		if first.Level != second.Level {
+			t.Errorf("Test: %q\n\t    Got: Level = %d, %d, Expect: "+
+				"the same level\n\n", tt.name, first.Level,
+				second.Level)
+		}
+		sm, ok := second.NodeList[0].(*SystemMessageNode)
+		if !ok || sm.MessageType != infoDuplicateImplicitTargetName {
+			t.Errorf("Test: %q\n\t    Got: NodeList[0] = %#v, Expect: "+
+				"%s\n\n", tt.name, second.NodeList[0],
+				infoDuplicateImplicitTargetName)
+		}
+	}
+}
+
+var escapedAdornmentTests = []struct {
+	name  string
+	input string
+	text  string // Expected paragraph text
+	plain string // Expected output of RenderText
+}{
+	{"Escaped line", "\\=======", "\\=======", "=======\n"},
+	{"Escaped underline", "Title\n\\=====", "Title\n\\=====",
+		"Title\n=====\n"},
+	{"Escaped overline and underline", "\\=====\nTitle\n\\=====",
+		"\\=====\nTitle\n\\=====", "=====\nTitle\n=====\n"},
+}
+
+func TestParseSectionEscapedAdornment(t *testing.T) {
+	for _, tt := range escapedAdornmentTests {
+		tr, errors := Parse(tt.name, tt.input)
+		if len(errors) != 0 || len(tr.Nodes) != 1 {
+			t.Errorf("Test: %q\n\t    Got: len(errors) = %d, len(Nodes) "+
+				"= %d, Expect: 0, 1\n\n", tt.name, len(errors),
+				len(tr.Nodes))
+			continue
+		}
+		p, ok := tr.Nodes[0].(*ParagraphNode)
+		if !ok || p.Text != tt.text {
+			t.Errorf("Test: %q\n\t    Got: Nodes[0] = %#v, Expect: "+
+				"paragraph %q\n\n", tt.name, tr.Nodes[0], tt.text)
+			continue
+		}
+		var buf bytes.Buffer
+		if err := tr.RenderText(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.plain {
+			t.Errorf("Test: %q\n\t    Got: RenderText() = %q, Expect: "+
+				"%q\n\n", tt.name, buf.String(), tt.plain)
+		}
+	}
+}
+
+var overlinedSiblingTests = []struct {
+	name   string
+	input  string
+	bodies []int // Expected length of the NodeList of each section
+}{
+	{"No bodies", "=====\nOne\n=====\n=====\nTwo\n=====\n", []int{0, 0}},
+	{"Blank line between", "=====\nOne\n=====\n\n=====\nTwo\n=====\n",
+		[]int{0, 0}},
+	{"Paragraph bodies",
+		"=====\nOne\n=====\n\nBody.\n\n=====\nTwo\n=====\n\nMore.",
+		[]int{1, 1}},
+	{"Subsection in the first",
+		"=====\nOne\n=====\n\n-----\nSub\n-----\n\n=====\nTwo\n=====\n\nMore.",
+		[]int{1, 1}},
+}
+
+func TestParseSectionOverlinedSiblings(t *testing.T) {
+	for _, tt := range overlinedSiblingTests {
+		tr, errors := Parse(tt.name, tt.input)
+		if len(errors) != 0 || len(tr.Nodes) != 2 {
+			t.Errorf("Test: %q\n\t    Got: len(errors) = %d, len(Nodes) "+
+				"= %d, Expect: 0, 2\n\n", tt.name, len(errors),
+				len(tr.Nodes))
+			continue
+		}
+		for num, title := range []string{"One", "Two"} {
+			sec, ok := tr.Nodes[num].(*SectionNode)
+			if !ok {
+				t.Errorf("Test: %q\n\t    Got: Nodes[%d] = %s, Expect: "+
+					"NodeSection\n\n", tt.name, num,
+					tr.Nodes[num].NodeType())
+				continue
+			}
+			if sec.Title.Text != title || sec.Level != 1 ||
+				sec.OverLine == nil {
+				t.Errorf("Test: %q\n\t    Got: title %q at level %d, "+
+					"Expect: overlined title %q at level 1\n\n",
+					tt.name, sec.Title.Text, sec.Level, title)
+			}
+			if len(sec.NodeList) != tt.bodies[num] {
+				t.Errorf("Test: %q\n\t    Got: len(Nodes[%d].NodeList) "+
+					"= %d, Expect: %d\n\n", tt.name, num,
+					len(sec.NodeList), tt.bodies[num])
+			}
+		}
+	}
+}
+
+var singleCharTitleTests = []struct {
+	name     string
+	input    string
+	overline bool
+	body     int // Expected length of the NodeList of the section
+}{
+	{"Underlined", "A\n=\n", false, 0},
+	{"Underlined with body", "A\n=\n\nBody.\n", false, 1},
+	{"Overlined", "=\nA\n=\n", true, 0},
+	{"Overlined with body", "=\nA\n=\n\nBody.\n", true, 1},
+}
+
+func TestParseSectionSingleCharTitle(t *testing.T) {
+	for _, tt := range singleCharTitleTests {
+		tr, errors := Parse(tt.name, tt.input)
+		if len(errors) != 0 || len(tr.Nodes) != 1 {
+			t.Errorf("Test: %q\n\t    Got: len(errors) = %d, len(Nodes) "+
+				"= %d, Expect: 0, 1\n\n", tt.name, len(errors),
+				len(tr.Nodes))
+			continue
+		}
+		sec, ok := tr.Nodes[0].(*SectionNode)
+		if !ok {
+			t.Errorf("Test: %q\n\t    Got: Nodes[0] = %s, Expect: "+
+				"NodeSection\n\n", tt.name, tr.Nodes[0].NodeType())
+			continue
+		}
+		if sec.Title.Text != "A" || (sec.OverLine != nil) != tt.overline {
+			t.Errorf("Test: %q\n\t    Got: title %q, overline = %t, "+
+				"Expect: title \"A\", overline = %t\n\n", tt.name,
+				sec.Title.Text, sec.OverLine != nil, tt.overline)
+		}
+		if len(sec.NodeList) != tt.body {
+			t.Errorf("Test: %q\n\t    Got: len(NodeList) = %d, "+
+				"Expect: %d\n\n", tt.name, len(sec.NodeList), tt.body)
+		}
+	}
+}
+
+func TestParseSingleCharAdornmentParagraph(t *testing.T) {
+	// A lone adornment rune between blank lines is too short to be a
+	// transition and has no title to adorn.
+	tr, errors := Parse("Lone adornment", "Para\n\n=\n\nPara\n")
+	if len(errors) != 0 || len(tr.Nodes) != 3 {
+		t.Fatalf("Got: len(errors) = %d, len(Nodes) = %d, Expect: 0, 3",
+			len(errors), len(tr.Nodes))
+	}
+	if p, ok := tr.Nodes[1].(*ParagraphNode); !ok || p.Text != "=" {
+		t.Errorf("Got: Nodes[1] = %#v, Expect: paragraph \"=\"",
+			tr.Nodes[1])
+	}
+}
+
+var strayAdornmentTests = []struct {
+	name    string
+	input   string
+	nType   NodeType      // Type of the last node of the tree
+	message parserMessage // The expected message, if any
+}{
+	{"Short underline", "Title\n====\n", NodeSection, warningShortUnderline},
+	{"Underline longer than title", "A\n==\n", NodeSection,
+		parserMessageNil},
+	{"Underline too short for title", "Title\n=\n", NodeParagraph,
+		infoUnderlineTooShortForTitle},
+	{"Adornment rune in paragraph", "Para\ntext\n=\n", NodeParagraph,
+		parserMessageNil},
+	{"Adornment runes in paragraph", "Para\ntext\n--\nmore\n",
+		NodeParagraph, parserMessageNil},
+}
+
+func TestParseSectionStrayAdornment(t *testing.T) {
+	for _, tt := range strayAdornmentTests {
+		tr, errors := Parse(tt.name, tt.input)
+		if n := tr.Nodes[len(tr.Nodes)-1]; n.NodeType() != tt.nType {
+			t.Errorf("Test: %q\n\t    Got: last node = %s, Expect: %s\n\n",
+				tt.name, n.NodeType(), tt.nType)
+		}
+		var got parserMessage
+		if len(errors) > 0 {
+			got = errors[0].(*SystemMessageNode).MessageType
+		}
+		if len(errors) > 1 || got != tt.message {
+			t.Errorf("Test: %q\n\t    Got: %d messages, first = %s, "+
+				"Expect: %s\n\n", tt.name, len(errors), got,
+				tt.message)
+		}
+	}
+	tr, _ := Parse("Paragraph text", "Para\ntext\n=\n")
+	if p := tr.Nodes[0].(*ParagraphNode); p.Text != "Para\ntext\n=" {
+		t.Errorf("Got: paragraph text = %q, Expect: %q", p.Text,
+			"Para\ntext\n=")
+	}
+}
+
+var adornmentTextTests = []struct {
+	name      string
+	input     string
+	overline  string
+	underline string
+}{
+	{"Underline", "Title\n=====\n", "", "====="},
+	{"Long underline", "Title\n--------\n", "", "--------"},
+	{"Overline", "~~~~~~~\n Title\n~~~~~~~\n", "~~~~~~~", "~~~~~~~"},
+	{"Trailing whitespace", "Title\n=====   \n\nBody.\n", "", "====="},
+	{"Different trailing whitespace",
+		"=======  \n Title\n=======\t\n\nBody.\n", "=======", "======="},
+}
+
+func TestParseSectionAdornmentText(t *testing.T) {
+	for _, tt := range adornmentTextTests {
+		tr, errors := Parse(tt.name, tt.input)
+		if len(errors) != 0 || len(tr.Nodes) == 0 {
+			t.Errorf("Test: %q\n\t    Got: len(errors) = %d, len(Nodes) "+
+				"= %d, Expect: 0, 1\n\n", tt.name, len(errors),
+				len(tr.Nodes))
+			continue
+		}
+		sec, ok := tr.Nodes[0].(*SectionNode)
+		if !ok {
+			t.Errorf("Test: %q\n\t    Got: Nodes[0] = %s, Expect: "+
+				"NodeSection\n\n", tt.name, tr.Nodes[0].NodeType())
+			continue
+		}
+		if o, u := sec.OverlineText(), sec.UnderlineText(); o != tt.overline ||
+			u != tt.underline {
+			t.Errorf("Test: %q\n\t    Got: %q, %q, Expect: %q, %q\n\n",
+				tt.name, o, u, tt.overline, tt.underline)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+var checkTransitionsTests = []struct {
+	name     string
+	input    string
+	messages []parserMessage // Expected messages in order
+	lines    []Line          // Expected line of each message
+}{
+	{
+		name:     "Transition at the beginning of the document",
+		input:    "----------\n\nParagraph.",
+		messages: []parserMessage{errorTransitionAtBeginning},
+		lines:    []Line{1},
+	},
+	{
+		name:     "Transition at the end of the document",
+		input:    "Paragraph.\n\n----------",
+		messages: []parserMessage{errorTransitionAtEnd},
+		lines:    []Line{3},
+	},
+	{
+		name:     "Adjacent transitions",
+		input:    "Paragraph.\n\n----------\n\n----------\n\nParagraph.",
+		messages: []parserMessage{errorAdjacentTransitions},
+		lines:    []Line{5},
+	},
+	{
+		name:     "Transition at the beginning of a section",
+		input:    "Title\n=====\n\n----------\n\nParagraph.",
+		messages: []parserMessage{errorTransitionAtBeginning},
+		lines:    []Line{4},
+	},
+	{
+		name:  "Transition between paragraphs",
+		input: "Paragraph.\n\n----------\n\nParagraph.",
+	},
+}
+
+func TestTreeCheckTransitions(t *testing.T) {
+	for _, tt := range checkTransitionsTests {
+		_, errors := Parse(tt.name, tt.input)
+		if len(errors) != len(tt.messages) {
+			t.Errorf("Test: %q\n\t    "+
+				"Got: len(errors) = %d, Expect: %d\n\n",
+				tt.name, len(errors), len(tt.messages))
+			continue
+		}
+		for num, msg := range tt.messages {
+			sm := errors[num].(*SystemMessageNode)
+			if sm.MessageType != msg {
+				t.Errorf("Test: %q\n\t    "+
+					"Got: MessageType = %q, Expect: %q\n\n",
+					tt.name, sm.MessageType, msg)
+			}
+			if sm.Severity != levelError {
+				t.Errorf("Test: %q\n\t    "+
+					"Got: Severity = %q, Expect: %q\n\n",
+					tt.name, sm.Severity, levelError)
+			}
+			if sm.Line != tt.lines[num] {
+				t.Errorf("Test: %q\n\t    "+
+					"Got: Line = %d, Expect: %d\n\n",
+					tt.name, sm.Line, tt.lines[num])
+			}
+		}
+	}
+}
+
+func TestTreeCheckTransitionsMessagePlacement(t *testing.T) {
+	name := "Adjacent transitions message placement"
+	tr, _ := Parse(name, "Paragraph.\n\n----------\n\n----------\n\nParagraph.")
+	expect := []NodeType{NodeParagraph, NodeTransition, NodeSystemMessage,
+		NodeTransition, NodeParagraph}
+	if len(tr.Nodes) != len(expect) {
+		t.Fatalf("Test: %q\n\t    Got: len(Nodes) = %d, Expect: %d\n\n",
+			name, len(tr.Nodes), len(expect))
+	}
+	for num, n := range tr.Nodes {
+		if n.NodeType() != expect[num] {
+			t.Errorf("Test: %q\n\t    "+
+				"Got: Nodes[%d].NodeType() = %q, Expect: %q\n\n",
+				name, num, n.NodeType(), expect[num])
+		}
+	}
+}
+
+func TestParseShortAdornmentsWithBlankTitle(t *testing.T) {
+	name := "Short overline and underline with a blank title"
+	tr, errors := Parse(name, "===\n\n===\n")
+	if len(errors) != 1 {
+		t.Fatalf("Test: %q\n\t    Got: len(errors) = %d, Expect: %d\n\n",
+			name, len(errors), 1)
+	}
+	sm := errors[0].(*SystemMessageNode)
+	if sm.MessageType != severeIncompleteSectionTitle {
+		t.Errorf("Test: %q\n\t    Got: MessageType = %q, Expect: %q\n\n",
+			name, sm.MessageType, severeIncompleteSectionTitle)
+	}
+	if sm.Line != 1 {
+		t.Errorf("Test: %q\n\t    Got: Line = %d, Expect: %d\n\n",
+			name, sm.Line, 1)
+	}
+	lb, ok := sm.NodeList[len(sm.NodeList)-1].(*LiteralBlockNode)
+	if !ok || lb.Text != "===\n\n===" {
+		t.Errorf("Test: %q\n\t    Got: literal block = %#v, "+
+			"Expect: %q\n\n", name, lb, "===\n\n===")
+	}
+	if len(tr.Nodes) != 1 {
+		t.Errorf("Test: %q\n\t    Got: len(Nodes) = %d, Expect: %d\n\n",
+			name, len(tr.Nodes), 1)
+	}
+}
+
+var leadingAdornmentTests = []struct {
+	name     string
+	input    string
+	nodes    []NodeType      // The types of the top level nodes
+	messages []parserMessage // Expected messages in order
+}{
+	{
+		name:  "Adornment line and blank line before a paragraph",
+		input: "====\n\nParagraph.\n",
+		nodes: []NodeType{NodeSystemMessage, NodeTransition,
+			NodeParagraph},
+		messages: []parserMessage{errorTransitionAtBeginning},
+	},
+	{
+		name:  "Adornment line and blank line before a section",
+		input: "=====\n\nTitle\n=====\n\nParagraph.\n",
+		nodes: []NodeType{NodeSystemMessage, NodeTransition,
+			NodeSection},
+		messages: []parserMessage{errorTransitionAtBeginning},
+	},
+	{
+		name:  "Adornment line after blank lines",
+		input: "\n\n====\n\nParagraph.\n",
+		nodes: []NodeType{NodeSystemMessage, NodeTransition,
+			NodeParagraph},
+		messages: []parserMessage{errorTransitionAtBeginning},
+	},
+	{
+		name:  "Adornment line too short for a transition",
+		input: "==\n\nParagraph.\n",
+		nodes: []NodeType{NodeParagraph, NodeParagraph},
+	},
+}
+
+func TestParseLeadingAdornmentWithoutTitle(t *testing.T) {
+	for _, tt := range leadingAdornmentTests {
+		tr, errors := Parse(tt.name, tt.input)
+		if len(tr.Nodes) != len(tt.nodes) {
+			t.Errorf("Test: %q\n\t    Got: len(Nodes) = %d, Expect: %d\n\n",
+				tt.name, len(tr.Nodes), len(tt.nodes))
+			continue
+		}
+		for num, typ := range tt.nodes {
+			if tr.Nodes[num].NodeType() != typ {
+				t.Errorf("Test: %q\n\t    Got: Nodes[%d] = %s, "+
+					"Expect: %s\n\n", tt.name, num,
+					tr.Nodes[num].NodeType(), typ)
+			}
+		}
+		if len(errors) != len(tt.messages) {
+			t.Errorf("Test: %q\n\t    Got: len(errors) = %d, Expect: %d\n\n",
+				tt.name, len(errors), len(tt.messages))
+			continue
+		}
+		for num, msg := range tt.messages {
+			if sm := errors[num].(*SystemMessageNode); sm.MessageType != msg {
+				t.Errorf("Test: %q\n\t    Got: MessageType = %s, "+
+					"Expect: %s\n\n", tt.name, sm.MessageType, msg)
+			}
+		}
+	}
+}
@@ -0,0 +1,20 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+// parsedLiteralDirective returns the ParsedLiteralNode of the parsed-literal
+// directive d at item i. The whitespace and the line breaks of the directive
+// content are kept, and its inline markup is parsed into the NodeList of the
+// node.
+func (t *Tree) parsedLiteralDirective(d *directive, i *item) Node {
+	t.directiveContent(d, i)
+	n := newParsedLiteral(i, d.content, &t.id)
+	n.NodeList = t.inline(&ParagraphNode{
+		Text:          d.content,
+		Line:          i.Line + Line(d.contentLine),
+		StartPosition: StartPosition(d.contentIndent + 1),
+	})
+	return n
+}
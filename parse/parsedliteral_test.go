@@ -0,0 +1,55 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+func TestParseParsedLiteralDirective(t *testing.T) {
+	input := ".. parsed-literal::\n\n   See the *manual* at Go_.\n" +
+		"     Indented   line.\n\nAfter."
+	tr, errors := Parse("parsed-literal", input)
+	if len(errors) != 0 {
+		t.Fatalf("Got: len(errors) = %d, Expect: 0", len(errors))
+	}
+	if len(tr.Nodes) != 2 {
+		t.Fatalf("Got: len(Nodes) = %d, Expect: 2", len(tr.Nodes))
+	}
+	pl, ok := tr.Nodes[0].(*ParsedLiteralNode)
+	if !ok {
+		t.Fatalf("Got: Nodes[0] = %s, Expect: NodeParsedLiteral",
+			tr.Nodes[0].NodeType())
+	}
+	text := "See the *manual* at Go_.\n  Indented   line."
+	if pl.Text != text {
+		t.Errorf("Got: Text = %q, Expect: %q", pl.Text, text)
+	}
+	var types []NodeType
+	for _, n := range pl.NodeList {
+		types = append(types, n.NodeType())
+	}
+	expect := []NodeType{NodeText, NodeEmphasis, NodeText}
+	if len(types) != len(expect) {
+		t.Fatalf("Got: NodeList types = %v, Expect: %v", types, expect)
+	}
+	for num := range expect {
+		if types[num] != expect[num] {
+			t.Fatalf("Got: NodeList types = %v, Expect: %v", types,
+				expect)
+		}
+	}
+	last := pl.NodeList[2].(*TextNode)
+	if last.Text != " at Go_.\n  Indented   line." {
+		t.Errorf("Got: NodeList[2].Text = %q, Expect: %q", last.Text,
+			" at Go_.\n  Indented   line.")
+	}
+	if e := pl.NodeList[1].(*EmphasisNode); e.Line != 3 ||
+		e.StartPosition != 12 {
+		t.Errorf("Got: emphasis at %d:%d, Expect: 3:12", e.Line,
+			e.StartPosition)
+	}
+	if errs := tr.Validate(); len(errs) != 0 {
+		t.Errorf("Got: Validate() = %v, Expect: no errors", errs)
+	}
+}
@@ -0,0 +1,83 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Pos is a byte offset in the input of a Tree, like token.Pos of the go/token
+// package. The first byte of the input is at offset zero.
+type Pos int
+
+// FileSet converts byte offsets in the input of a Tree to line and column
+// positions. It provides the part of the token.FileSet API that is useful for
+// a single reStructuredText document.
+type FileSet struct {
+	name  string // The name of the parsed input
+	size  int    // The length of the input in bytes
+	lines []int  // The offset of the first byte of each line
+}
+
+// newFileSet returns a FileSet for text using name as the file name.
+func newFileSet(name, text string) *FileSet {
+	f := &FileSet{name: name, size: len(text), lines: []int{0}}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			f.lines = append(f.lines, i+1)
+		}
+	}
+	return f
+}
+
+// Position returns the line, column, and file name of offset. Lines and
+// columns begin at 1, columns are counted in bytes like the StartPosition of
+// nodes. A line and column of zero is returned if offset is outside of the
+// input.
+func (f *FileSet) Position(offset int) (line, col int, file string) {
+	if offset < 0 || offset > f.size {
+		return 0, 0, f.name
+	}
+	num := sort.Search(len(f.lines), func(i int) bool {
+		return f.lines[i] > offset
+	}) - 1
+	return num + 1, offset - f.lines[num] + 1, f.name
+}
+
+// Pos returns the byte offset of the column pos on line. The line and column
+// of a node, which begin at 1, can be converted to an offset with Pos. -1 is
+// returned if line is outside of the input.
+func (f *FileSet) Pos(line Line, pos StartPosition) Pos {
+	if line < 1 || int(line) > len(f.lines) {
+		return -1
+	}
+	if pos < 1 {
+		pos = 1
+	}
+	return Pos(f.lines[line-1] + int(pos) - 1)
+}
+
+// PositionString returns the position of p in the form "name:line:col", as
+// used by the Go tools. If p is outside of the input, only the name of the
+// input is returned, or "-" if the input has no name. The name is
+// Options.Source, or the name of the Tree if it is not set.
+func (t *Tree) PositionString(p Pos) string {
+	name := t.source()
+	if t.Fset == nil {
+		t.Fset = newFileSet(name, t.text)
+	}
+	line, col, _ := t.Fset.Position(int(p))
+	if line == 0 {
+		if name == "" {
+			return "-"
+		}
+		return name
+	}
+	if name != "" {
+		name += ":"
+	}
+	return name + strconv.Itoa(line) + ":" + strconv.Itoa(col)
+}
@@ -0,0 +1,59 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+var fileSetPositionTests = []struct {
+	name   string
+	offset int
+	line   int // Expected line
+	col    int // Expected column
+}{
+	{name: "Start of input", offset: 0, line: 1, col: 1},
+	{name: "End of first line", offset: 5, line: 1, col: 6},
+	{name: "Start of second line", offset: 6, line: 2, col: 1},
+	{name: "Blank line", offset: 12, line: 3, col: 1},
+	{name: "Middle of last line", offset: 17, line: 4, col: 5},
+	{name: "End of input", offset: 23, line: 4, col: 11},
+	{name: "Before input", offset: -1},
+	{name: "After input", offset: 24},
+}
+
+func TestFileSetPosition(t *testing.T) {
+	f := newFileSet("doc.rst", "Title\n=====\n\nParagraph.")
+	for _, tt := range fileSetPositionTests {
+		line, col, file := f.Position(tt.offset)
+		if line != tt.line || col != tt.col || file != "doc.rst" {
+			t.Errorf("Test: %q\n\t    Got: %s:%d:%d, "+
+				"Expect: doc.rst:%d:%d\n\n", tt.name, file, line,
+				col, tt.line, tt.col)
+		}
+	}
+}
+
+func TestTreePositionString(t *testing.T) {
+	input := "Document\n========\n\n=======\n Title\n=======\n\nParagraph."
+	tree, _ := Parse("doc.rst", input)
+	var node *TitleNode
+	Walk(tree.Nodes, func(n Node) bool {
+		if title, ok := n.(*TitleNode); ok && title.Text == "Title" {
+			node = title
+		}
+		return true
+	})
+	if node == nil {
+		t.Fatal("Got: no title node")
+	}
+	pos := tree.Fset.Pos(node.Line, node.StartPosition)
+	if got := tree.PositionString(pos); got != "doc.rst:5:2" {
+		t.Errorf("Got: PositionString == %q, Expect: %q", got,
+			"doc.rst:5:2")
+	}
+	if got := tree.PositionString(Pos(len(input) + 1)); got != "doc.rst" {
+		t.Errorf("Got: PositionString == %q, Expect: %q", got,
+			"doc.rst")
+	}
+}
@@ -0,0 +1,123 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// phraseReferenceEnd returns the index of the closing backquote of the phrase
+// reference "`text`_" or "`text`__" beginning at index i of text, and the
+// index after the reference. Both are -1 if no phrase reference begins at i.
+//
+// Like other inline markup, the reference is closed by the first backquote
+// that is preceded by a non-whitespace rune and is followed by an end context,
+// so "`text`" followed by an end context is interpreted text and not a
+// reference.
+func phraseReferenceEnd(text string, i int) (closing, end int) {
+	if text[i] != '`' || strings.HasPrefix(text[i:], "``") {
+		return -1, -1
+	}
+	after, _ := utf8.DecodeRuneInString(text[i+1:])
+	if i+1 == len(text) || unicode.IsSpace(after) {
+		return -1, -1
+	}
+	for j := i + 2; j < len(text); j++ {
+		if text[j] == '\\' {
+			j++
+			continue
+		}
+		before, _ := utf8.DecodeLastRuneInString(text[:j])
+		if text[j] != '`' || unicode.IsSpace(before) {
+			continue
+		}
+		end = j + 1
+		if strings.HasPrefix(text[end:], "__") {
+			end += 2
+		} else if strings.HasPrefix(text[end:], "_") {
+			end++
+		}
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if end < len(text) && !isInlineEndContext(after) {
+			continue
+		}
+		if end == j+1 {
+			return -1, -1
+		}
+		return j, end
+	}
+	return -1, -1
+}
+
+// embeddedReference splits the text of a phrase reference into the reference
+// text and the embedded URI or alias of "text <uri>" or "text <alias_>". ref
+// is empty if there is no embedded URI or alias. The "<" must begin the text
+// or follow whitespace, and the ">" must end the text.
+func embeddedReference(text string) (phrase, ref string) {
+	if !strings.HasSuffix(text, ">") || strings.HasSuffix(text, `\>`) {
+		return text, ""
+	}
+	open := strings.LastIndex(text, "<")
+	if open == -1 || open+1 == len(text)-1 {
+		return text, ""
+	}
+	if before, _ := utf8.DecodeLastRuneInString(text[:open]); open > 0 &&
+		!unicode.IsSpace(before) {
+		return text, ""
+	}
+	return strings.TrimSpace(text[:open]), text[open+1 : len(text)-1]
+}
+
+// phraseReference returns the ReferenceNode of the phrase reference raw with
+// the text between the backquotes at the byte offset off in the text of
+// paragraph p. If the reference text is empty, the embedded URI or alias is
+// used as the reference text.
+//
+// A named reference with an embedded URI or alias also defines a hyperlink
+// target with the reference text as its name. The TargetNode follows the
+// ReferenceNode in the returned NodeList, and its name is registered in
+// Tree.Names. The same embedded URI may be used in several references to the
+// same name. Anonymous references do not define a target name.
+func (t *Tree) phraseReference(p *ParagraphNode, off int, text, raw string,
+	anonymous bool) NodeList {
+
+	phrase, ref := embeddedReference(text)
+	line, pos := inlinePosition(p, off)
+	t.id++
+	r := &ReferenceNode{ID: ID(t.id), Type: NodeReference, Text: phrase,
+		Anonymous: anonymous, Length: len(raw), Line: line,
+		StartPosition: pos}
+	switch {
+	case ref == "":
+		if !anonymous {
+			r.RefName = normalizeName(phrase)
+		}
+		return NodeList{r}
+	case strings.HasSuffix(ref, "_") && !strings.HasSuffix(ref, `\_`):
+		ref = strings.TrimSuffix(ref, "_")
+		r.RefName = normalizeName(ref)
+	default:
+		r.RefURI = targetLink(ref)
+	}
+	if phrase == "" {
+		phrase = ref
+		r.Text = phrase
+	}
+	if anonymous {
+		return NodeList{r}
+	}
+	n := newTarget(&item{Line: line, StartPosition: pos}, phrase, false,
+		&t.id)
+	n.RefURI, n.RefName = r.RefURI, r.RefName
+	name := normalizeName(phrase)
+	n.Names = append(n.Names, name)
+	if prev, ok := t.Names[name].(*TargetNode); !ok || prev.RefURI != n.RefURI ||
+		prev.RefName != n.RefName {
+		t.addName(name, n)
+	}
+	return NodeList{r, n}
+}
@@ -0,0 +1,167 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+var phraseReferenceTests = []struct {
+	name      string
+	input     string
+	text      string // Expected text of the reference
+	refURI    string // Expected RefURI of the reference and the target
+	refName   string // Expected RefName of the reference and the target
+	anonymous bool   // Expected Anonymous of the reference
+	target    string // Expected name of the target, empty if there is none
+}{
+	{
+		name:    "Named reference",
+		input:   "See `Some  Title`_.",
+		text:    "Some  Title",
+		refName: "some title",
+	},
+	{
+		name:   "Embedded URI",
+		input:  "See `Go <https://golang.org>`_.",
+		text:   "Go",
+		refURI: "https://golang.org",
+		target: "go",
+	},
+	{
+		name:   "Embedded URI without text",
+		input:  "See `<https://golang.org>`_.",
+		text:   "https://golang.org",
+		refURI: "https://golang.org",
+		target: "https://golang.org",
+	},
+	{
+		name:   "Embedded URI across lines",
+		input:  "See `Go <https://golang\n.org>`_.",
+		text:   "Go",
+		refURI: "https://golang.org",
+		target: "go",
+	},
+	{
+		name:    "Embedded alias",
+		input:   "See `the docs <Go Docs_>`_.",
+		text:    "the docs",
+		refName: "go docs",
+		target:  "the docs",
+	},
+	{
+		name:      "Anonymous embedded URI",
+		input:     "See `Go <https://golang.org>`__.",
+		text:      "Go",
+		refURI:    "https://golang.org",
+		anonymous: true,
+	},
+	{
+		name:      "Anonymous reference",
+		input:     "See `Go`__.",
+		text:      "Go",
+		anonymous: true,
+	},
+	{
+		name:    "Less than inside a word",
+		input:   "See `a<b>`_.",
+		text:    "a<b>",
+		refName: "a<b>",
+	},
+}
+
+func TestParsePhraseReference(t *testing.T) {
+	for _, tt := range phraseReferenceTests {
+		tr, errors := ParseWithOptions(tt.name, tt.input,
+			&ParseOptions{ParseInline: true})
+		if len(errors) != 0 {
+			t.Errorf("Test: %q\n\t    Got: len(errors) = %d, Expect: 0\n\n",
+				tt.name, len(errors))
+		}
+		nl := tr.Nodes[0].(*ParagraphNode).NodeList
+		nTarget := 0
+		if tt.target != "" {
+			nTarget = 1
+		}
+		if len(nl) != 3+nTarget {
+			t.Errorf("Test: %q\n\t    Got: len(NodeList) = %d, "+
+				"Expect: %d\n\n", tt.name, len(nl), 3+nTarget)
+			continue
+		}
+		r, ok := nl[1].(*ReferenceNode)
+		if !ok {
+			t.Errorf("Test: %q\n\t    Got: NodeList[1] = %s, "+
+				"Expect: NodeReference\n\n", tt.name, nl[1].NodeType())
+			continue
+		}
+		if r.Text != tt.text || r.RefURI != tt.refURI ||
+			r.RefName != tt.refName || r.Anonymous != tt.anonymous {
+			t.Errorf("Test: %q\n\t    Got: %#v\n\t    Expect: Text = %q, "+
+				"RefURI = %q, RefName = %q, Anonymous = %t\n\n",
+				tt.name, r, tt.text, tt.refURI, tt.refName,
+				tt.anonymous)
+		}
+		if r.Line != 1 || r.StartPosition != 5 {
+			t.Errorf("Test: %q\n\t    Got: reference at %d:%d, "+
+				"Expect: 1:5\n\n", tt.name, r.Line, r.StartPosition)
+		}
+		if tt.target == "" {
+			if len(tr.Names) != 0 {
+				t.Errorf("Test: %q\n\t    Got: Names = %v, Expect: "+
+					"none\n\n", tt.name, tr.Names)
+			}
+			continue
+		}
+		n, ok := nl[2].(*TargetNode)
+		if !ok {
+			t.Errorf("Test: %q\n\t    Got: NodeList[2] = %s, "+
+				"Expect: NodeTarget\n\n", tt.name, nl[2].NodeType())
+			continue
+		}
+		if n.RefURI != tt.refURI || n.RefName != tt.refName ||
+			len(n.Names) != 1 || n.Names[0] != tt.target {
+			t.Errorf("Test: %q\n\t    Got: %#v\n\t    Expect: target %q "+
+				"with RefURI = %q, RefName = %q\n\n", tt.name, n,
+				tt.target, tt.refURI, tt.refName)
+		}
+		if tr.Names[tt.target] != n {
+			t.Errorf("Test: %q\n\t    Got: Names[%q] = %v, Expect: the "+
+				"target\n\n", tt.name, tt.target, tr.Names[tt.target])
+		}
+		if errs := tr.Validate(); len(errs) != 0 {
+			t.Errorf("Test: %q\n\t    Got: Validate() = %v, Expect: no "+
+				"errors\n\n", tt.name, errs)
+		}
+	}
+}
+
+func TestParsePhraseReferenceDuplicateTarget(t *testing.T) {
+	for _, tt := range []struct {
+		input string
+		nMsgs int
+	}{
+		{"`Go <https://golang.org>`_ and `Go <https://golang.org>`_", 0},
+		{"`Go <https://golang.org>`_ and `Go <https://go.dev>`_", 1},
+	} {
+		_, errors := ParseWithOptions("duplicate", tt.input,
+			&ParseOptions{ParseInline: true})
+		if len(errors) != tt.nMsgs {
+			t.Errorf("Test: %q\n\t    Got: len(errors) = %d, "+
+				"Expect: %d\n\n", tt.input, len(errors), tt.nMsgs)
+		}
+	}
+}
+
+func TestParseInterpretedTextIsNotReference(t *testing.T) {
+	for _, input := range []string{"`text`", "`text`_x", "`text`:emphasis:"} {
+		tr, _ := ParseWithOptions(input, input,
+			&ParseOptions{ParseInline: true})
+		Walk(tr.Nodes, func(n Node) bool {
+			if _, ok := n.(*ReferenceNode); ok {
+				t.Errorf("Test: %q\n\t    Got: %#v, Expect: no "+
+					"reference\n\n", input, n)
+			}
+			return true
+		})
+	}
+}
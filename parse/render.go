@@ -0,0 +1,63 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// RenderFunc writes the parsed document of the Tree t to w in an output
+// format.
+type RenderFunc func(t *Tree, w io.Writer) error
+
+var (
+	renderersMu sync.RWMutex
+
+	// renderers are the output formats of Tree.Render by name.
+	renderers = map[string]RenderFunc{
+		"markdown":   (*Tree).RenderMarkdown,
+		"text":       (*Tree).RenderText,
+		"pseudo-xml": (*Tree).renderPseudoXML,
+	}
+)
+
+// RegisterRenderer makes the output format named format available to
+// Tree.Render. A renderer registered with the name of a built-in format, like
+// "markdown", replaces the built-in renderer. RegisterRenderer panics if fn is
+// nil.
+func RegisterRenderer(format string, fn RenderFunc) {
+	if fn == nil {
+		panic("parse: RegisterRenderer with a nil RenderFunc")
+	}
+	renderersMu.Lock()
+	renderers[format] = fn
+	renderersMu.Unlock()
+}
+
+// Render writes the parsed document to w in the output format named format.
+// The built-in formats are "markdown", "text", and "pseudo-xml", written by
+// RenderMarkdown, RenderText, and PseudoXML. Other formats, like "html", are
+// added with RegisterRenderer. An error is returned if format is unknown.
+func (t *Tree) Render(w io.Writer, format string) error {
+	renderersMu.RLock()
+	fn := renderers[format]
+	renderersMu.RUnlock()
+	if fn == nil {
+		return fmt.Errorf("unknown output format %q", format)
+	}
+	return fn(t, w)
+}
+
+// renderPseudoXML writes the pseudo-XML of the parsed document to w.
+func (t *Tree) renderPseudoXML(w io.Writer) error {
+	text := PseudoXML(t.Nodes)
+	if text == "" {
+		return nil
+	}
+	_, err := io.WriteString(w, text+"\n")
+	return err
+}
@@ -0,0 +1,57 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestTreeRender(t *testing.T) {
+	tr, _ := Parse("render", "Title\n=====\n\nSome *text*.")
+	for _, format := range []string{"markdown", "text", "pseudo-xml"} {
+		var got, expect bytes.Buffer
+		if err := tr.Render(&got, format); err != nil {
+			t.Errorf("Test: %q\n\t    Got: err = %s\n\n", format, err)
+			continue
+		}
+		switch format {
+		case "markdown":
+			tr.RenderMarkdown(&expect)
+		case "text":
+			tr.RenderText(&expect)
+		case "pseudo-xml":
+			expect.WriteString(PseudoXML(tr.Nodes) + "\n")
+		}
+		if got.String() != expect.String() {
+			t.Errorf("Test: %q\n\t    Got: %q\n\t Expect: %q\n\n",
+				format, got.String(), expect.String())
+		}
+	}
+	if err := tr.Render(&bytes.Buffer{}, "unknown"); err == nil {
+		t.Error("Got: err = nil for an unknown format, Expect: an error")
+	}
+}
+
+func TestRegisterRenderer(t *testing.T) {
+	RegisterRenderer("test-dummy", func(t *Tree, w io.Writer) error {
+		_, err := io.WriteString(w, "dummy "+t.Name)
+		return err
+	})
+	defer func() {
+		renderersMu.Lock()
+		delete(renderers, "test-dummy")
+		renderersMu.Unlock()
+	}()
+	tr, _ := Parse("custom", "Paragraph.")
+	var buf bytes.Buffer
+	if err := tr.Render(&buf, "test-dummy"); err != nil {
+		t.Fatalf("Got: err = %s, Expect: nil", err)
+	}
+	if expect := "dummy custom"; buf.String() != expect {
+		t.Errorf("Got: %q, Expect: %q", buf.String(), expect)
+	}
+}
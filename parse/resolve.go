@@ -0,0 +1,141 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// footnoteSymbols are the symbols of auto-symbol footnotes in the order they
+// are assigned. When the symbols are used up, they are repeated twice, then
+// three times, and so on.
+var footnoteSymbols = []string{"*", "†", "‡", "§", "¶", "#", "♠", "♥", "♦",
+	"♣"}
+
+// footnoteSymbol returns the symbol of the auto-symbol footnote num, counting
+// from zero.
+func footnoteSymbol(num int) string {
+	n := len(footnoteSymbols)
+	return strings.Repeat(footnoteSymbols[num%n], num/n+1)
+}
+
+// resolveReferences links the FootnoteRefNodes and CitationRefNodes of the
+// document to the footnotes and citations they refer to. It runs after the
+// auto-numbered footnotes are numbered by numberFootnotes.
+//
+// The footnotes, citations, and references are given ids, the id of the
+// referenced element is set as the Refid of a reference, and the id of a
+// reference is added to the BackRefs of the referenced element, so renderers
+// can link in both directions. Auto-symbol footnotes and their references are
+// matched in the order they appear, like anonymous auto-numbered footnotes.
+// The footnote references are collected in Tree.FootnoteReferences. An error
+// is added to Tree.Messages for each reference without a matching element, and
+// a warning for each auto-symbol footnote that is not referenced.
+func (t *Tree) resolveReferences() {
+	var footnotes []*FootnoteNode
+	var refs []Node
+	citations := make(map[string]*CitationNode)
+	Walk(t.Nodes, func(n Node) bool {
+		switch n := n.(type) {
+		case *FootnoteNode:
+			footnotes = append(footnotes, n)
+		case *CitationNode:
+			id := className(n.Label)
+			if id == "" {
+				id = strconv.Itoa(int(n.ID))
+			}
+			n.Ids = append(n.Ids, "citation-"+id)
+			name := strings.ToLower(n.Label)
+			if citations[name] == nil {
+				citations[name] = n
+			}
+		case *FootnoteRefNode, *CitationRefNode:
+			refs = append(refs, n)
+		}
+		return true
+	})
+
+	var anonymous, symbols []*FootnoteNode
+	named := make(map[string]*FootnoteNode)
+	numbered := make(map[int]*FootnoteNode)
+	for _, f := range footnotes {
+		switch {
+		case f.Label == "*":
+			f.Symbol = footnoteSymbol(len(symbols))
+			symbols = append(symbols, f)
+			f.Ids = append(f.Ids,
+				fmt.Sprintf("footnote-symbol-%d", len(symbols)))
+		case f.Label == "#":
+			anonymous = append(anonymous, f)
+		case strings.HasPrefix(f.Label, "#"):
+			named[footnoteName(f.Label)] = f
+		}
+		if f.Number > 0 {
+			f.Ids = append(f.Ids, fmt.Sprintf("footnote-%d", f.Number))
+			if numbered[f.Number] == nil {
+				numbered[f.Number] = f
+			}
+		}
+	}
+
+	var footnoteRefs, citationRefs, symbolRefs int
+	for _, r := range refs {
+		switch r := r.(type) {
+		case *FootnoteRefNode:
+			footnoteRefs++
+			id := fmt.Sprintf("footnote-reference-%d", footnoteRefs)
+			r.Ids = append(r.Ids, id)
+			var f *FootnoteNode
+			switch {
+			case r.Label == "*":
+				if symbolRefs < len(symbols) {
+					f = symbols[symbolRefs]
+					symbolRefs++
+				}
+			case r.Label == "#":
+				if len(anonymous) > 0 {
+					f = anonymous[0]
+					anonymous = anonymous[1:]
+				}
+			case strings.HasPrefix(r.Label, "#"):
+				f = named[footnoteName(r.Label)]
+			default:
+				num, _ := strconv.Atoi(r.Label)
+				f = numbered[num]
+			}
+			ref := &FootnoteReference{Label: r.Label, Line: r.Line}
+			t.FootnoteReferences = append(t.FootnoteReferences, ref)
+			if f == nil || len(f.Ids) == 0 {
+				t.positionMessage(errorUnknownFootnoteReference, r.Line,
+					r.StartPosition)
+				continue
+			}
+			ref.Footnote = f
+			r.Refid, r.Number, r.Symbol = f.Ids[0], f.Number, f.Symbol
+			f.BackRefs = append(f.BackRefs, id)
+		case *CitationRefNode:
+			citationRefs++
+			id := fmt.Sprintf("citation-reference-%d", citationRefs)
+			r.Ids = append(r.Ids, id)
+			c := citations[strings.ToLower(r.Label)]
+			if c == nil {
+				t.positionMessage(errorUnknownCitationReference, r.Line,
+					r.StartPosition)
+				continue
+			}
+			r.Refid = c.Ids[0]
+			c.BackRefs = append(c.BackRefs, id)
+		}
+	}
+
+	for _, f := range symbols {
+		if len(f.BackRefs) == 0 {
+			t.positionMessage(warningUnreferencedSymbolFootnote, f.Line,
+				f.StartPosition)
+		}
+	}
+}
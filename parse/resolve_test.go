@@ -0,0 +1,142 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"reflect"
+	"testing"
+)
+
+// parseReferences parses input with inline parsing enabled and returns the
+// tree, the footnote and citation references, and the footnotes and
+// citations in document order.
+func parseReferences(input string) (tr *Tree, refs, targets NodeList) {
+	tr, _ = ParseWithOptions("references", input,
+		&ParseOptions{ParseInline: true})
+	Walk(tr.Nodes, func(n Node) bool {
+		switch n.(type) {
+		case *FootnoteRefNode, *CitationRefNode:
+			refs = append(refs, n)
+		case *FootnoteNode, *CitationNode:
+			targets = append(targets, n)
+		}
+		return true
+	})
+	return
+}
+
+func TestResolveNumberedFootnoteReference(t *testing.T) {
+	tr, refs, targets := parseReferences("Some text [1]_.\n\n.. [1] A note.")
+	if len(tr.Messages) != 0 {
+		t.Fatalf("Got: len(Messages) = %d, Expect: 0", len(tr.Messages))
+	}
+	if len(refs) != 1 || len(targets) != 1 {
+		t.Fatalf("Got: %d references, %d footnotes, Expect: 1, 1",
+			len(refs), len(targets))
+	}
+	r, f := refs[0].(*FootnoteRefNode), targets[0].(*FootnoteNode)
+	if r.Refid != "footnote-1" || r.Number != 1 {
+		t.Errorf("Got: Refid = %q, Number = %d, Expect: %q, 1", r.Refid,
+			r.Number, "footnote-1")
+	}
+	if !reflect.DeepEqual(f.Ids, []string{"footnote-1"}) {
+		t.Errorf("Got: Ids = %v, Expect: [footnote-1]", f.Ids)
+	}
+	if !reflect.DeepEqual(f.BackRefs, r.Ids) {
+		t.Errorf("Got: BackRefs = %v, Expect: %v", f.BackRefs, r.Ids)
+	}
+}
+
+func TestResolveCitationReference(t *testing.T) {
+	_, refs, targets := parseReferences("See [CIT2002]_.\n\n" +
+		".. [CIT2002] A book.")
+	if len(refs) != 1 || len(targets) != 1 {
+		t.Fatalf("Got: %d references, %d citations, Expect: 1, 1",
+			len(refs), len(targets))
+	}
+	r, c := refs[0].(*CitationRefNode), targets[0].(*CitationNode)
+	if r.Refid != "citation-cit2002" {
+		t.Errorf("Got: Refid = %q, Expect: %q", r.Refid,
+			"citation-cit2002")
+	}
+	if !reflect.DeepEqual(c.BackRefs, r.Ids) {
+		t.Errorf("Got: BackRefs = %v, Expect: %v", c.BackRefs, r.Ids)
+	}
+}
+
+func TestResolveUndefinedReference(t *testing.T) {
+	tr, refs, _ := parseReferences("Some text [2]_ and [CIT]_.")
+	if len(refs) != 2 {
+		t.Fatalf("Got: len(refs) = %d, Expect: 2", len(refs))
+	}
+	expect := []parserMessage{errorUnknownFootnoteReference,
+		errorUnknownCitationReference}
+	if len(tr.Diagnostics) != len(expect) {
+		t.Fatalf("Got: len(Diagnostics) = %d, Expect: %d",
+			len(tr.Diagnostics), len(expect))
+	}
+	for num, d := range tr.Diagnostics {
+		if d.MessageType != expect[num] || d.Line != 1 {
+			t.Errorf("Got: Diagnostics[%d] = %s on line %d, "+
+				"Expect: %s on line 1", num, d.MessageType, d.Line,
+				expect[num])
+		}
+	}
+	if r := refs[0].(*FootnoteRefNode); r.Refid != "" {
+		t.Errorf("Got: Refid = %q, Expect: \"\"", r.Refid)
+	}
+}
+
+func TestResolveAutoNumberedFootnotes(t *testing.T) {
+	input := "First [#]_ and second [#]_ and [#named]_.\n\n" +
+		".. [#named] Named note.\n\n.. [#] First note.\n\n" +
+		".. [#] Second note."
+	tr, refs, targets := parseReferences(input)
+	if len(tr.Messages) != 0 {
+		t.Fatalf("Got: len(Messages) = %d, Expect: 0", len(tr.Messages))
+	}
+	if len(refs) != 3 || len(targets) != 3 {
+		t.Fatalf("Got: %d references, %d footnotes, Expect: 3, 3",
+			len(refs), len(targets))
+	}
+	// The footnotes are numbered in document order, the anonymous
+	// references refer to the anonymous footnotes in order.
+	expect := []struct {
+		number int
+		target int
+	}{{2, 1}, {3, 2}, {1, 0}}
+	for num, e := range expect {
+		r := refs[num].(*FootnoteRefNode)
+		f := targets[e.target].(*FootnoteNode)
+		if r.Number != e.number || f.Number != e.number ||
+			r.Refid != f.Ids[0] {
+			t.Errorf("Got: refs[%d] = %d %q, footnote = %d %v, "+
+				"Expect: %d", num, r.Number, r.Refid, f.Number, f.Ids,
+				e.number)
+		}
+		if !reflect.DeepEqual(f.BackRefs, r.Ids) {
+			t.Errorf("Got: BackRefs = %v, Expect: %v", f.BackRefs,
+				r.Ids)
+		}
+	}
+}
+
+func TestResolveUnreferencedSymbolFootnote(t *testing.T) {
+	tr, _, targets := parseReferences("See [*]_.\n\n.. [*] First.\n\n" +
+		".. [*] Second.")
+	if len(targets) != 2 {
+		t.Fatalf("Got: len(targets) = %d, Expect: 2", len(targets))
+	}
+	for num, sym := range []string{"*", "†"} {
+		if f := targets[num].(*FootnoteNode); f.Symbol != sym {
+			t.Errorf("Got: Symbol = %q, Expect: %q", f.Symbol, sym)
+		}
+	}
+	if len(tr.Diagnostics) != 1 || tr.Diagnostics[0].MessageType !=
+		warningUnreferencedSymbolFootnote || tr.Diagnostics[0].Line != 5 {
+		t.Errorf("Got: Diagnostics = %v, Expect: %s on line 5",
+			tr.Diagnostics, warningUnreferencedSymbolFootnote)
+	}
+}
@@ -0,0 +1,177 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RoleHandler parses interpreted text with a role. Handlers are added to the
+// parser with ParseOptions.Roles.
+type RoleHandler interface {
+	// Name returns the name of the role handled, for example "emphasis".
+	// Role names are not case sensitive.
+	Name() string
+
+	// Parse returns the inline node of the interpreted text. The ID and
+	// the position of the returned node are set by the parser if they are
+	// zero. The diagnostics are handled like the diagnostics of a
+	// DirectiveHandler. If the returned node is nil, the interpreted text
+	// is added as a ProblematicNode referencing the first system message.
+	Parse(text string) (Node, []Diagnostic)
+}
+
+// markupRole is the RoleHandler of a built-in role that returns an inline
+// markup node of type typ, like the equivalent inline markup.
+type markupRole struct {
+	name string
+	typ  NodeType
+}
+
+// Name returns the name of the role.
+func (r markupRole) Name() string {
+	return r.name
+}
+
+// Parse returns the inline markup node of text.
+func (r markupRole) Parse(text string) (Node, []Diagnostic) {
+	switch r.typ {
+	case NodeEmphasis:
+		return &EmphasisNode{Type: r.typ, Text: text, Length: len(text)}, nil
+	case NodeStrong:
+		return &StrongNode{Type: r.typ, Text: text, Length: len(text)}, nil
+	}
+	return &InlineLiteralNode{Type: r.typ, Text: text, Length: len(text)}, nil
+}
+
+// builtinRoles are the built-in interpreted text roles.
+var builtinRoles = []RoleHandler{
+	markupRole{"emphasis", NodeEmphasis},
+	markupRole{"strong", NodeStrong},
+	markupRole{"literal", NodeInlineLiteral},
+}
+
+// roleHandler returns the RoleHandler of the role name. The handlers of
+// ParseOptions.Roles are searched before the built-in handlers. nil is
+// returned if there is no handler for name.
+func (t *Tree) roleHandler(name string) RoleHandler {
+	for _, h := range t.Options.Roles {
+		if strings.ToLower(h.Name()) == name {
+			return h
+		}
+	}
+	for _, h := range builtinRoles {
+		if h.Name() == name {
+			return h
+		}
+	}
+	return nil
+}
+
+// isRoleName returns true if name is the name of an interpreted text role.
+// Role names are simple reference names without colons.
+func isRoleName(name string) bool {
+	return isSimpleReferenceName(name) && !strings.Contains(name, ":")
+}
+
+// interpretedText returns the lowercased role and the text of the interpreted
+// text with an explicit role beginning at index i of text, and the index after
+// the interpreted text. The role is given before the text, ":role:`text`", or
+// after it, "`text`:role:". end is -1 if no interpreted text with an explicit
+// role begins at i.
+func interpretedText(text string, i int) (role, content string, end int) {
+	start := i
+	if text[i] == ':' {
+		r := strings.Index(text[i+1:], ":`")
+		if r < 1 || !isRoleName(text[i+1:i+1+r]) {
+			return "", "", -1
+		}
+		role = text[i+1 : i+1+r]
+		start = i + r + 2
+	}
+	if !strings.HasPrefix(text[start:], "`") ||
+		strings.HasPrefix(text[start:], "``") {
+		return "", "", -1
+	}
+	after, _ := utf8.DecodeRuneInString(text[start+1:])
+	if start+1 == len(text) || unicode.IsSpace(after) {
+		return "", "", -1
+	}
+	e := findInlineEnd(text, start+1, "`")
+	if e == -1 {
+		return "", "", -1
+	}
+	content, end = text[start+1:e], e+1
+	if role == "" {
+		r := strings.Index(text[end:], ":")
+		if r != 0 {
+			return "", "", -1
+		}
+		r = strings.Index(text[end+1:], ":")
+		if r < 1 || !isRoleName(text[end+1:end+1+r]) {
+			return "", "", -1
+		}
+		role = text[end+1 : end+1+r]
+		end += r + 2
+		after, _ = utf8.DecodeRuneInString(text[end:])
+		if end < len(text) && !isInlineEndContext(after) {
+			return "", "", -1
+		}
+	}
+	return strings.ToLower(role), content, end
+}
+
+// interpreted returns the node of the interpreted text content with role at
+// the byte offset off in the text of paragraph p. raw is the markup of the
+// interpreted text. The node is returned by the RoleHandler of role. If there
+// is no handler for role, or the handler returns no node, a ProblematicNode
+// for raw is returned.
+func (t *Tree) interpreted(p *ParagraphNode, off int, role, content,
+	raw string) Node {
+
+	h := t.roleHandler(role)
+	if h == nil {
+		return t.problematic(p, off, raw, errorUnknownInterpretedTextRole)
+	}
+	line, pos := inlinePosition(p, off)
+	n, diags := h.Parse(content)
+	msgs := t.diagnosticMessages(diags, line, pos)
+	if n == nil {
+		n = &ProblematicNode{
+			Type:          NodeProblematic,
+			Text:          raw,
+			Length:        len(raw),
+			Line:          line,
+			StartPosition: pos,
+		}
+		if len(msgs) > 0 {
+			n.(*ProblematicNode).Message = msgs[0]
+		}
+	}
+	setPosition(n, line, pos)
+	t.numberNodes(NodeList{n})
+	return n
+}
+
+// setPosition sets the Line and StartPosition fields of the node n if they are
+// zero.
+func setPosition(n Node, line Line, pos StartPosition) {
+	v := reflect.ValueOf(n)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	v = v.Elem()
+	if f := v.FieldByName("Line"); f.IsValid() && f.CanSet() &&
+		f.Int() == 0 {
+		f.SetInt(int64(line))
+	}
+	if f := v.FieldByName("StartPosition"); f.IsValid() && f.CanSet() &&
+		f.Int() == 0 {
+		f.SetInt(int64(pos))
+	}
+}
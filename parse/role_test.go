@@ -0,0 +1,159 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"strings"
+	"testing"
+)
+
+var interpretedTextTests = []struct {
+	name    string
+	input   string
+	typ     NodeType      // The type of the second inline node
+	text    string        // The text of the second inline node
+	pos     StartPosition // The column of the second inline node
+	message parserMessage // The expected system message
+}{
+	{
+		name:  "Role before the text",
+		input: "An :emphasis:`emphasized` word.",
+		typ:   NodeEmphasis,
+		text:  "emphasized",
+		pos:   4,
+	},
+	{
+		name:  "Role after the text",
+		input: "A `strong`:strong: word.",
+		typ:   NodeStrong,
+		text:  "strong",
+		pos:   3,
+	},
+	{
+		name:  "Role names are not case sensitive",
+		input: "A :Literal:`x := 1` word.",
+		typ:   NodeInlineLiteral,
+		text:  "x := 1",
+		pos:   3,
+	},
+	{
+		name:    "Unknown role",
+		input:   "A :unknown:`text` word.",
+		typ:     NodeProblematic,
+		text:    ":unknown:`text`",
+		pos:     3,
+		message: errorUnknownInterpretedTextRole,
+	},
+}
+
+func TestParseInterpretedText(t *testing.T) {
+	for _, tt := range interpretedTextTests {
+		tr, _ := ParseWithOptions(tt.name, tt.input,
+			&ParseOptions{ParseInline: true})
+		p := tr.Nodes[0].(*ParagraphNode)
+		if len(p.NodeList) != 3 {
+			t.Errorf("Test: %q\n\t    Got: len(NodeList) = %d, "+
+				"Expect: 3\n\n", tt.name, len(p.NodeList))
+			continue
+		}
+		n := p.NodeList[1]
+		if n.NodeType() != tt.typ {
+			t.Errorf("Test: %q\n\t    Got: NodeType = %s, "+
+				"Expect: %s\n\n", tt.name, n.NodeType(), tt.typ)
+			continue
+		}
+		var text string
+		var pos StartPosition
+		switch n := n.(type) {
+		case *EmphasisNode:
+			text, pos = n.Text, n.StartPosition
+		case *StrongNode:
+			text, pos = n.Text, n.StartPosition
+		case *InlineLiteralNode:
+			text, pos = n.Text, n.StartPosition
+		case *ProblematicNode:
+			text, pos = n.Text, n.StartPosition
+		}
+		if text != tt.text || pos != tt.pos {
+			t.Errorf("Test: %q\n\t    Got: Text = %q, StartPosition = "+
+				"%d, Expect: %q, %d\n\n", tt.name, text, pos,
+				tt.text, tt.pos)
+		}
+		var msg parserMessage
+		if len(tr.Diagnostics) > 0 {
+			msg = tr.Diagnostics[0].MessageType
+		}
+		if len(tr.Diagnostics) > 1 || msg != tt.message {
+			t.Errorf("Test: %q\n\t    Got: Diagnostics = %v, "+
+				"Expect: %s\n\n", tt.name, tr.Diagnostics, tt.message)
+		}
+	}
+}
+
+// abbrNode is an abbreviation with its explanation.
+type abbrNode struct {
+	ID
+	Type        NodeType
+	Text        string
+	Explanation string
+	Line
+	StartPosition
+	Attributes
+}
+
+func (a abbrNode) NodeType() NodeType { return a.Type }
+
+func (a *abbrNode) Accept(v Visitor) {}
+
+// abbrRole parses an abbreviation with the explanation in parentheses,
+// ":abbr:`HTML (HyperText Markup Language)`".
+type abbrRole struct{}
+
+func (abbrRole) Name() string { return "abbr" }
+
+func (abbrRole) Parse(text string) (Node, []Diagnostic) {
+	open := strings.Index(text, " (")
+	if open == -1 || !strings.HasSuffix(text, ")") {
+		return nil, []Diagnostic{{
+			MessageType: errorUnknownInterpretedTextRole}}
+	}
+	return &abbrNode{Type: NodeText, Text: text[:open],
+		Explanation: text[open+2 : len(text)-1]}, nil
+}
+
+func TestParseRoleHandler(t *testing.T) {
+	opts := &ParseOptions{ParseInline: true,
+		Roles: []RoleHandler{abbrRole{}}}
+	tr, _ := ParseWithOptions("abbr",
+		"Use\n:abbr:`HTML (HyperText Markup Language)` and `CSS`:abbr:.",
+		opts)
+	p := tr.Nodes[0].(*ParagraphNode)
+	if len(p.NodeList) != 5 {
+		t.Fatalf("Got: len(NodeList) = %d, Expect: 5", len(p.NodeList))
+	}
+	a, ok := p.NodeList[1].(*abbrNode)
+	if !ok {
+		t.Fatalf("Got: NodeList[1] = %#v, Expect: *abbrNode",
+			p.NodeList[1])
+	}
+	if a.Text != "HTML" || a.Explanation != "HyperText Markup Language" {
+		t.Errorf("Got: Text = %q, Explanation = %q, Expect: %q, %q",
+			a.Text, a.Explanation, "HTML", "HyperText Markup Language")
+	}
+	if a.Line != 2 || a.StartPosition != 1 || a.ID <= p.ID {
+		t.Errorf("Got: Line = %d, StartPosition = %d, ID = %d, "+
+			"Expect: 2, 1, > %d", a.Line, a.StartPosition, a.ID, p.ID)
+	}
+	pr, ok := p.NodeList[3].(*ProblematicNode)
+	if !ok || pr.Text != "`CSS`:abbr:" || pr.Message == nil {
+		t.Fatalf("Got: NodeList[3] = %#v, Expect: problematic "+
+			"\"`CSS`:abbr:\"", p.NodeList[3])
+	}
+	if len(tr.Diagnostics) != 1 || tr.Diagnostics[0].Node != pr.Message ||
+		tr.Diagnostics[0].Line != 2 {
+		t.Errorf("Got: Diagnostics = %v, Expect: one on line 2",
+			tr.Diagnostics)
+	}
+}
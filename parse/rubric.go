@@ -0,0 +1,27 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+// rubricDirective returns the RubricNode of the rubric directive d at item i.
+// The argument is the text of the rubric, its inline markup is parsed into the
+// NodeList of the rubric. A rubric does not start a section, so the section
+// levels are not changed. A system message is returned if the text is missing.
+func (t *Tree) rubricDirective(d *directive, i *item) Node {
+	if d.argument == "" {
+		return t.itemMessage(errorRubricDirectiveArgument, i)
+	}
+	n := newRubric(i, d.argument, &t.id)
+	// The directive text follows the explicit markup start ".. ".
+	pos := i.StartPosition
+	if pos == 0 {
+		pos = 1
+	}
+	n.NodeList = t.inline(&ParagraphNode{
+		Text:          d.argument,
+		Line:          i.Line,
+		StartPosition: pos + 3 + StartPosition(d.argumentOffset),
+	})
+	return n
+}
@@ -0,0 +1,56 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+func TestParseRubricDirective(t *testing.T) {
+	input := "Title\n=====\n\nFirst paragraph.\n\n" +
+		".. rubric:: Informal *heading*\n\nSecond paragraph.\n\n" +
+		"Next\n====\n\nThird paragraph."
+	tr, errors := Parse("rubric", input)
+	if len(errors) != 0 {
+		t.Fatalf("Got: len(errors) = %d, Expect: 0", len(errors))
+	}
+	if len(tr.Nodes) != 2 {
+		t.Fatalf("Got: len(Nodes) = %d, Expect: 2", len(tr.Nodes))
+	}
+	for num, n := range tr.Nodes {
+		s, ok := n.(*SectionNode)
+		if !ok || s.Level != 1 {
+			t.Fatalf("Got: Nodes[%d] = %#v, Expect: level 1 section",
+				num, n)
+		}
+	}
+	if len(tr.sectionLevels.levels) != 1 {
+		t.Errorf("Got: len(sectionLevels) = %d, Expect: 1",
+			len(tr.sectionLevels.levels))
+	}
+	body := tr.Nodes[0].(*SectionNode).NodeList
+	if len(body) != 3 {
+		t.Fatalf("Got: len(NodeList) = %d, Expect: 3", len(body))
+	}
+	r, ok := body[1].(*RubricNode)
+	if !ok {
+		t.Fatalf("Got: NodeList[1] = %s, Expect: NodeRubric",
+			body[1].NodeType())
+	}
+	if r.Text != "Informal *heading*" || r.Line != 6 {
+		t.Errorf("Got: Text = %q, Line = %d, Expect: %q, 6", r.Text,
+			r.Line, "Informal *heading*")
+	}
+	if len(r.NodeList) != 2 {
+		t.Fatalf("Got: len(Rubric.NodeList) = %d, Expect: 2",
+			len(r.NodeList))
+	}
+	if e, ok := r.NodeList[1].(*EmphasisNode); !ok || e.Text != "heading" ||
+		e.StartPosition != 22 {
+		t.Errorf("Got: Rubric.NodeList[1] = %#v, Expect: emphasis "+
+			"\"heading\" at 22", r.NodeList[1])
+	}
+	if errs := tr.Validate(); len(errs) != 0 {
+		t.Errorf("Got: Validate() = %v, Expect: no errors", errs)
+	}
+}
@@ -0,0 +1,157 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"reflect"
+	"strings"
+)
+
+// SourceMapEntry is the range of input bytes [Start, End) a node was parsed
+// from. A renderer can use it to tie output elements to the input, for example
+// with a data-source attribute.
+type SourceMapEntry struct {
+	ID    ID  // The ID of the node
+	Start Pos // The offset of the first byte of the node
+	End   Pos // The offset after the last byte of the node
+}
+
+// SourceMap returns the source map entries of the nodes in Tree.Nodes in
+// document order. The range of a node with text ends at the end of its last
+// line of text. The range of a node with children includes the ranges of the
+// children, so the range of a section begins at its overline or title and ends
+// at the end of its body. Nodes without a position in the input, like blank
+// lines added by the parser, have no entry.
+func (t *Tree) SourceMap() []SourceMapEntry {
+	if t.Fset == nil {
+		t.Fset = newFileSet(t.source(), t.text)
+	}
+	var entries []SourceMapEntry
+	var span func(n Node) (start, end Pos)
+	span = func(n Node) (start, end Pos) {
+		start, end = t.nodeSpan(n)
+		num := len(entries)
+		entries = append(entries, SourceMapEntry{ID: n.IDNumber()})
+		for _, c := range children(n) {
+			if c == nil {
+				continue
+			}
+			cs, ce := span(c)
+			if cs == -1 {
+				continue
+			}
+			if start == -1 || cs < start {
+				start = cs
+			}
+			if ce > end {
+				end = ce
+			}
+		}
+		entries[num].Start, entries[num].End = start, end
+		return
+	}
+	for _, n := range t.Nodes {
+		if n != nil {
+			span(n)
+		}
+	}
+
+	var found []SourceMapEntry
+	for _, e := range entries {
+		if e.Start != -1 {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+// nodeSpan returns the range of input bytes of the text of node n, not
+// counting its children. start and end are -1 if n has no position.
+func (t *Tree) nodeSpan(n Node) (start, end Pos) {
+	v := reflect.Indirect(reflect.ValueOf(n))
+	line, pos := v.FieldByName("Line"), v.FieldByName("StartPosition")
+	if !line.IsValid() || line.Int() == 0 || !pos.IsValid() {
+		return -1, -1
+	}
+	start = t.Fset.Pos(Line(line.Int()), StartPosition(pos.Int()))
+	if start == -1 {
+		return -1, -1
+	}
+	var text string
+	if f := v.FieldByName("Text"); f.IsValid() {
+		text = f.String()
+	}
+	length := len(text)
+	if f := v.FieldByName("Length"); f.IsValid() {
+		length = int(f.Int())
+	}
+	end = start + Pos(length)
+	// The lines of the text after the first line may be indented in the
+	// input, so the text ends at the end of its last line.
+	if lines := strings.Count(text, "\n"); lines > 0 {
+		last := int(line.Int()) + lines
+		if last < len(t.Fset.lines) {
+			end = Pos(t.Fset.lines[last] - 1)
+		} else {
+			end = Pos(t.Fset.size)
+		}
+	}
+	if int(end) > t.Fset.size {
+		end = Pos(t.Fset.size)
+	}
+	return
+}
+
+// LineSpan returns the first and the last line of input node n was parsed
+// from. Like the ranges of SourceMap, the span of a node includes the spans of
+// its children, so the span of a section begins at its overline or title and
+// ends at the last line of its body, and the span of a literal block includes
+// all of its lines. Zero is returned for nodes without a position in the
+// input.
+func (t *Tree) LineSpan(n Node) (start, end Line) {
+	if t.Fset == nil {
+		t.Fset = newFileSet(t.source(), t.text)
+	}
+	s, e := t.treeSpan(n)
+	if s == -1 {
+		return 0, 0
+	}
+	if e > s {
+		// The range ends after its last byte.
+		e--
+	}
+	sl, _, _ := t.Fset.Position(int(s))
+	el, _, _ := t.Fset.Position(int(e))
+	return Line(sl), Line(el)
+}
+
+// EndLine returns the last line of input node n was parsed from, as returned
+// by LineSpan.
+func (t *Tree) EndLine(n Node) Line {
+	_, end := t.LineSpan(n)
+	return end
+}
+
+// treeSpan returns the range of input bytes of node n and its children. start
+// and end are -1 if neither n nor its children have a position.
+func (t *Tree) treeSpan(n Node) (start, end Pos) {
+	start, end = t.nodeSpan(n)
+	for _, c := range children(n) {
+		if c == nil {
+			continue
+		}
+		cs, ce := t.treeSpan(c)
+		if cs == -1 {
+			continue
+		}
+		if start == -1 || cs < start {
+			start = cs
+		}
+		if ce > end {
+			end = ce
+		}
+	}
+	return
+}
@@ -0,0 +1,104 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+var sourceMapTests = []struct {
+	name   string
+	input  string
+	id     ID     // The ID of the node
+	source string // The expected input of the node
+}{
+	{
+		name:   "Paragraph",
+		input:  "First paragraph.\n\nSecond\nparagraph.\n\nThird.",
+		id:     2,
+		source: "Second\nparagraph.",
+	},
+	{
+		name:   "Block quote",
+		input:  "Quoted:\n\n    Block quote\n    lines.\n\nAfter.",
+		id:     2,
+		source: "Block quote\n    lines.",
+	},
+	{
+		name:   "Section",
+		input:  "Title\n=====\n\nSection body.\n",
+		id:     1,
+		source: "Title\n=====\n\nSection body.",
+	},
+}
+
+func TestTreeSourceMap(t *testing.T) {
+	for _, tt := range sourceMapTests {
+		tr, _ := Parse(tt.name, tt.input)
+		var source string
+		found := false
+		for _, e := range tr.SourceMap() {
+			if e.ID == tt.id {
+				source = tt.input[e.Start:e.End]
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("Test: %q\n\t    Got: no entry for ID %d\n\n",
+				tt.name, tt.id)
+		} else if source != tt.source {
+			t.Errorf("Test: %q\n\t    Got: source = %q, "+
+				"Expect: %q\n\n", tt.name, source, tt.source)
+		}
+	}
+}
+
+var lineSpanTests = []struct {
+	name  string
+	input string
+	nType NodeType
+	start Line
+	end   Line
+}{
+	{
+		name:  "LiteralBlock",
+		input: "Paragraph::\n\n    one\n    two\n    three\n\nAfter.\n",
+		nType: NodeLiteralBlock,
+		start: 3,
+		end:   5,
+	},
+	{
+		name:  "SectionOverline",
+		input: "=====\nTitle\n=====\n\nSection body.\n",
+		nType: NodeSection,
+		start: 1,
+		end:   5,
+	},
+}
+
+func TestTreeLineSpan(t *testing.T) {
+	for _, tt := range lineSpanTests {
+		tr, _ := Parse(tt.name, tt.input)
+		var node Node
+		Walk(tr.Nodes, func(n Node) bool {
+			if node == nil && n.NodeType() == tt.nType {
+				node = n
+			}
+			return true
+		})
+		if node == nil {
+			t.Errorf("Test: %q\n\t    Got: no %s node\n\n", tt.name, tt.nType)
+			continue
+		}
+		start, end := tr.LineSpan(node)
+		if start != tt.start || end != tt.end {
+			t.Errorf("Test: %q\n\t    Got: span = (%d, %d), "+
+				"Expect: (%d, %d)\n\n", tt.name, start, end,
+				tt.start, tt.end)
+		}
+		if e := tr.EndLine(node); e != tt.end {
+			t.Errorf("Test: %q\n\t    Got: EndLine = %d, Expect: %d\n\n",
+				tt.name, e, tt.end)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "strings"
+
+// Stats contains statistics of a parsed document.
+type Stats struct {
+	Sections      int // Number of sections
+	Paragraphs    int // Number of paragraphs, not counting system messages
+	LiteralBlocks int // Number of literal blocks
+	BlockQuotes   int // Number of block quotes
+	Lists         int // Number of bullet, enumerated, and definition lists
+
+	// Words is the number of words in the inline text of the document: the
+	// section titles, paragraphs, and definition terms. Inline markup,
+	// adornments, comments, directives, literal blocks, and system messages
+	// are not counted.
+	Words int
+
+	// Diagnostics is the number of diagnostics of the parser by severity
+	// name, for example "WARNING".
+	Diagnostics map[string]int
+}
+
+// Stats returns the statistics of the parsed document.
+func (t *Tree) Stats() Stats {
+	s := Stats{Diagnostics: make(map[string]int)}
+	Walk(t.Nodes, func(n Node) bool {
+		switch n := n.(type) {
+		case *SectionNode:
+			s.Sections++
+			s.Words += countWords(n.Title.Text)
+		case *ParagraphNode:
+			s.Paragraphs++
+			s.Words += countWords(n.Text)
+			return false
+		case *DefinitionTermNode:
+			s.Words += countWords(n.Text)
+		case *ClassifierNode:
+			s.Words += countWords(n.Text)
+		case *LiteralBlockNode:
+			s.LiteralBlocks++
+		case *BlockQuoteNode:
+			s.BlockQuotes++
+		case *BulletListNode, *EnumListNode, *DefinitionListNode:
+			s.Lists++
+		case *SystemMessageNode:
+			// The text of system messages is generated by the parser.
+			return false
+		}
+		return true
+	})
+	for _, d := range t.Diagnostics {
+		s.Diagnostics[d.Severity.String()]++
+	}
+	return s
+}
+
+// countWords returns the number of words in the inline markup text.
+func countWords(text string) int {
+	return len(strings.Fields(plainInline(text)))
+}
@@ -0,0 +1,38 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+func TestTreeStats(t *testing.T) {
+	input := "Document Title\n==============\n\n" +
+		"A *short* paragraph.\n\n" +
+		"- one item\n- two\n\n" +
+		".. A comment is not counted.\n\n" +
+		"Section\n-----\n\n" +
+		"Last paragraph ``here``."
+	tr, _ := Parse("TestTreeStats", input)
+	s := tr.Stats()
+	for _, tt := range []struct {
+		name        string
+		got, expect int
+	}{
+		{"Sections", s.Sections, 2},
+		{"Paragraphs", s.Paragraphs, 4},
+		{"Lists", s.Lists, 1},
+		{"LiteralBlocks", s.LiteralBlocks, 0},
+		{"BlockQuotes", s.BlockQuotes, 0},
+		// Titles 2 + 1, paragraphs 3 + 2 + 1 + 3
+		{"Words", s.Words, 12},
+		{"Diagnostics[INFO]", s.Diagnostics["INFO"], 0},
+		// The underline of "Section" is too short
+		{"Diagnostics[WARNING]", s.Diagnostics["WARNING"], 1},
+	} {
+		if tt.got != tt.expect {
+			t.Errorf("Got: %s = %d, Expect: %d", tt.name, tt.got,
+				tt.expect)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseStream parses the reStructuredText read from r and calls emit with each
+// top-level node as soon as it is complete, without keeping the parsed document
+// in memory. The input is parsed in chunks, a chunk ends before the title of
+// each top-level section, so only the lines of the section being read are
+// kept. The input before the first section title is parsed as one chunk. The
+// nodes are numbered, and their lines are counted, from the start of the input
+// as if the input was parsed with Parse. The reference names and the section
+// title names of the previous chunks are kept, so duplicate names are reported
+// like Parse does.
+//
+// Each chunk is parsed separately, and the passes run by Parse after parsing,
+// like the numbering of auto-numbered footnotes, run for each chunk, so
+// footnotes and their references are only matched within a chunk. The system
+// messages of the parser are emitted as part of the nodes. Parsing stops at
+// the first error returned by emit or by reading r, and the error is returned.
+func ParseStream(name string, r io.Reader, emit func(Node) error) error {
+	return ParseStreamDiagnostics(name, r, emit, nil)
+}
+
+// ParseStreamDiagnostics is like ParseStream, but the diagnostics of each chunk
+// are passed to report before the nodes of the chunk are emitted, like the
+// Diagnostics of a Tree returned by Parse. report may be nil.
+func ParseStreamDiagnostics(name string, r io.Reader, emit func(Node) error,
+	report func(*Diagnostic)) error {
+
+	t := New(name, "")
+	s := &streamState{tree: t, levels: new(sectionLevels), emit: emit,
+		report: report}
+	lr := &lineReader{r: bufio.NewReader(r)}
+	prevBlank := true
+	for {
+		line, ok := lr.next()
+		if !ok {
+			break
+		}
+		if s.start == 0 && len(s.chunk) == 0 {
+			line = stripBOM(line)
+		}
+		if prevBlank && s.startsSection(line, lr) {
+			if err := s.flush(); err != nil {
+				return err
+			}
+		}
+		s.chunk = append(s.chunk, line)
+		prevBlank = lineIsBlank(line)
+	}
+	if lr.err != nil {
+		return lr.err
+	}
+	return s.flush()
+}
+
+// streamState holds the state of ParseStream between chunks.
+type streamState struct {
+	tree   *Tree             // Numbers the nodes of the chunks
+	levels *sectionLevels    // The section levels encountered so far
+	top    *sectionLevel     // The adornment of top-level sections
+	chunk  []string          // The lines of the current chunk
+	start  int               // The number of lines before the chunk
+	emit   func(Node) error  // Receives the top-level nodes
+	report func(*Diagnostic) // Receives the diagnostics, may be nil
+}
+
+// flush parses the current chunk, reports its diagnostics, and emits its
+// nodes. The section levels of the chunk are kept for the next chunk, without
+// the parsed sections.
+func (s *streamState) flush() error {
+	text := strings.Join(s.chunk, "\n")
+	line := Line(s.start + 1)
+	s.start += len(s.chunk)
+	s.chunk = nil
+	if lineIsBlank(text) {
+		return nil
+	}
+	text, _ = replaceInvalidUTF8(text)
+	nt := s.tree.nestedTree(text)
+	nt.sectionLevels = s.levels
+	if s.tree.titleNames == nil {
+		s.tree.titleNames = make(map[string]bool)
+	}
+	nt.titleNames = s.tree.titleNames
+	if len(s.tree.Names) > 0 {
+		nt.Names = make(map[string]Node, len(s.tree.Names))
+		for name, n := range s.tree.Names {
+			nt.Names[name] = n
+		}
+	}
+	nt.Parse(text, nt)
+	nt.checkBody()
+	nodes := s.tree.adoptNested(nt, line, 0)
+	if s.report != nil {
+		for _, d := range s.tree.Diagnostics {
+			s.report(d)
+		}
+	}
+	s.tree.Messages, s.tree.Diagnostics = nil, nil
+	for _, lvl := range s.levels.levels {
+		lvl.sections = nil
+	}
+	s.levels.lastSectionNode = nil
+	for _, n := range nodes {
+		if err := s.emit(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startsSection reports whether line, which follows a blank line, is the title
+// or the overline of a top-level section. The adornment of top-level sections
+// is the adornment of the first section title in the input. The lines after
+// line are read from lr.
+func (s *streamState) startsSection(line string, lr *lineReader) bool {
+	var rChar rune
+	var overLine bool
+	next, _ := lr.peek(0)
+	if r, ok := adornmentLine(line); ok {
+		title, _ := lr.peek(0)
+		under, _ := lr.peek(1)
+		if u, ok := adornmentLine(under); !ok || u != r || lineIsBlank(title) {
+			return false
+		}
+		rChar, overLine = r, true
+	} else if r, ok := adornmentLine(next); ok && !lineIsBlank(line) &&
+		lineIndent(line, defaultTabWidth) == 0 &&
+		(utf8.RuneCountInString(next) >= 4 ||
+			utf8.RuneCountInString(next) >= utf8.RuneCountInString(line)) {
+		rChar = r
+	} else {
+		return false
+	}
+	if s.top == nil {
+		s.top = &sectionLevel{rChar: rChar, overLine: overLine}
+	}
+	return s.top.rChar == rChar && s.top.overLine == overLine
+}
+
+// adornmentLine returns the rune of line if line is a section adornment, a
+// line made of a single repeated adornment rune.
+func adornmentLine(line string) (rune, bool) {
+	if line == "" {
+		return 0, false
+	}
+	r, _ := utf8.DecodeRuneInString(line)
+	if strings.Trim(line, string(r)) != "" {
+		return 0, false
+	}
+	for _, a := range sectionAdornments {
+		if a == r {
+			return r, true
+		}
+	}
+	return 0, false
+}
+
+// lineReader reads lines from r, with lookahead.
+type lineReader struct {
+	r   *bufio.Reader
+	buf []string // Lines read ahead
+	eof bool     // The end of r has been reached
+	err error    // The error reading r, other than io.EOF
+}
+
+// fill reads lines from r until there are n lines in buf or r is exhausted.
+func (lr *lineReader) fill(n int) {
+	for len(lr.buf) < n && !lr.eof {
+		line, err := lr.r.ReadString('\n')
+		if err != nil {
+			lr.eof = true
+			if err != io.EOF {
+				lr.err = err
+				return
+			}
+			if line == "" {
+				return
+			}
+		}
+		lr.buf = append(lr.buf, strings.TrimSuffix(line, "\n"))
+	}
+}
+
+// peek returns the line n lines after the next line without consuming it.
+func (lr *lineReader) peek(n int) (string, bool) {
+	lr.fill(n + 1)
+	if n >= len(lr.buf) {
+		return "", false
+	}
+	return lr.buf[n], true
+}
+
+// next returns the next line of input. false is returned at the end of input.
+func (lr *lineReader) next() (string, bool) {
+	lr.fill(1)
+	if len(lr.buf) == 0 || lr.err != nil {
+		return "", false
+	}
+	line := lr.buf[0]
+	lr.buf = lr.buf[1:]
+	return line, true
+}
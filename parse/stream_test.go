@@ -0,0 +1,168 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// streamDocument returns a document with an introduction paragraph followed by
+// n top-level sections, each with a subsection.
+func streamDocument(n int) string {
+	doc := "Introduction paragraph.\n\n"
+	for i := 1; i <= n; i++ {
+		title := fmt.Sprintf("Section %d", i)
+		sub := fmt.Sprintf("Subsection %d", i)
+		doc += fmt.Sprintf("%s\n%s\n\nParagraph %d.\n\n"+
+			"- Item one.\n- Item two.\n\n%s\n%s\n\n"+
+			"Paragraph in subsection %d.\n\n", title,
+			strings.Repeat("=", len(title)), i, sub,
+			strings.Repeat("-", len(sub)), i)
+	}
+	return doc
+}
+
+func TestParseStream(t *testing.T) {
+	input := streamDocument(500)
+	var nodes NodeList
+	err := ParseStream("stream", strings.NewReader(input), func(n Node) error {
+		nodes = append(nodes, n)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Got: err = %v, Expect: nil", err)
+	}
+	if len(nodes) != 501 {
+		t.Fatalf("Got: len(nodes) = %d, Expect: 501", len(nodes))
+	}
+	tr, _ := Parse("stream", strings.TrimSuffix(input, "\n"))
+	if len(tr.Nodes) != len(nodes) {
+		t.Fatalf("Got: len(Parse().Nodes) = %d, Expect: %d",
+			len(tr.Nodes), len(nodes))
+	}
+	for num, n := range nodes {
+		if !reflect.DeepEqual(n, tr.Nodes[num]) {
+			t.Fatalf("Got: node %d = %#v, Expect: %#v", num, n,
+				tr.Nodes[num])
+		}
+	}
+}
+
+func TestParseStreamEmitError(t *testing.T) {
+	stop := errors.New("stop")
+	var count int
+	err := ParseStream("stream", strings.NewReader(streamDocument(10)),
+		func(n Node) error {
+			count++
+			if count == 3 {
+				return stop
+			}
+			return nil
+		})
+	if err != stop || count != 3 {
+		t.Errorf("Got: err = %v, count = %d, Expect: %v, 3", err, count,
+			stop)
+	}
+}
+
+func TestParseStreamByteOrderMark(t *testing.T) {
+	var nodes NodeList
+	err := ParseStream("stream", strings.NewReader("\ufeffParagraph."),
+		func(n Node) error {
+			nodes = append(nodes, n)
+			return nil
+		})
+	if err != nil || len(nodes) != 1 {
+		t.Fatalf("Got: err = %v, len(nodes) = %d, Expect: nil, 1", err,
+			len(nodes))
+	}
+	if p := nodes[0].(*ParagraphNode); p.Text != "Paragraph." {
+		t.Errorf("Got: Text = %q, Expect: %q", p.Text, "Paragraph.")
+	}
+}
+
+func TestParseStreamFootnotes(t *testing.T) {
+	input := "Intro.\n\n::\n\n    Lit\n\nTitle\n=====\n\nPara [#]_.\n\n" +
+		".. [#] Note."
+	var nodes NodeList
+	err := ParseStream("stream", strings.NewReader(input), func(n Node) error {
+		nodes = append(nodes, n)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Got: err = %v, Expect: nil", err)
+	}
+	tr, _ := Parse("stream", input)
+	if diff := DiffNodes(tr.Nodes, nodes); diff != "" {
+		t.Fatalf("Got: nodes differ from Parse:\n%s", diff)
+	}
+	var fn *FootnoteNode
+	Walk(nodes, func(n Node) bool {
+		if f, ok := n.(*FootnoteNode); ok {
+			fn = f
+		}
+		return true
+	})
+	if fn == nil || fn.Number != 1 {
+		t.Errorf("Got: footnote = %#v, Expect: Number = 1", fn)
+	}
+}
+
+func TestParseStreamDiagnostics(t *testing.T) {
+	input := "Intro.\n\nTitle one\n=====\n\nText.\n\nTitle two\n=====\n\nText."
+	var diags []*Diagnostic
+	err := ParseStreamDiagnostics("stream", strings.NewReader(input),
+		func(n Node) error { return nil },
+		func(d *Diagnostic) { diags = append(diags, d) })
+	if err != nil {
+		t.Fatalf("Got: err = %v, Expect: nil", err)
+	}
+	tr, _ := Parse("stream", input)
+	if len(tr.Diagnostics) != 2 || len(diags) != len(tr.Diagnostics) {
+		t.Fatalf("Got: len(diags) = %d, Expect: %d", len(diags),
+			len(tr.Diagnostics))
+	}
+	for num, d := range diags {
+		if e := tr.Diagnostics[num]; d.String() != e.String() {
+			t.Errorf("Got: diagnostic %d = %q, Expect: %q", num,
+				d.String(), e.String())
+		}
+	}
+}
+
+func TestParseStreamDuplicateNames(t *testing.T) {
+	input := "One\n===\n\nSub\n---\n\nText.\n\n" +
+		"Two\n===\n\nSub\n---\n\nText.\n\n" +
+		"Three\n=====\n\nSub\n---\n\nText."
+	var nodes NodeList
+	var diags []*Diagnostic
+	err := ParseStreamDiagnostics("stream", strings.NewReader(input),
+		func(n Node) error {
+			nodes = append(nodes, n)
+			return nil
+		},
+		func(d *Diagnostic) { diags = append(diags, d) })
+	if err != nil {
+		t.Fatalf("Got: err = %v, Expect: nil", err)
+	}
+	tr, _ := Parse("stream", input)
+	if len(tr.Diagnostics) != 2 || len(diags) != len(tr.Diagnostics) {
+		t.Fatalf("Got: len(diags) = %d, Expect: %d", len(diags),
+			len(tr.Diagnostics))
+	}
+	for num, d := range diags {
+		if e := tr.Diagnostics[num]; d.String() != e.String() {
+			t.Errorf("Got: diagnostic %d = %q, Expect: %q", num,
+				d.String(), e.String())
+		}
+	}
+	if diff := DiffNodes(tr.Nodes, nodes); diff != "" {
+		t.Fatalf("Got: nodes differ from Parse:\n%s", diff)
+	}
+}
@@ -0,0 +1,64 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"strings"
+	"unicode"
+)
+
+// splitSubstitution splits the text of an explicit markup block into the
+// substitution text between the vertical bars and the text of the embedded
+// directive. The kind of text must be explicitSubstitutionDefinition.
+func splitSubstitution(text string) (name, body string) {
+	end := strings.Index(text[1:], "|") + 1
+	return text[1:end], strings.TrimLeftFunc(text[end+1:], unicode.IsSpace)
+}
+
+// substitutionDefinition returns the SubstitutionDefinitionNode of the
+// substitution definition at item i with the substitution text name. body is
+// the embedded directive, it is parsed like the directive of an explicit markup
+// block and its node becomes the content of the definition. A system message of
+// the directive is returned in place of the definition. An
+// errorInvalidSubstitutionDefinition system message is returned if body is not
+// a directive, or the directive has no node of its own, like the class
+// directive.
+func (t *Tree) substitutionDefinition(name, body string, i *item) Node {
+	d, ok := parseDirective(body)
+	if !ok || d.name == "class" {
+		return t.substitutionError(name, body, i)
+	}
+	n := newSubstitutionDefinition(i, name, &t.id)
+	dn := t.directive(d, i)
+	switch {
+	case dn == nil:
+		return t.substitutionError(name, body, i)
+	case dn.NodeType() == NodeSystemMessage:
+		return dn
+	}
+	n.NodeList = append(n.NodeList, dn)
+	return n
+}
+
+// substitutionError returns an errorInvalidSubstitutionDefinition system
+// message for the substitution definition at item i with the substitution text
+// name and body. The system message contains the text of the definition.
+func (t *Tree) substitutionError(name, body string, i *item) Node {
+	s := newSystemMessage(&item{Type: itemSystemMessage, Line: i.Line},
+		errorInvalidSubstitutionDefinition, &t.id)
+	text := "Substitution definition \"" + name + "\" empty or invalid."
+	s.NodeList = append(s.NodeList, newParagraph(&item{
+		Text:   text,
+		Length: len(text),
+	}, &t.id))
+	block := strings.TrimRight(".. |"+name+"| "+body, " ")
+	s.NodeList = append(s.NodeList, newLiteralBlock(&item{
+		Type:   itemLiteralBlock,
+		Text:   block,
+		Length: len(block),
+	}, &t.id))
+	t.addMessage(s, i.StartPosition)
+	return s
+}
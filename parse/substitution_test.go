@@ -0,0 +1,126 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+var substitutionDefinitionTests = []struct {
+	name   string
+	input  string
+	subst  string // The expected substitution text
+	uri    string // The expected URI of the image
+	target string // The expected target of the image
+}{
+	{
+		name:  "Image",
+		input: ".. |logo| image:: logo.png\n",
+		subst: "logo",
+		uri:   "logo.png",
+	},
+	{
+		name:   "Image with an option",
+		input:  ".. |the logo| image:: logo.png\n   :target: http://x.org\n",
+		subst:  "the logo",
+		uri:    "logo.png",
+		target: "http://x.org",
+	},
+}
+
+func TestParseSubstitutionDefinition(t *testing.T) {
+	for _, tt := range substitutionDefinitionTests {
+		tr, errors := Parse(tt.name, tt.input)
+		if len(errors) != 0 {
+			t.Errorf("Test: %q\n\t    Got: len(errors) = %d, "+
+				"Expect: 0\n\n", tt.name, len(errors))
+		}
+		if len(tr.Nodes) != 1 {
+			t.Errorf("Test: %q\n\t    Got: len(Nodes) = %d, "+
+				"Expect: 1\n\n", tt.name, len(tr.Nodes))
+			continue
+		}
+		s, ok := tr.Nodes[0].(*SubstitutionDefinitionNode)
+		if !ok {
+			t.Errorf("Test: %q\n\t    Got: Nodes[0] = %s, "+
+				"Expect: NodeSubstitutionDefinition\n\n", tt.name,
+				tr.Nodes[0].NodeType())
+			continue
+		}
+		if s.Name != tt.subst || len(s.Names) != 1 ||
+			s.Names[0] != tt.subst {
+			t.Errorf("Test: %q\n\t    Got: Name = %q, Names = %q, "+
+				"Expect: %q\n\n", tt.name, s.Name, s.Names, tt.subst)
+		}
+		if len(s.NodeList) != 1 {
+			t.Errorf("Test: %q\n\t    Got: len(NodeList) = %d, "+
+				"Expect: 1\n\n", tt.name, len(s.NodeList))
+			continue
+		}
+		img, ok := s.NodeList[0].(*ImageNode)
+		if !ok || img.URI != tt.uri || img.Target != tt.target {
+			t.Errorf("Test: %q\n\t    Got: NodeList[0] = %#v, Expect: "+
+				"image %q with target %q\n\n", tt.name,
+				s.NodeList[0], tt.uri, tt.target)
+		}
+		if errs := tr.Validate(); len(errs) != 0 {
+			t.Errorf("Test: %q\n\t    Got: Validate() = %v, Expect: no "+
+				"errors\n\n", tt.name, errs)
+		}
+	}
+}
+
+var invalidSubstitutionDefinitionTests = []struct {
+	name    string
+	input   string
+	message parserMessage // The expected system message
+}{
+	{"Text", ".. |x| text\n", errorInvalidSubstitutionDefinition},
+	{"Empty", ".. |x|\n", errorInvalidSubstitutionDefinition},
+	{"Class directive", ".. |x| class:: y\n\nPara.",
+		errorInvalidSubstitutionDefinition},
+	{"Unknown directive", ".. |x| unknown:: y\n",
+		errorUnknownDirectiveType},
+}
+
+func TestParseSubstitutionDefinitionInvalid(t *testing.T) {
+	for _, tt := range invalidSubstitutionDefinitionTests {
+		tr, errors := Parse(tt.name, tt.input)
+		if len(errors) != 1 {
+			t.Errorf("Test: %q\n\t    Got: len(errors) = %d, "+
+				"Expect: 1\n\n", tt.name, len(errors))
+			continue
+		}
+		if m := errors[0].(*SystemMessageNode); m.MessageType != tt.message {
+			t.Errorf("Test: %q\n\t    Got: MessageType = %s, "+
+				"Expect: %s\n\n", tt.name, m.MessageType, tt.message)
+		}
+		Walk(tr.Nodes, func(n Node) bool {
+			if n.NodeType() == NodeSubstitutionDefinition {
+				t.Errorf("Test: %q\n\t    Got: %s, Expect: no "+
+					"substitution definition\n\n", tt.name,
+					n.NodeType())
+			}
+			return true
+		})
+	}
+}
+
+func TestSubstitutionDefinitionJSONRoundTrip(t *testing.T) {
+	tr, _ := Parse("round trip", ".. |logo| image:: logo.png\n")
+	data, err := json.Marshal(tr.Nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got NodeList
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal: %s\n%s", err, data)
+	}
+	if !reflect.DeepEqual(got, tr.Nodes) {
+		t.Errorf("Got: %s\n\t    Expect: %s", data, tr.Nodes)
+	}
+}
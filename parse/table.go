@@ -0,0 +1,339 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"sort"
+	"strings"
+)
+
+// gridCell is a cell of a grid table found by scanGridCells. The borders of
+// the cell are given as line and column indexes into the lines of the table.
+// A cell spanning several rows or columns of the grid has borders further
+// apart than a single row or column.
+type gridCell struct {
+	top, left, bottom, right int
+	text                     string // The text inside the cell borders
+	line, col                int    // The line and column index of text
+}
+
+// gridCorners is a list of top left cell corners, given as line and column
+// indexes, sorted by line and then column.
+type gridCorners [][2]int
+
+func (c gridCorners) Len() int      { return len(c) }
+func (c gridCorners) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+func (c gridCorners) Less(i, j int) bool {
+	if c[i][0] != c[j][0] {
+		return c[i][0] < c[j][0]
+	}
+	return c[i][1] < c[j][1]
+}
+
+// gridScanner finds the cells of a grid table using the same method as
+// docutils: starting from the top left corner of a cell, the borders are
+// followed clockwise until the corner is reached again.
+type gridScanner struct {
+	block [][]rune // The lines of the table, see gridPadding
+	done  []int    // The bottom line of the last cell found in each column
+}
+
+// gridPadding is added to the lines of a grid table after each wide character,
+// like a wide CJK ideograph, so that the index of a rune in the line is its
+// display column. The padding is removed from the text of the cells.
+const gridPadding = '\x00'
+
+// gridLine returns the runes of the table line with gridPadding after each wide
+// character.
+func gridLine(line string) (runes []rune) {
+	for _, r := range line {
+		runes = append(runes, r)
+		if runeWidth(r, false) == 2 {
+			runes = append(runes, gridPadding)
+		}
+	}
+	return
+}
+
+// scanGridCells returns the cells of the grid table in lines ordered by their
+// top left corners. The lines must be the complete table, beginning and ending
+// with a border line. The header separator ("=") is treated as a row
+// separator. nil is returned if the table is malformed.
+func scanGridCells(lines []string) []gridCell {
+	if len(lines) < 3 {
+		return nil
+	}
+	s := &gridScanner{}
+	for _, line := range lines {
+		line = strings.Replace(strings.TrimRight(line, " \t"), "=", "-", -1)
+		s.block = append(s.block, gridLine(line))
+	}
+	width := len(s.block[0])
+	for _, line := range s.block {
+		if len(line) != width {
+			return nil
+		}
+	}
+	s.done = make([]int, width)
+	for i := range s.done {
+		s.done[i] = -1
+	}
+	var cells []gridCell
+	corners := gridCorners{{0, 0}}
+	for len(corners) > 0 {
+		top, left := corners[0][0], corners[0][1]
+		corners = corners[1:]
+		if top == len(s.block)-1 || left == width-1 || top <= s.done[left] {
+			continue
+		}
+		bottom, right, ok := s.scanRight(top, left)
+		if !ok {
+			continue
+		}
+		for col := left; col < right; col++ {
+			s.done[col] = bottom - 1
+		}
+		text, line, col := s.cellText(top, left, bottom, right)
+		cells = append(cells, gridCell{
+			top: top, left: left, bottom: bottom, right: right,
+			text: text, line: line, col: col,
+		})
+		corners = append(corners, [2]int{top, right},
+			[2]int{bottom, left})
+		sort.Sort(corners)
+	}
+	return cells
+}
+
+// scanRight follows the top border of the cell at top, left to each possible
+// top right corner and returns the bottom right corner of the cell.
+func (s *gridScanner) scanRight(top, left int) (bottom, right int, ok bool) {
+	line := s.block[top]
+	for i := left + 1; i < len(line); i++ {
+		switch line[i] {
+		case '+':
+			if bottom, ok = s.scanDown(top, left, i); ok {
+				return bottom, i, true
+			}
+		case '-':
+		default:
+			return 0, 0, false
+		}
+	}
+	return 0, 0, false
+}
+
+// scanDown follows the right border of the cell down to each possible bottom
+// right corner and returns the bottom line of the cell.
+func (s *gridScanner) scanDown(top, left, right int) (bottom int, ok bool) {
+	for i := top + 1; i < len(s.block); i++ {
+		switch s.block[i][right] {
+		case '+':
+			if s.scanLeft(top, left, i, right) {
+				return i, true
+			}
+		case '|':
+		default:
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// scanLeft follows the bottom border of the cell back to the left border and
+// returns true if the cell is closed.
+func (s *gridScanner) scanLeft(top, left, bottom, right int) bool {
+	line := s.block[bottom]
+	for i := right - 1; i > left; i-- {
+		if line[i] != '+' && line[i] != '-' {
+			return false
+		}
+	}
+	return line[left] == '+' && s.scanUp(top, left, bottom)
+}
+
+// scanUp follows the left border of the cell up to the top left corner.
+func (s *gridScanner) scanUp(top, left, bottom int) bool {
+	for i := bottom - 1; i > top; i-- {
+		if r := s.block[i][left]; r != '+' && r != '|' {
+			return false
+		}
+	}
+	return true
+}
+
+// cellText returns the text inside the borders of a cell, and the line and
+// column index in the table of the start of the text. Trailing whitespace and
+// the indentation common to all lines is removed. The lines of cells spanning
+// several rows are merged into a single text.
+func (s *gridScanner) cellText(top, left, bottom, right int) (string, int,
+	int) {
+
+	var lines []string
+	indent, first := -1, -1
+	for num, line := range s.block[top+1 : bottom] {
+		text := strings.Replace(string(line[left+1:right]),
+			string(gridPadding), "", -1)
+		text = strings.TrimRight(text, " \t")
+		if trimmed := strings.TrimLeft(text, " \t"); trimmed != "" {
+			if n := len(text) - len(trimmed); indent == -1 || n < indent {
+				indent = n
+			}
+			if first == -1 {
+				first = num
+			}
+		}
+		lines = append(lines, text)
+	}
+	if first == -1 {
+		return "", 0, 0
+	}
+	for num, line := range lines {
+		if len(line) >= indent && indent > 0 {
+			lines[num] = line[indent:]
+		}
+	}
+	text := strings.Trim(strings.Join(lines, "\n"), "\n")
+	return text, top + 1 + first, left + 1 + indent
+}
+
+// isGridTableBorder returns true if line is a border line of a grid table,
+// like "+-----+----+" or the header separator "+=====+====+".
+func isGridTableBorder(line string) bool {
+	line = strings.TrimRight(line, " \t")
+	return len(line) >= 3 && line[0] == '+' && line[len(line)-1] == '+' &&
+		(strings.Trim(line, "+-") == "" || strings.Trim(line, "+=") == "")
+}
+
+// gridTableError returns the reason the grid table in lines is malformed. The
+// lines are the text block beginning with the top border of the table. An
+// empty reason is returned for a table with a valid border whose cells cannot
+// be found, and ok is true if the table is well formed.
+func gridTableError(lines []string) (reason string, ok bool) {
+	if len(lines) < 3 || !isGridTableBorder(lines[len(lines)-1]) {
+		return "No bottom table border found.", false
+	}
+	width := textWidth(strings.TrimRight(lines[0], " \t"), false)
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if textWidth(line, false) != width ||
+			!strings.HasSuffix(line, "+") && !strings.HasSuffix(line, "|") {
+			return "Bottom/right table border does not match top " +
+				"border.", false
+		}
+	}
+	return "", scanGridCells(lines) != nil
+}
+
+// checkGridTable returns the TableNode of the grid table beginning the
+// paragraph p, or nil if p does not begin with the top border of a grid table.
+// The table takes the place of p and takes its ID. If the table is malformed,
+// for example because its bottom border is missing, an error level system
+// message containing the text of the table as a literal block is returned
+// instead, so the text following the table is parsed as usual.
+func (t *Tree) checkGridTable(p *ParagraphNode) Node {
+	lines := strings.Split(p.Text, "\n")
+	if !isGridTableBorder(lines[0]) || strings.Contains(lines[0], "=") {
+		return nil
+	}
+	// The table or the system message replaces the paragraph, and takes its
+	// ID.
+	t.id = int(p.ID) - 1
+	reason, ok := gridTableError(lines)
+	if ok {
+		return t.gridTable(p, lines)
+	}
+	s := newSystemMessage(&item{Type: itemSystemMessage, Line: p.Line},
+		errorMalformedTable, &t.id)
+	text := errorMalformedTable.Message()
+	if reason != "" {
+		text += "\n" + reason
+	}
+	s.NodeList = append(s.NodeList, newParagraph(&item{
+		Text:   text,
+		Length: len(text),
+	}, &t.id))
+	s.NodeList = append(s.NodeList, newLiteralBlock(&item{
+		Type:   itemLiteralBlock,
+		Text:   p.Text,
+		Length: len(p.Text),
+	}, &t.id))
+	t.addMessage(s, p.StartPosition)
+	return s
+}
+
+// gridTable returns the TableNode of the well formed grid table in lines, the
+// text of the paragraph p. The rows and columns of the table are the rows and
+// columns of the grid formed by the borders of all cells, a cell spanning
+// several of them has MoreRows or MoreCols set. The rows above the header
+// separator ("=") are the header rows of the table.
+func (t *Tree) gridTable(p *ParagraphNode, lines []string) *TableNode {
+	cells := scanGridCells(lines)
+	rows, cols := gridBoundaries(cells)
+	n := newTable(&item{Line: p.Line, StartPosition: p.StartPosition}, &t.id)
+	for k := 1; k < len(cols); k++ {
+		n.ColWidths = append(n.ColWidths, cols[k]-cols[k-1]-1)
+	}
+	for num, line := range lines {
+		if isGridTableBorder(line) && strings.Contains(line, "=") {
+			n.HeadRows = sort.SearchInts(rows, num)
+			break
+		}
+	}
+	indent := int(p.StartPosition) - 1
+	if indent < 0 {
+		indent = 0
+	}
+	var row *TableRowNode
+	for _, c := range cells {
+		if row == nil || int(row.Line) != int(p.Line)+c.top {
+			row = newTableRow(&item{Line: p.Line + Line(c.top)}, &t.id)
+			n.NodeList.append(row)
+		}
+		e := newTableEntry(&item{
+			Line:          p.Line + Line(c.top),
+			StartPosition: StartPosition(indent + c.left + 1),
+		}, &t.id)
+		e.MoreRows = sort.SearchInts(rows, c.bottom) -
+			sort.SearchInts(rows, c.top) - 1
+		e.MoreCols = sort.SearchInts(cols, c.right) -
+			sort.SearchInts(cols, c.left) - 1
+		e.NodeList = t.parseCell(c, p.Line, indent)
+		row.NodeList.append(e)
+	}
+	return n
+}
+
+// gridBoundaries returns the sorted line indexes of the row borders and the
+// sorted column indexes of the column borders of the grid formed by cells.
+func gridBoundaries(cells []gridCell) (rows, cols []int) {
+	seenRow, seenCol := make(map[int]bool), make(map[int]bool)
+	for _, c := range cells {
+		for _, r := range []int{c.top, c.bottom} {
+			if !seenRow[r] {
+				seenRow[r] = true
+				rows = append(rows, r)
+			}
+		}
+		for _, col := range []int{c.left, c.right} {
+			if !seenCol[col] {
+				seenCol[col] = true
+				cols = append(cols, col)
+			}
+		}
+	}
+	sort.Ints(rows)
+	sort.Ints(cols)
+	return
+}
+
+// parseCell parses the text of the table cell c as a nested document using the
+// options of the tree and returns the parsed nodes. line is the line of the top
+// border of the table and indent is its indentation, the lines and columns of
+// the nodes are relative to the input of the tree. Cells can contain any body
+// elements, for example several paragraphs or a list.
+func (t *Tree) parseCell(c gridCell, line Line, indent int) NodeList {
+	return t.parseNested(c.text, line+Line(c.line), indent+c.col)
+}
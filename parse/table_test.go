@@ -0,0 +1,234 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"strings"
+	"testing"
+)
+
+var scanGridCellsTests = []struct {
+	name  string
+	input string
+	cells []string // The expected text of the cells
+}{
+	{
+		name: "Two cells",
+		input: `+-------+-------+
+| One   | Two   |
++-------+-------+`,
+		cells: []string{"One", "Two"},
+	},
+	{
+		name: "Header row",
+		input: `+-------+-------+
+| Head  | Head  |
++=======+=======+
+| Body  | Body  |
++-------+-------+`,
+		cells: []string{"Head", "Head", "Body", "Body"},
+	},
+	{
+		name: "Row span",
+		input: `+-------+-------+
+| A     | B     |
++-------+ two   |
+| C     | rows  |
++-------+-------+`,
+		cells: []string{"A", "B\ntwo\nrows", "C"},
+	},
+	{
+		name: "Column span",
+		input: `+-------+-------+
+| Two columns   |
++-------+-------+
+| A     | B     |
++-------+-------+`,
+		cells: []string{"Two columns", "A", "B"},
+	},
+	{
+		name: "Indented cell text",
+		input: `+-----------+
+|   Quoted  |
+|   text    |
++-----------+`,
+		cells: []string{"Quoted\ntext"},
+	},
+	{
+		name: "Wide characters",
+		input: `+------+-----+
+| 日本 | é   |
++------+-----+`,
+		cells: []string{"日本", "é"},
+	},
+	{
+		name: "Unclosed table",
+		input: `+-------+
+| A     |
+| B     |`,
+	},
+}
+
+func TestScanGridCells(t *testing.T) {
+	for _, tt := range scanGridCellsTests {
+		cells := scanGridCells(strings.Split(tt.input, "\n"))
+		if len(cells) != len(tt.cells) {
+			t.Errorf("Test: %q\n\t    Got: len(cells) = %d, "+
+				"Expect: %d\n\n", tt.name, len(cells), len(tt.cells))
+			continue
+		}
+		for num, cell := range cells {
+			if cell.text != tt.cells[num] {
+				t.Errorf("Test: %q\n\t    Got: cell %d = %q, "+
+					"Expect: %q\n\n", tt.name, num, cell.text,
+					tt.cells[num])
+			}
+		}
+	}
+}
+
+func TestParseGridTable(t *testing.T) {
+	input := `Para.
+
++--------------------+-------+
+| Head               | Head  |
++====================+=======+
+|                    | Cell  |
+| First paragraph.   |       |
+|                    | two   |
+| Second paragraph.  | rows  |
++--------------------+       |
+| Last               |       |
++--------------------+-------+`
+	tr, errors := Parse("table", input)
+	if len(errors) != 0 || len(tr.Nodes) != 2 {
+		t.Fatalf("Got: len(errors) = %d, len(Nodes) = %d, Expect: 0, 2",
+			len(errors), len(tr.Nodes))
+	}
+	tb, ok := tr.Nodes[1].(*TableNode)
+	if !ok {
+		t.Fatalf("Got: Nodes[1] = %s, Expect: NodeTable",
+			tr.Nodes[1].NodeType())
+	}
+	// The table takes the ID of the paragraph it replaces.
+	if tb.ID != 2 || tb.Line != 3 || tb.HeadRows != 1 ||
+		len(tb.ColWidths) != 2 || tb.ColWidths[0] != 20 ||
+		tb.ColWidths[1] != 7 || len(tb.NodeList) != 3 {
+		t.Fatalf("Got: %#v, Expect: table 2 at line 3 with 1 header row, "+
+			"column widths [20 7] and 3 rows", tb)
+	}
+	body := tb.NodeList[1].(*TableRowNode)
+	if len(body.NodeList) != 2 {
+		t.Fatalf("Got: len(row.NodeList) = %d, Expect: 2",
+			len(body.NodeList))
+	}
+	first := body.NodeList[0].(*TableEntryNode)
+	expect := []struct {
+		text string
+		line Line
+	}{{"First paragraph.", 7}, {"Second paragraph.", 9}}
+	if len(first.NodeList) != len(expect) {
+		t.Fatalf("Got: len(entry.NodeList) = %d, Expect: %d",
+			len(first.NodeList), len(expect))
+	}
+	for num, n := range first.NodeList {
+		p, ok := n.(*ParagraphNode)
+		if !ok || p.Text != expect[num].text || p.Line != expect[num].line ||
+			p.StartPosition != 3 {
+			t.Errorf("Got: node %d = %#v, Expect: paragraph %q at "+
+				"line %d, column 3", num, n, expect[num].text,
+				expect[num].line)
+		}
+	}
+	second := body.NodeList[1].(*TableEntryNode)
+	if second.MoreRows != 1 || second.MoreCols != 0 {
+		t.Errorf("Got: MoreRows = %d, MoreCols = %d, Expect: 1, 0",
+			second.MoreRows, second.MoreCols)
+	}
+	if p := second.NodeList[0].(*ParagraphNode); p.Line != 6 ||
+		p.StartPosition != 24 {
+		t.Errorf("Got: Line = %d, StartPosition = %d, Expect: 6, 24",
+			p.Line, p.StartPosition)
+	}
+	if last := tb.NodeList[2].(*TableRowNode); len(last.NodeList) != 1 {
+		t.Errorf("Got: len(row.NodeList) = %d, Expect: 1",
+			len(last.NodeList))
+	}
+	checkIDsInDocumentOrder(t, tr.Nodes)
+}
+
+var malformedTableTests = []struct {
+	name   string
+	input  string
+	reason string // The second line of the message, if any
+}{
+	{
+		name:   "Missing bottom border",
+		input:  "+---+---+\n| a | b |\n| c | d |\n\nAfter.\n",
+		reason: "No bottom table border found.",
+	},
+	{
+		name:   "Ragged right border",
+		input:  "+---+---+\n| a | b |\n| c | d  |\n+---+---+\n\nAfter.\n",
+		reason: "Bottom/right table border does not match top border.",
+	},
+	{
+		name:   "Partial bottom border",
+		input:  "+---+---+\n| a | b |\n+---+   |\n\nAfter.\n",
+		reason: "No bottom table border found.",
+	},
+	{
+		name:   "Ragged right border with non-ASCII text",
+		input:  "+---+---+\n| é | b |\n| 日 | d |\n+---+---+\n\nAfter.\n",
+		reason: "Bottom/right table border does not match top border.",
+	},
+}
+
+func TestParseMalformedGridTable(t *testing.T) {
+	for _, tt := range malformedTableTests {
+		tr, errors := Parse(tt.name, tt.input)
+		if len(errors) != 1 || len(tr.Nodes) != 2 {
+			t.Errorf("Test: %q\n\t    Got: len(errors) = %d, len(Nodes) "+
+				"= %d, Expect: 1, 2\n\n", tt.name, len(errors),
+				len(tr.Nodes))
+			continue
+		}
+		s, ok := tr.Nodes[0].(*SystemMessageNode)
+		if !ok || s.MessageType != errorMalformedTable ||
+			s.Severity != levelError || len(s.NodeList) != 2 {
+			t.Errorf("Test: %q\n\t    Got: Nodes[0] = %s, Expect: "+
+				"errorMalformedTable\n\n", tt.name, PseudoXML(tr.Nodes[:1]))
+			continue
+		}
+		text := "Malformed table.\n" + tt.reason
+		if p := s.NodeList[0].(*ParagraphNode); p.Text != text {
+			t.Errorf("Test: %q\n\t    Got: %q, Expect: %q\n\n", tt.name,
+				p.Text, text)
+		}
+		table := tt.input[:strings.Index(tt.input, "\n\n")]
+		if lb := s.NodeList[1].(*LiteralBlockNode); lb.Text != table {
+			t.Errorf("Test: %q\n\t    Got: literal block %q, "+
+				"Expect: %q\n\n", tt.name, lb.Text, table)
+		}
+		// The text after the table is parsed as usual.
+		if p, ok := tr.Nodes[1].(*ParagraphNode); !ok || p.Text != "After." {
+			t.Errorf("Test: %q\n\t    Got: Nodes[1] = %s, Expect: "+
+				"paragraph \"After.\"\n\n", tt.name,
+				PseudoXML(tr.Nodes[1:]))
+		}
+	}
+	// Well formed tables are not reported. The width of the rows is
+	// measured in display columns, not bytes.
+	for _, input := range []string{
+		"+---+---+\n| a | b |\n+---+---+\n",
+		"+---+\n| é |\n+---+\n",
+		"+------+\n| 日本 |\n+------+\n",
+	} {
+		if _, errors := Parse("table", input); len(errors) != 0 {
+			t.Errorf("Test: %q\n\t    Got: len(errors) = %d, "+
+				"Expect: 0\n\n", input, len(errors))
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "strings"
+
+// target returns the TargetNode of the hyperlink target at the explicit markup
+// start i with the block text. If i is the "__" marker of the short form of an
+// anonymous target, text is the link of the target. A link ending with an
+// unescaped underscore is a reference to another target, the target is then
+// an indirect target.
+func (t *Tree) target(text string, i *item) Node {
+	var name, link string
+	anonymous := true
+	switch {
+	case i.Text == "__":
+		link = text
+	case strings.HasPrefix(text, "__:"):
+		link = text[3:]
+	default:
+		anonymous = false
+		name, _ = splitHyperlinkTarget(text)
+		link = text[targetNameEnd(text[1:])+2:]
+	}
+	n := newTarget(i, name, anonymous, &t.id)
+	if name != "" {
+		n.Names = append(n.Names, normalizeName(name))
+	}
+	link = strings.TrimSpace(link)
+	if strings.HasSuffix(link, "_") && !strings.HasSuffix(link, `\_`) {
+		ref := strings.Trim(strings.TrimSuffix(link, "_"), "`")
+		n.RefName = normalizeName(ref)
+		return n
+	}
+	n.RefURI = targetLink(link)
+	return n
+}
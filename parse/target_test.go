@@ -0,0 +1,78 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+var targetTests = []struct {
+	name   string
+	input  string
+	expect []TargetNode
+}{
+	{
+		name:  "stacked targets",
+		input: ".. _a: http://a.example\n.. _b: http://b.example",
+		expect: []TargetNode{
+			{Name: "a", RefURI: "http://a.example", Line: 1},
+			{Name: "b", RefURI: "http://b.example", Line: 2},
+		},
+	},
+	{
+		name:  "stacked anonymous targets",
+		input: ".. __: http://a.example\n__ http://b.example",
+		expect: []TargetNode{
+			{Anonymous: true, RefURI: "http://a.example", Line: 1},
+			{Anonymous: true, RefURI: "http://b.example", Line: 2},
+		},
+	},
+	{
+		name:  "internal and indirect targets",
+		input: ".. _Internal:\n.. _indirect: `Internal`_",
+		expect: []TargetNode{
+			{Name: "Internal", Line: 1},
+			{Name: "indirect", RefName: "internal", Line: 2},
+		},
+	},
+	{
+		name:  "link continued on an indented line",
+		input: ".. _a: http://a.example/\n   path\n.. _b: http://b.example",
+		expect: []TargetNode{
+			{Name: "a", RefURI: "http://a.example/path", Line: 1},
+			{Name: "b", RefURI: "http://b.example", Line: 3},
+		},
+	},
+}
+
+func TestParseTargets(t *testing.T) {
+	for _, tt := range targetTests {
+		tr, errors := Parse(tt.name, tt.input)
+		if len(errors) != 0 {
+			t.Errorf("Test: %q\n\t    Got: len(errors) = %d, "+
+				"Expect: 0\n\n", tt.name, len(errors))
+			continue
+		}
+		if len(tr.Nodes) != len(tt.expect) {
+			t.Errorf("Test: %q\n\t    Got: len(Nodes) = %d, "+
+				"Expect: %d\n\n", tt.name, len(tr.Nodes),
+				len(tt.expect))
+			continue
+		}
+		for num, exp := range tt.expect {
+			n, ok := tr.Nodes[num].(*TargetNode)
+			if !ok {
+				t.Errorf("Test: %q\n\t    Got: Nodes[%d] = %s, "+
+					"Expect: NodeTarget\n\n", tt.name, num,
+					tr.Nodes[num].NodeType())
+				continue
+			}
+			if n.Name != exp.Name || n.Anonymous != exp.Anonymous ||
+				n.RefURI != exp.RefURI || n.RefName != exp.RefName ||
+				n.Line != exp.Line {
+				t.Errorf("Test: %q\n\t    Got: Nodes[%d] = %+v, "+
+					"Expect: %+v\n\n", tt.name, num, *n, exp)
+			}
+		}
+	}
+}
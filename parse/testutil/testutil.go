@@ -0,0 +1,182 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+// Package testutil loads the JSON test fixtures used to test the parser and
+// compares the expected nodes of a fixture against parsed nodes. It can be used
+// to write conformance fixtures outside of the go-rst package.
+//
+// A fixture is a pair of files sharing a base path: "<path>.rst" contains the
+// reStructuredText input and "<path>-nodes.json" contains the nodes expected
+// from the parser. The format of the nodes file is documented by
+// parse.DecodeNodes.
+package testutil
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+
+	"code.google.com/p/go.text/unicode/norm"
+	"github.com/demizer/go-rst/parse"
+)
+
+// ParseTest contains a single test fixture.
+type ParseTest struct {
+	Path     string // The path including directory and basename
+	Input    string // The input data to be parsed
+	NodeData []byte // The expected parse nodes in JSON
+}
+
+// LoadParseTest loads the fixture at path. path is the path of the fixture
+// files without the ".rst" and "-nodes.json" suffixes. The final newline of
+// the input file is removed.
+func LoadParseTest(path string) (*ParseTest, error) {
+	input, err := ioutil.ReadFile(path + ".rst")
+	if err != nil {
+		return nil, err
+	}
+	if len(input) == 0 {
+		return nil, fmt.Errorf("%q is empty", path+".rst")
+	}
+	nodeData, err := ioutil.ReadFile(path + "-nodes.json")
+	if err != nil {
+		return nil, err
+	}
+	if len(nodeData) == 0 {
+		return nil, fmt.Errorf("%q is empty", path+"-nodes.json")
+	}
+	return &ParseTest{
+		Path:     path,
+		Input:    string(input[:len(input)-1]),
+		NodeData: nodeData,
+	}, nil
+}
+
+// ExpectNodes returns the expected nodes of the fixture. A panic occurs if the
+// JSON data can not be decoded.
+func (p *ParseTest) ExpectNodes() parse.NodeList {
+	nl, err := parse.DecodeNodes(p.NodeData)
+	if err != nil {
+		panic(fmt.Errorf("%s: JSON error: %s", p.Path, err))
+	}
+	return nl
+}
+
+// Parse parses the input of the fixture and returns the parsed nodes.
+func (p *ParseTest) Parse() parse.NodeList {
+	tree, _ := parse.Parse(p.Path, p.Input)
+	return tree.Nodes
+}
+
+// CheckParseNodes compares the parsed nodes got against the expected nodes
+// want field by field and returns an error describing the first mismatch.
+//
+// Fixtures leave out the fields of the nodes that have their usual value, so a
+// StartPosition of zero in want matches a StartPosition of zero or one. Text
+// in want is compared after NFC normalization, like the parser input.
+func CheckParseNodes(got, want parse.NodeList) error {
+	return checker{}.nodeList(got, want)
+}
+
+// CheckParseNodesTolerant is like CheckParseNodes, but ignores the
+// StartPosition of the nodes and only compares their structure and content.
+// Fixtures checked with it do not need to be updated when the byte offsets
+// reported by the lexer change.
+func CheckParseNodesTolerant(got, want parse.NodeList) error {
+	return checker{ignorePositions: true}.nodeList(got, want)
+}
+
+// checker compares parsed nodes against expected nodes.
+type checker struct {
+	ignorePositions bool // Do not compare StartPosition fields
+}
+
+func (c checker) nodeList(got, want parse.NodeList) error {
+	if len(got) != len(want) {
+		return fmt.Errorf("got %d nodes, want %d nodes", len(got),
+			len(want))
+	}
+	for num := range want {
+		if err := c.node(got[num], want[num]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c checker) node(got, want parse.Node) error {
+	if got == nil || want == nil {
+		if got == nil && want == nil {
+			return nil
+		}
+		return fmt.Errorf("got node %v, want %v", got, want)
+	}
+	gv := reflect.Indirect(reflect.ValueOf(got))
+	wv := reflect.Indirect(reflect.ValueOf(want))
+	if gv.Type() != wv.Type() {
+		return fmt.Errorf("Node ID=%d: got %s, want %s", want.IDNumber(),
+			gv.Type(), wv.Type())
+	}
+	for i := 0; i < wv.NumField(); i++ {
+		name := wv.Type().Field(i).Name
+		if err := c.field(gv.Field(i), wv.Field(i)); err != nil {
+			return fmt.Errorf("Node ID=%d: %s: %s", want.IDNumber(),
+				name, err)
+		}
+	}
+	return nil
+}
+
+func (c checker) field(got, want reflect.Value) error {
+	switch w := want.Interface().(type) {
+	case parse.NodeList:
+		return c.nodeList(got.Interface().(parse.NodeList), w)
+	case parse.StartPosition:
+		g := got.Interface().(parse.StartPosition)
+		if c.ignorePositions || w == 0 && (g == 0 || g == 1) {
+			return nil
+		}
+	case string:
+		w = norm.NFC.String(w)
+		if g := got.Interface().(string); g != w {
+			return fmt.Errorf("got %q, want %q", g, w)
+		}
+		return nil
+	}
+	if want.Kind() == reflect.Ptr {
+		if got.IsNil() || want.IsNil() {
+			if got.IsNil() && want.IsNil() {
+				return nil
+			}
+			return fmt.Errorf("got %v, want %v", got.Interface(),
+				want.Interface())
+		}
+		return c.node(got.Interface().(parse.Node),
+			want.Interface().(parse.Node))
+	}
+	if want.Kind() == reflect.Struct {
+		for i := 0; i < want.NumField(); i++ {
+			if err := c.field(got.Field(i), want.Field(i)); err != nil {
+				return fmt.Errorf("%s: %s",
+					want.Type().Field(i).Name, err)
+			}
+		}
+		return nil
+	}
+	if want.Kind() == reflect.Slice {
+		if got.Len() == 0 && want.Len() == 0 {
+			return nil
+		}
+		if !reflect.DeepEqual(got.Interface(), want.Interface()) {
+			return fmt.Errorf("got %v, want %v", got.Interface(),
+				want.Interface())
+		}
+		return nil
+	}
+	if got.Interface() != want.Interface() {
+		return fmt.Errorf("got %v, want %v", got.Interface(),
+			want.Interface())
+	}
+	return nil
+}
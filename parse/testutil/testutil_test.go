@@ -0,0 +1,99 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package testutil
+
+import (
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/demizer/go-rst/parse"
+)
+
+// fixturePath returns the path to a fixture in the testdata directory of the
+// repository.
+func fixturePath(elem ...string) string {
+	_, file, _, _ := runtime.Caller(0)
+	dir := filepath.Join(filepath.Dir(file), "..", "..", "testdata")
+	return filepath.Join(append([]string{dir}, elem...)...)
+}
+
+func TestCheckParseNodes(t *testing.T) {
+	path := fixturePath("test-section", "02-title-with-overline-bad",
+		"00.03-inset-title-mismatched-underline")
+	test, err := LoadParseTest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := test.ExpectNodes()
+	if len(want) == 0 {
+		t.Fatalf("Test: %q\n\t    Got: no expected nodes\n\n", path)
+	}
+	if err := CheckParseNodes(test.Parse(), want); err != nil {
+		t.Errorf("Test: %q\n\t    Got: %s\n\n", path, err)
+	}
+}
+
+func TestCheckParseNodesMismatch(t *testing.T) {
+	path := fixturePath("test-section", "00-title-good",
+		"00.00-title-paragraph")
+	test, err := LoadParseTest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := test.ExpectNodes()
+	sec := want[0].(*parse.SectionNode)
+	sec.NodeList[0].(*parse.ParagraphNode).Text = "Changed text."
+	if err := CheckParseNodes(test.Parse(), want); err == nil {
+		t.Errorf("Test: %q\n\t    Got: no error for changed text\n\n",
+			path)
+	}
+	if err := CheckParseNodes(test.Parse(), want[:0]); err == nil {
+		t.Errorf("Test: %q\n\t    Got: no error for missing nodes\n\n",
+			path)
+	}
+}
+
+// shiftPositions adds offset to the StartPosition of every node in nl.
+func shiftPositions(nl parse.NodeList, offset int) {
+	parse.Walk(nl, func(n parse.Node) bool {
+		f := reflect.ValueOf(n).Elem().FieldByName("StartPosition")
+		if f.IsValid() {
+			f.SetInt(f.Int() + int64(offset))
+		}
+		return true
+	})
+}
+
+func TestCheckParseNodesTolerant(t *testing.T) {
+	path := fixturePath("test-section", "00-title-good",
+		"00.00-title-paragraph")
+	test, err := LoadParseTest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := test.ExpectNodes()
+	shiftPositions(want, 3)
+	if err := CheckParseNodes(test.Parse(), want); err == nil {
+		t.Errorf("Test: %q\n\t    Got: no error for shifted "+
+			"positions in strict mode\n\n", path)
+	}
+	if err := CheckParseNodesTolerant(test.Parse(), want); err != nil {
+		t.Errorf("Test: %q\n\t    Got: %s\n\n", path, err)
+	}
+	want[0].(*parse.SectionNode).Title.Text = "Changed"
+	if err := CheckParseNodesTolerant(test.Parse(), want); err == nil {
+		t.Errorf("Test: %q\n\t    Got: no error for changed title "+
+			"in tolerant mode\n\n", path)
+	}
+}
+
+func TestLoadParseTestMissing(t *testing.T) {
+	if _, err := LoadParseTest(fixturePath("missing")); err == nil {
+		t.Error("LoadParseTest did not return an error for a missing " +
+			"fixture")
+	}
+}
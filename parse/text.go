@@ -0,0 +1,233 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RenderText writes the readable text of the parsed document to w, for example
+// for search indexing. Section titles, paragraphs, list items, and literal
+// blocks are written as blocks of text separated by blank lines. Inline markup
+// is removed, inline literals and references contribute their text. Section
+// adornments, comments, images, and system messages are omitted.
+func (t *Tree) RenderText(w io.Writer) error {
+	text := textBlocks(t.Nodes, "\n\n")
+	if text == "" {
+		return nil
+	}
+	_, err := io.WriteString(w, text+"\n")
+	return err
+}
+
+// textBlocks returns the text of the nodes in nl separated by sep.
+func textBlocks(nl NodeList, sep string) string {
+	var blocks []string
+	for _, n := range nl {
+		if n == nil {
+			continue
+		}
+		if b := textNode(n); b != "" {
+			blocks = append(blocks, b)
+		}
+	}
+	return strings.Join(blocks, sep)
+}
+
+// textNode returns the text of the node n and its children.
+func textNode(n Node) string {
+	switch n := n.(type) {
+	case *SectionNode:
+		title := plainInline(n.Title.Text)
+		if body := textBlocks(n.NodeList, "\n\n"); body != "" {
+			return title + "\n\n" + body
+		}
+		return title
+	case *ParagraphNode:
+		return plainInline(n.Text)
+	case *DefinitionTermNode:
+		return plainInline(n.Text)
+	case *DefinitionListItemNode:
+		// The classifiers are written on the line of the term.
+		var term []string
+		if n.Term != nil {
+			term = append(term, plainInline(n.Term.Text))
+		}
+		for _, c := range n.Classifiers {
+			term = append(term, plainInline(c.(*ClassifierNode).Text))
+		}
+		text := strings.Join(term, " : ")
+		if n.Definition != nil {
+			if def := textNode(n.Definition); def != "" {
+				text += "\n\n" + def
+			}
+		}
+		return text
+	case *RubricNode:
+		return plainInline(n.Text)
+	case *LiteralBlockNode:
+		return n.Text
+	case *ParsedLiteralNode:
+		return plainInline(n.Text)
+	case *BulletListNode, *EnumListNode:
+		// List items are written on consecutive lines.
+		return textBlocks(children(n), "\n")
+	case *CommentNode, *ImageNode, *SystemMessageNode, *TransitionNode,
+		*BlankLineNode:
+		return ""
+	}
+	return textBlocks(children(n), "\n\n")
+}
+
+// inlineDelimiters are the start-string and end-string of an inline markup
+// span.
+type inlineDelimiters struct {
+	start, end string
+}
+
+// inlineStartStrings contains the inline markup start-strings removed by
+// plainInline and their end-strings. Longer start-strings are listed first. A
+// start-string can have more than one end-string, the first end-string found
+// closes the span.
+var inlineStartStrings = []inlineDelimiters{
+	{"**", "**"},
+	{"``", "``"},
+	{"*", "*"},
+	{"`", "`"},
+	{"`", "`_"},
+	{"`", "`__"},
+	{"|", "|"},
+}
+
+// plainInline returns text with the inline markup removed. The text of
+// emphasis, strong emphasis, inline literals, interpreted text, and
+// substitution references is kept. Hyperlink references are replaced by their
+// reference text, and escaping backslashes are removed.
+func plainInline(text string) string {
+	var buf []byte
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' {
+			buf = appendEscaped(buf, text, i)
+			i++
+			continue
+		}
+		if text[i] == '_' && isReferenceEnd(text, i) {
+			continue
+		}
+		d, end := inlineMarkupAt(text, i)
+		if end == -1 {
+			buf = append(buf, text[i])
+			continue
+		}
+		content := text[i+len(d.start) : end]
+		switch d.start {
+		case "``":
+			// Backslashes in inline literals are literal text.
+		case "`":
+			content = removeEscapes(referenceText(content))
+		default:
+			content = removeEscapes(content)
+		}
+		buf = append(buf, content...)
+		i = end + len(d.end) - 1
+	}
+	return string(buf)
+}
+
+// removeEscapes returns text with the escaping backslashes removed.
+func removeEscapes(text string) string {
+	if !strings.Contains(text, "\\") {
+		return text
+	}
+	var buf []byte
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' {
+			buf = appendEscaped(buf, text, i)
+			i++
+			continue
+		}
+		buf = append(buf, text[i])
+	}
+	return string(buf)
+}
+
+// appendEscaped appends the byte escaped by the backslash at index i of text
+// to buf. An escaped whitespace character is removed with the backslash, so an
+// escaped line break joins the text of two lines without a space.
+func appendEscaped(buf []byte, text string, i int) []byte {
+	if i+1 < len(text) && !unicode.IsSpace(rune(text[i+1])) {
+		buf = append(buf, text[i+1])
+	}
+	return buf
+}
+
+// inlineMarkupAt returns the delimiters of the inline markup beginning at
+// index i of text, and the index of its end-string. end is -1 if no inline
+// markup begins at i.
+func inlineMarkupAt(text string, i int) (d inlineDelimiters, end int) {
+	end = -1
+	before := eof
+	if i > 0 {
+		before, _ = utf8.DecodeLastRuneInString(text[:i])
+	}
+	if !isInlineStartContext(before) {
+		return
+	}
+	var start string
+	for _, s := range inlineStartStrings {
+		if start == "" && strings.HasPrefix(text[i:], s.start) {
+			after, _ := utf8.DecodeRuneInString(text[i+len(s.start):])
+			if i+len(s.start) == len(text) || unicode.IsSpace(after) ||
+				isInlineMatchingPair(before, after) {
+				return
+			}
+			start = s.start
+		}
+		if s.start != start {
+			continue
+		}
+		e := findInlineEnd(text, i+len(s.start), s.end)
+		if e != -1 && (end == -1 || e < end) {
+			d, end = s, e
+		}
+	}
+	return
+}
+
+// isReferenceEnd returns true if the underscore at index i of text ends a
+// hyperlink reference, like "name_", "name__", or "`name`_".
+func isReferenceEnd(text string, i int) bool {
+	j := i + 1
+	if j < len(text) && text[j] == '_' {
+		j++
+	}
+	after := eof
+	if j < len(text) {
+		after, _ = utf8.DecodeRuneInString(text[j:])
+	}
+	if i == 0 || !isInlineEndContext(after) {
+		return false
+	}
+	before, _ := utf8.DecodeLastRuneInString(text[:i])
+	return before == '`' || before == '_' || unicode.IsLetter(before) ||
+		unicode.IsDigit(before)
+}
+
+// referenceText returns the reference text of interpreted text or a phrase
+// reference. An embedded URI, like in "`Go <http://golang.org>`_", is
+// removed.
+func referenceText(content string) string {
+	if strings.HasSuffix(content, ">") {
+		if open := strings.LastIndex(content, "<"); open > 0 {
+			if text := strings.TrimSpace(content[:open]); text != "" {
+				return text
+			}
+		}
+	}
+	return content
+}
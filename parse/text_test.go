@@ -0,0 +1,72 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRenderText(t *testing.T) {
+	input := "Title\n=====\n\nSome *emphasis* and **strong** text.\n\n" +
+		"- ``item`` one\n- `Go <http://golang.org>`_ two\n\n" +
+		".. A comment"
+	tr, _ := Parse("TestRenderText", input)
+	var buf bytes.Buffer
+	if err := tr.RenderText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{"Title\n", "Some emphasis and strong text.",
+		"item one\nGo two\n"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("Got: %q\n\t Expect: contains %q", out, s)
+		}
+	}
+	for _, s := range []string{"=", "*", "`", "comment"} {
+		if strings.Contains(out, s) {
+			t.Errorf("Got: %q\n\t Expect: no %q", out, s)
+		}
+	}
+}
+
+var plainInlineTests = []struct {
+	input  string
+	expect string
+}{
+	{input: "*a* **b** ``c``", expect: "a b c"},
+	{input: "2 * 3 * 4", expect: "2 * 3 * 4"},
+	{input: "(*) and snake_case", expect: "(*) and snake_case"},
+	{input: "See name_ and anonymous__.", expect: "See name and anonymous."},
+	{input: "`phrase reference`_ and |sub|", expect: "phrase reference and sub"},
+	{input: `\*not emphasis\*`, expect: "*not emphasis*"},
+	{input: "join\\\nwords", expect: "joinwords"},
+	{input: "*emph\\\nasis*", expect: "emphasis"},
+	{input: "``lit\\\nral``", expect: "lit\\\nral"},
+	{input: "back\\\\\nslash", expect: "back\\\nslash"},
+}
+
+func TestPlainInline(t *testing.T) {
+	for _, tt := range plainInlineTests {
+		if got := plainInline(tt.input); got != tt.expect {
+			t.Errorf("Test: %q\n\t    Got: %q, Expect: %q\n\n",
+				tt.input, got, tt.expect)
+		}
+	}
+}
+
+func TestRenderTextEscapedLineBreak(t *testing.T) {
+	// A backslash at the end of a paragraph line escapes the line break,
+	// which is removed, so the words around it are joined.
+	tr, _ := Parse("Escaped line break", "Some word\\\nnext word.\n")
+	var buf bytes.Buffer
+	if err := tr.RenderText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if expect := "Some wordnext word.\n"; buf.String() != expect {
+		t.Errorf("Got: %q, Expect: %q", buf.String(), expect)
+	}
+}
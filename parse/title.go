@@ -0,0 +1,58 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+// isPreBibliographic returns true if n may appear before the document title
+// without preventing title promotion. These are the nodes docutils classifies
+// as pre-bibliographic.
+func isPreBibliographic(n Node) bool {
+	switch n.NodeType() {
+	case NodeComment, NodeSystemMessage, NodeBlankLine:
+		return true
+	}
+	return false
+}
+
+// titleCandidate returns the index of the section in nl that can be promoted
+// to a title, or -1 if there is none. The section must be the first node of nl
+// that is not pre-bibliographic, and the last node of nl.
+func titleCandidate(nl NodeList) int {
+	for num, n := range nl {
+		if isPreBibliographic(n) {
+			continue
+		}
+		if _, ok := n.(*SectionNode); ok && num == len(nl)-1 {
+			return num
+		}
+		return -1
+	}
+	return -1
+}
+
+// promoteTitle implements the docutils DocTitle transform. If the document
+// consists of a single top-level section, optionally preceded by
+// pre-bibliographic nodes, the title of the section becomes Tree.Title and the
+// nodes of the section replace the section in Tree.Nodes. If the nodes of the
+// promoted section again consist of a single section, its title becomes
+// Tree.Subtitle and it is replaced by its nodes in the same way.
+func (t *Tree) promoteTitle() {
+	var ok bool
+	if t.Title, t.Nodes, ok = promoteSection(t.Nodes); ok {
+		t.Subtitle, t.Nodes, _ = promoteSection(t.Nodes)
+	}
+}
+
+// promoteSection replaces the title candidate section of nl by its nodes and
+// returns the title of the section and the new NodeList. If nl has no title
+// candidate, nl is returned unchanged and ok is false.
+func promoteSection(nl NodeList) (title *TitleNode, nodes NodeList, ok bool) {
+	num := titleCandidate(nl)
+	if num == -1 {
+		return nil, nl, false
+	}
+	sec := nl[num].(*SectionNode)
+	nodes = append(NodeList{}, nl[:num]...)
+	return sec.Title, append(nodes, sec.NodeList...), true
+}
@@ -0,0 +1,102 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+var promoteTitleTests = []struct {
+	name     string
+	input    string
+	opts     *ParseOptions
+	title    string     // The expected document title
+	subtitle string     // The expected document subtitle
+	types    []NodeType // The expected types of the top level nodes
+}{
+	{
+		name:  "Single top-level section",
+		input: "Title\n=====\n\nParagraph one.\n\nParagraph two.",
+		opts:  &ParseOptions{PromoteTitle: true},
+		title: "Title",
+		types: []NodeType{NodeParagraph, NodeParagraph},
+	},
+	{
+		name:  "Comment before the title",
+		input: ".. A comment\n\nTitle\n=====\n\nParagraph.",
+		opts:  &ParseOptions{PromoteTitle: true},
+		title: "Title",
+		types: []NodeType{NodeComment, NodeParagraph},
+	},
+	{
+		name: "Two top-level sections",
+		input: "Title 1\n=======\n\nParagraph.\n\n" +
+			"Title 2\n=======\n\nParagraph.",
+		opts:  &ParseOptions{PromoteTitle: true},
+		types: []NodeType{NodeSection, NodeSection},
+	},
+	{
+		name:  "Paragraph before the section",
+		input: "Paragraph.\n\nTitle\n=====\n\nParagraph.",
+		opts:  &ParseOptions{PromoteTitle: true},
+		types: []NodeType{NodeParagraph, NodeSection},
+	},
+	{
+		name: "Title and subtitle",
+		input: "Title\n=====\n\nSubtitle\n--------\n\n" +
+			"Paragraph.\n\nSection\n~~~~~~~\n\nParagraph.",
+		opts:     &ParseOptions{PromoteTitle: true},
+		title:    "Title",
+		subtitle: "Subtitle",
+		types:    []NodeType{NodeParagraph, NodeSection},
+	},
+	{
+		name: "Paragraph before the subtitle candidate",
+		input: "Title\n=====\n\nParagraph.\n\n" +
+			"Section\n-------\n\nParagraph.",
+		opts:  &ParseOptions{PromoteTitle: true},
+		title: "Title",
+		types: []NodeType{NodeParagraph, NodeSection},
+	},
+	{
+		name:  "Title promotion disabled",
+		input: "Title\n=====\n\nParagraph.",
+		opts:  &ParseOptions{},
+		types: []NodeType{NodeSection},
+	},
+}
+
+func TestParseOptionsPromoteTitle(t *testing.T) {
+	for _, tt := range promoteTitleTests {
+		tr, _ := ParseWithOptions(tt.name, tt.input, tt.opts)
+		var title string
+		if tr.Title != nil {
+			title = tr.Title.Text
+		}
+		if title != tt.title {
+			t.Errorf("Test: %q\n\t    Got: Title = %q, Expect: %q\n\n",
+				tt.name, title, tt.title)
+		}
+		var subtitle string
+		if tr.Subtitle != nil {
+			subtitle = tr.Subtitle.Text
+		}
+		if subtitle != tt.subtitle {
+			t.Errorf("Test: %q\n\t    Got: Subtitle = %q, "+
+				"Expect: %q\n\n", tt.name, subtitle, tt.subtitle)
+		}
+		if len(tr.Nodes) != len(tt.types) {
+			t.Errorf("Test: %q\n\t    Got: len(Nodes) = %d, "+
+				"Expect: %d\n\n", tt.name, len(tr.Nodes),
+				len(tt.types))
+			continue
+		}
+		for num, n := range tr.Nodes {
+			if n.NodeType() != tt.types[num] {
+				t.Errorf("Test: %q\n\t    Got: Nodes[%d] = %s, "+
+					"Expect: %s\n\n", tt.name, num,
+					n.NodeType(), tt.types[num])
+			}
+		}
+	}
+}
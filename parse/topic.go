@@ -0,0 +1,34 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+// topicDirective returns the TopicNode of the topic directive d at item i. The
+// argument is the title of the topic, and the directive content is parsed as
+// the body. A system message is returned if the title is missing.
+func (t *Tree) topicDirective(d *directive, i *item) Node {
+	t.directiveContent(d, i)
+	if d.argument == "" {
+		return t.itemMessage(errorTopicDirectiveArgument, i)
+	}
+	n := newTopic(i, d.argument, &t.id)
+	n.NodeList = t.parseNested(d.content, i.Line+Line(d.contentLine),
+		d.contentIndent)
+	return n
+}
+
+// sidebarDirective returns the SidebarNode of the sidebar directive d at item
+// i. The argument is the title of the sidebar and the :subtitle: option is its
+// subtitle. The directive content is parsed as the body. A system message is
+// returned if the title is missing.
+func (t *Tree) sidebarDirective(d *directive, i *item) Node {
+	t.directiveContent(d, i)
+	if d.argument == "" {
+		return t.itemMessage(errorSidebarDirectiveArgument, i)
+	}
+	n := newSidebar(i, d.argument, d.options["subtitle"], &t.id)
+	n.NodeList = t.parseNested(d.content, i.Line+Line(d.contentLine),
+		d.contentIndent)
+	return n
+}
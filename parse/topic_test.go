@@ -0,0 +1,81 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+func TestParseTopicDirective(t *testing.T) {
+	input := ".. topic:: Topic Title\n\n   Topic body.\n\nAfter the topic."
+	tr, errors := Parse("topic", input)
+	if len(errors) != 0 {
+		t.Fatalf("Got: len(errors) = %d, Expect: 0", len(errors))
+	}
+	if len(tr.Nodes) != 2 {
+		t.Fatalf("Got: len(Nodes) = %d, Expect: 2", len(tr.Nodes))
+	}
+	topic, ok := tr.Nodes[0].(*TopicNode)
+	if !ok {
+		t.Fatalf("Got: Nodes[0] = %s, Expect: NodeTopic",
+			tr.Nodes[0].NodeType())
+	}
+	if topic.Title != "Topic Title" {
+		t.Errorf("Got: Title = %q, Expect: %q", topic.Title, "Topic Title")
+	}
+	if len(topic.NodeList) != 1 {
+		t.Fatalf("Got: len(NodeList) = %d, Expect: 1", len(topic.NodeList))
+	}
+	p, ok := topic.NodeList[0].(*ParagraphNode)
+	if !ok || p.Text != "Topic body." || p.Line != 3 {
+		t.Errorf("Got: NodeList[0] = %#v, Expect: paragraph "+
+			"\"Topic body.\" on line 3", topic.NodeList[0])
+	}
+	if errs := tr.Validate(); len(errs) != 0 {
+		t.Errorf("Got: Validate() = %v, Expect: no errors", errs)
+	}
+}
+
+func TestParseSidebarDirective(t *testing.T) {
+	input := ".. sidebar:: Sidebar Title\n   :subtitle: Sub Title\n\n" +
+		"   Sidebar body."
+	tr, errors := Parse("sidebar", input)
+	if len(errors) != 0 {
+		t.Fatalf("Got: len(errors) = %d, Expect: 0", len(errors))
+	}
+	if len(tr.Nodes) != 1 {
+		t.Fatalf("Got: len(Nodes) = %d, Expect: 1", len(tr.Nodes))
+	}
+	sb, ok := tr.Nodes[0].(*SidebarNode)
+	if !ok {
+		t.Fatalf("Got: Nodes[0] = %s, Expect: NodeSidebar",
+			tr.Nodes[0].NodeType())
+	}
+	if sb.Title != "Sidebar Title" || sb.Subtitle != "Sub Title" {
+		t.Errorf("Got: Title = %q, Subtitle = %q, Expect: %q, %q",
+			sb.Title, sb.Subtitle, "Sidebar Title", "Sub Title")
+	}
+	if len(sb.NodeList) != 1 {
+		t.Fatalf("Got: len(NodeList) = %d, Expect: 1", len(sb.NodeList))
+	}
+	if p, ok := sb.NodeList[0].(*ParagraphNode); !ok ||
+		p.Text != "Sidebar body." {
+		t.Errorf("Got: NodeList[0] = %#v, Expect: paragraph "+
+			"\"Sidebar body.\"", sb.NodeList[0])
+	}
+	if errs := tr.Validate(); len(errs) != 0 {
+		t.Errorf("Got: Validate() = %v, Expect: no errors", errs)
+	}
+}
+
+func TestParseTopicDirectiveWithoutTitle(t *testing.T) {
+	_, errors := Parse("topic", ".. topic::\n")
+	if len(errors) != 1 {
+		t.Fatalf("Got: len(errors) = %d, Expect: 1", len(errors))
+	}
+	if m := errors[0].(*SystemMessageNode); m.MessageType !=
+		errorTopicDirectiveArgument {
+		t.Errorf("Got: MessageType = %s, Expect: %s", m.MessageType,
+			errorTopicDirectiveArgument)
+	}
+}
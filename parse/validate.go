@@ -0,0 +1,73 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "fmt"
+
+// Validate checks the parsed nodes in Tree.Nodes for structural errors that
+// indicate a bug in the parser. The following is checked:
+//
+// Section levels are at least one and at most one more than the level of the
+// parent section. AdornmentNodes do not appear in any NodeList, they are only
+// referenced by the OverLine and UnderLine fields of sections. System messages
+// have a body. Node IDs are unique.
+//
+// An error is returned for each problem found. The returned slice is empty if
+// the tree is valid.
+func (t *Tree) Validate() (errs []error) {
+	ids := make(map[ID]bool)
+	checkID := func(n Node) {
+		if ids[n.IDNumber()] {
+			errs = append(errs, fmt.Errorf("Node ID=%d: "+
+				"duplicate node ID", n.IDNumber()))
+		}
+		ids[n.IDNumber()] = true
+	}
+
+	var validate func(nl NodeList, parentLevel int)
+	validate = func(nl NodeList, parentLevel int) {
+		for _, n := range nl {
+			if n == nil {
+				continue
+			}
+			checkID(n)
+			switch n := n.(type) {
+			case *AdornmentNode:
+				errs = append(errs, fmt.Errorf("Node ID=%d: "+
+					"adornment node in a NodeList", n.ID))
+			case *SectionNode:
+				if n.Level < 1 || n.Level > parentLevel+1 {
+					errs = append(errs, fmt.Errorf("Node ID=%d: "+
+						"section level %d is invalid, "+
+						"parent section level is %d",
+						n.ID, n.Level, parentLevel))
+				}
+				// The title and adornments are fields of the
+				// section, only their IDs are checked.
+				if n.Title != nil {
+					checkID(n.Title)
+				}
+				if n.OverLine != nil {
+					checkID(n.OverLine)
+				}
+				if n.UnderLine != nil {
+					checkID(n.UnderLine)
+				}
+				validate(n.NodeList, n.Level)
+				continue
+			case *SystemMessageNode:
+				if len(n.NodeList) == 0 {
+					errs = append(errs, fmt.Errorf("Node ID=%d: "+
+						"system message has no body", n.ID))
+				}
+			}
+			validate(children(n), parentLevel)
+		}
+	}
+
+	validate(t.Nodes, 0)
+
+	return
+}
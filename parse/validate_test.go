@@ -0,0 +1,128 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+var treeValidateTests = []struct {
+	name  string
+	nodes NodeList
+	nErrs int // Expected number of errors returned by Validate()
+}{
+	{
+		name: "Valid tree",
+		nodes: NodeList{
+			&SectionNode{ID: 1, Type: NodeSection, Level: 1,
+				NodeList: NodeList{
+					&ParagraphNode{ID: 2, Type: NodeParagraph},
+					&SectionNode{ID: 3, Type: NodeSection, Level: 2},
+				},
+			},
+			&SectionNode{ID: 4, Type: NodeSection, Level: 1},
+		},
+	},
+	{
+		name: "Section level zero",
+		nodes: NodeList{
+			&SectionNode{ID: 1, Type: NodeSection, Level: 0},
+		},
+		nErrs: 1,
+	},
+	{
+		name: "Section level skipped",
+		nodes: NodeList{
+			&SectionNode{ID: 1, Type: NodeSection, Level: 1,
+				NodeList: NodeList{
+					&SectionNode{ID: 2, Type: NodeSection, Level: 3},
+				},
+			},
+		},
+		nErrs: 1,
+	},
+	{
+		name: "Adornment in Tree.Nodes",
+		nodes: NodeList{
+			&AdornmentNode{ID: 1, Type: NodeAdornment, Rune: '='},
+		},
+		nErrs: 1,
+	},
+	{
+		name: "Adornment in a section",
+		nodes: NodeList{
+			&SectionNode{ID: 1, Type: NodeSection, Level: 1,
+				UnderLine: &AdornmentNode{ID: 2,
+					Type: NodeAdornment, Rune: '='},
+				NodeList: NodeList{
+					&AdornmentNode{ID: 3, Type: NodeAdornment,
+						Rune: '='},
+				},
+			},
+		},
+		nErrs: 1,
+	},
+	{
+		name: "System message without body",
+		nodes: NodeList{
+			&SystemMessageNode{ID: 1, Type: NodeSystemMessage},
+		},
+		nErrs: 1,
+	},
+	{
+		name: "Duplicate node ID",
+		nodes: NodeList{
+			&ParagraphNode{ID: 1, Type: NodeParagraph},
+			&ParagraphNode{ID: 1, Type: NodeParagraph},
+		},
+		nErrs: 1,
+	},
+}
+
+func TestTreeValidate(t *testing.T) {
+	for _, tt := range treeValidateTests {
+		tr := New(tt.name, "")
+		tr.Nodes = tt.nodes
+		if errs := tr.Validate(); len(errs) != tt.nErrs {
+			t.Errorf("Test: %q\n\t    "+
+				"Got: len(errs) = %d, Expect: %d\n\t    errs: %v\n\n",
+				tt.name, len(errs), tt.nErrs, errs)
+		}
+	}
+}
+
+func TestTreeValidateParsed(t *testing.T) {
+	tr, _ := Parse("Valid document", "Title\n=====\n\nParagraph.\n\n"+
+		"Subtitle\n--------\n\nParagraph 2.")
+	if errs := tr.Validate(); len(errs) != 0 {
+		t.Errorf("Test: %q\n\t    Got: errs = %v, Expect: none\n\n",
+			tr.Name, errs)
+	}
+}
+
+// TestTreeValidateSectionFixtures checks that the trees parsed from the section
+// test inputs are valid, for example that the parser does not add the
+// adornments of sections to a NodeList.
+func TestTreeValidateSectionFixtures(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join(testDataDir(), "test-section",
+		"*", "*.rst"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("No section test inputs found: %v", err)
+	}
+	for _, path := range paths {
+		input, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The final newline is removed like in the parse tests.
+		tr, _ := Parse(path, string(input[:len(input)-1]))
+		if errs := tr.Validate(); len(errs) != 0 {
+			t.Errorf("Test: %q\n\t    Got: errs = %v, Expect: none\n\n",
+				path, errs)
+		}
+	}
+}
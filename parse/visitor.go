@@ -0,0 +1,343 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+// Visitor is implemented by types that handle each type of node, for example
+// renderers. Node.Accept calls the method of the Visitor for the type of the
+// node. The children of a node are not visited, a Visitor that needs them
+// calls Accept on the children itself, or is used with Walk.
+//
+// Embed BaseVisitor to implement only the methods for the node types of
+// interest.
+type Visitor interface {
+	VisitSection(*SectionNode)
+	VisitParagraph(*ParagraphNode)
+	VisitAdornment(*AdornmentNode)
+	VisitBlockQuote(*BlockQuoteNode)
+	VisitSystemMessage(*SystemMessageNode)
+	VisitLiteralBlock(*LiteralBlockNode)
+	VisitTransition(*TransitionNode)
+	VisitTitle(*TitleNode)
+	VisitComment(*CommentNode)
+	VisitBulletList(*BulletListNode)
+	VisitBulletListItem(*BulletListItemNode)
+	VisitEnumList(*EnumListNode)
+	VisitDefinitionList(*DefinitionListNode)
+	VisitDefinitionListItem(*DefinitionListItemNode)
+	VisitDefinitionTerm(*DefinitionTermNode)
+	VisitDefinition(*DefinitionNode)
+	VisitBlankLine(*BlankLineNode)
+	VisitImage(*ImageNode)
+	VisitFootnote(*FootnoteNode)
+	VisitText(*TextNode)
+	VisitEmphasis(*EmphasisNode)
+	VisitStrong(*StrongNode)
+	VisitInlineLiteral(*InlineLiteralNode)
+	VisitProblematic(*ProblematicNode)
+	VisitTopic(*TopicNode)
+	VisitSidebar(*SidebarNode)
+	VisitRubric(*RubricNode)
+	VisitParsedLiteral(*ParsedLiteralNode)
+	VisitCompound(*CompoundNode)
+	VisitContainer(*ContainerNode)
+	VisitFootnoteRef(*FootnoteRefNode)
+	VisitCitationRef(*CitationRefNode)
+	VisitReference(*ReferenceNode)
+	VisitCitation(*CitationNode)
+	VisitTarget(*TargetNode)
+	VisitDirective(*DirectiveNode)
+	VisitClassifier(*ClassifierNode)
+	VisitSubstitutionDefinition(*SubstitutionDefinitionNode)
+	VisitFieldList(*FieldListNode)
+	VisitField(*FieldNode)
+	VisitDocInfo(*DocInfoNode)
+	VisitLineBlock(*LineBlockNode)
+	VisitLine(*LineNode)
+	VisitTable(*TableNode)
+	VisitTableRow(*TableRowNode)
+	VisitTableEntry(*TableEntryNode)
+}
+
+// BaseVisitor implements Visitor with methods that do nothing. It is meant to
+// be embedded in Visitor implementations.
+type BaseVisitor struct{}
+
+func (BaseVisitor) VisitSection(*SectionNode)                               {}
+func (BaseVisitor) VisitParagraph(*ParagraphNode)                           {}
+func (BaseVisitor) VisitAdornment(*AdornmentNode)                           {}
+func (BaseVisitor) VisitBlockQuote(*BlockQuoteNode)                         {}
+func (BaseVisitor) VisitSystemMessage(*SystemMessageNode)                   {}
+func (BaseVisitor) VisitLiteralBlock(*LiteralBlockNode)                     {}
+func (BaseVisitor) VisitTransition(*TransitionNode)                         {}
+func (BaseVisitor) VisitTitle(*TitleNode)                                   {}
+func (BaseVisitor) VisitComment(*CommentNode)                               {}
+func (BaseVisitor) VisitBulletList(*BulletListNode)                         {}
+func (BaseVisitor) VisitBulletListItem(*BulletListItemNode)                 {}
+func (BaseVisitor) VisitEnumList(*EnumListNode)                             {}
+func (BaseVisitor) VisitDefinitionList(*DefinitionListNode)                 {}
+func (BaseVisitor) VisitDefinitionListItem(*DefinitionListItemNode)         {}
+func (BaseVisitor) VisitDefinitionTerm(*DefinitionTermNode)                 {}
+func (BaseVisitor) VisitDefinition(*DefinitionNode)                         {}
+func (BaseVisitor) VisitBlankLine(*BlankLineNode)                           {}
+func (BaseVisitor) VisitImage(*ImageNode)                                   {}
+func (BaseVisitor) VisitFootnote(*FootnoteNode)                             {}
+func (BaseVisitor) VisitText(*TextNode)                                     {}
+func (BaseVisitor) VisitEmphasis(*EmphasisNode)                             {}
+func (BaseVisitor) VisitStrong(*StrongNode)                                 {}
+func (BaseVisitor) VisitInlineLiteral(*InlineLiteralNode)                   {}
+func (BaseVisitor) VisitProblematic(*ProblematicNode)                       {}
+func (BaseVisitor) VisitTopic(*TopicNode)                                   {}
+func (BaseVisitor) VisitSidebar(*SidebarNode)                               {}
+func (BaseVisitor) VisitRubric(*RubricNode)                                 {}
+func (BaseVisitor) VisitParsedLiteral(*ParsedLiteralNode)                   {}
+func (BaseVisitor) VisitCompound(*CompoundNode)                             {}
+func (BaseVisitor) VisitContainer(*ContainerNode)                           {}
+func (BaseVisitor) VisitFootnoteRef(*FootnoteRefNode)                       {}
+func (BaseVisitor) VisitCitationRef(*CitationRefNode)                       {}
+func (BaseVisitor) VisitReference(*ReferenceNode)                           {}
+func (BaseVisitor) VisitCitation(*CitationNode)                             {}
+func (BaseVisitor) VisitTarget(*TargetNode)                                 {}
+func (BaseVisitor) VisitDirective(*DirectiveNode)                           {}
+func (BaseVisitor) VisitClassifier(*ClassifierNode)                         {}
+func (BaseVisitor) VisitSubstitutionDefinition(*SubstitutionDefinitionNode) {}
+func (BaseVisitor) VisitFieldList(*FieldListNode)                           {}
+func (BaseVisitor) VisitField(*FieldNode)                                   {}
+func (BaseVisitor) VisitDocInfo(*DocInfoNode)                               {}
+func (BaseVisitor) VisitLineBlock(*LineBlockNode)                           {}
+func (BaseVisitor) VisitLine(*LineNode)                                     {}
+func (BaseVisitor) VisitTable(*TableNode)                                   {}
+func (BaseVisitor) VisitTableRow(*TableRowNode)                             {}
+func (BaseVisitor) VisitTableEntry(*TableEntryNode)                         {}
+
+// Accept calls v.VisitSection with the SectionNode.
+func (s *SectionNode) Accept(v Visitor) {
+	v.VisitSection(s)
+}
+
+// Accept calls v.VisitParagraph with the ParagraphNode.
+func (p *ParagraphNode) Accept(v Visitor) {
+	v.VisitParagraph(p)
+}
+
+// Accept calls v.VisitAdornment with the AdornmentNode.
+func (a *AdornmentNode) Accept(v Visitor) {
+	v.VisitAdornment(a)
+}
+
+// Accept calls v.VisitBlockQuote with the BlockQuoteNode.
+func (b *BlockQuoteNode) Accept(v Visitor) {
+	v.VisitBlockQuote(b)
+}
+
+// Accept calls v.VisitSystemMessage with the SystemMessageNode.
+func (s *SystemMessageNode) Accept(v Visitor) {
+	v.VisitSystemMessage(s)
+}
+
+// Accept calls v.VisitLiteralBlock with the LiteralBlockNode.
+func (l *LiteralBlockNode) Accept(v Visitor) {
+	v.VisitLiteralBlock(l)
+}
+
+// Accept calls v.VisitTransition with the TransitionNode.
+func (t *TransitionNode) Accept(v Visitor) {
+	v.VisitTransition(t)
+}
+
+// Accept calls v.VisitTitle with the TitleNode.
+func (t *TitleNode) Accept(v Visitor) {
+	v.VisitTitle(t)
+}
+
+// Accept calls v.VisitComment with the CommentNode.
+func (c *CommentNode) Accept(v Visitor) {
+	v.VisitComment(c)
+}
+
+// Accept calls v.VisitBulletList with the BulletListNode.
+func (b *BulletListNode) Accept(v Visitor) {
+	v.VisitBulletList(b)
+}
+
+// Accept calls v.VisitBulletListItem with the BulletListItemNode.
+func (b *BulletListItemNode) Accept(v Visitor) {
+	v.VisitBulletListItem(b)
+}
+
+// Accept calls v.VisitEnumList with the EnumListNode.
+func (e *EnumListNode) Accept(v Visitor) {
+	v.VisitEnumList(e)
+}
+
+// Accept calls v.VisitDefinitionList with the DefinitionListNode.
+func (d *DefinitionListNode) Accept(v Visitor) {
+	v.VisitDefinitionList(d)
+}
+
+// Accept calls v.VisitDefinitionListItem with the DefinitionListItemNode.
+func (d *DefinitionListItemNode) Accept(v Visitor) {
+	v.VisitDefinitionListItem(d)
+}
+
+// Accept calls v.VisitDefinitionTerm with the DefinitionTermNode.
+func (d *DefinitionTermNode) Accept(v Visitor) {
+	v.VisitDefinitionTerm(d)
+}
+
+// Accept calls v.VisitDefinition with the DefinitionNode.
+func (d *DefinitionNode) Accept(v Visitor) {
+	v.VisitDefinition(d)
+}
+
+// Accept calls v.VisitBlankLine with the BlankLineNode.
+func (b *BlankLineNode) Accept(v Visitor) {
+	v.VisitBlankLine(b)
+}
+
+// Accept calls v.VisitImage with the ImageNode.
+func (i *ImageNode) Accept(v Visitor) {
+	v.VisitImage(i)
+}
+
+// Accept calls v.VisitFootnote with the FootnoteNode.
+func (f *FootnoteNode) Accept(v Visitor) {
+	v.VisitFootnote(f)
+}
+
+// Accept calls v.VisitText with the TextNode.
+func (t *TextNode) Accept(v Visitor) {
+	v.VisitText(t)
+}
+
+// Accept calls v.VisitEmphasis with the EmphasisNode.
+func (e *EmphasisNode) Accept(v Visitor) {
+	v.VisitEmphasis(e)
+}
+
+// Accept calls v.VisitStrong with the StrongNode.
+func (s *StrongNode) Accept(v Visitor) {
+	v.VisitStrong(s)
+}
+
+// Accept calls v.VisitInlineLiteral with the InlineLiteralNode.
+func (i *InlineLiteralNode) Accept(v Visitor) {
+	v.VisitInlineLiteral(i)
+}
+
+// Accept calls v.VisitProblematic with the ProblematicNode.
+func (p *ProblematicNode) Accept(v Visitor) {
+	v.VisitProblematic(p)
+}
+
+// Accept calls v.VisitTopic with the TopicNode.
+func (t *TopicNode) Accept(v Visitor) {
+	v.VisitTopic(t)
+}
+
+// Accept calls v.VisitSidebar with the SidebarNode.
+func (s *SidebarNode) Accept(v Visitor) {
+	v.VisitSidebar(s)
+}
+
+// Accept calls v.VisitRubric with the RubricNode.
+func (r *RubricNode) Accept(v Visitor) {
+	v.VisitRubric(r)
+}
+
+// Accept calls v.VisitParsedLiteral with the ParsedLiteralNode.
+func (p *ParsedLiteralNode) Accept(v Visitor) {
+	v.VisitParsedLiteral(p)
+}
+
+// Accept calls v.VisitCompound with the CompoundNode.
+func (c *CompoundNode) Accept(v Visitor) {
+	v.VisitCompound(c)
+}
+
+// Accept calls v.VisitContainer with the ContainerNode.
+func (c *ContainerNode) Accept(v Visitor) {
+	v.VisitContainer(c)
+}
+
+// Accept calls v.VisitFootnoteRef with the FootnoteRefNode.
+func (f *FootnoteRefNode) Accept(v Visitor) {
+	v.VisitFootnoteRef(f)
+}
+
+// Accept calls v.VisitCitationRef with the CitationRefNode.
+func (c *CitationRefNode) Accept(v Visitor) {
+	v.VisitCitationRef(c)
+}
+
+// Accept calls v.VisitReference with the ReferenceNode.
+func (r *ReferenceNode) Accept(v Visitor) {
+	v.VisitReference(r)
+}
+
+// Accept calls v.VisitCitation with the CitationNode.
+func (c *CitationNode) Accept(v Visitor) {
+	v.VisitCitation(c)
+}
+
+// Accept calls v.VisitTarget with the TargetNode.
+func (t *TargetNode) Accept(v Visitor) {
+	v.VisitTarget(t)
+}
+
+// Accept calls v.VisitDirective with the DirectiveNode.
+func (d *DirectiveNode) Accept(v Visitor) {
+	v.VisitDirective(d)
+}
+
+// Accept calls v.VisitClassifier with the ClassifierNode.
+func (c *ClassifierNode) Accept(v Visitor) {
+	v.VisitClassifier(c)
+}
+
+// Accept calls v.VisitSubstitutionDefinition with the
+// SubstitutionDefinitionNode.
+func (s *SubstitutionDefinitionNode) Accept(v Visitor) {
+	v.VisitSubstitutionDefinition(s)
+}
+
+// Accept calls v.VisitFieldList with the FieldListNode.
+func (f *FieldListNode) Accept(v Visitor) {
+	v.VisitFieldList(f)
+}
+
+// Accept calls v.VisitField with the FieldNode.
+func (f *FieldNode) Accept(v Visitor) {
+	v.VisitField(f)
+}
+
+// Accept calls v.VisitDocInfo with the DocInfoNode.
+func (d *DocInfoNode) Accept(v Visitor) {
+	v.VisitDocInfo(d)
+}
+
+// Accept calls v.VisitLineBlock with the LineBlockNode.
+func (l *LineBlockNode) Accept(v Visitor) {
+	v.VisitLineBlock(l)
+}
+
+// Accept calls v.VisitLine with the LineNode.
+func (l *LineNode) Accept(v Visitor) {
+	v.VisitLine(l)
+}
+
+// Accept calls v.VisitTable with the TableNode.
+func (t *TableNode) Accept(v Visitor) {
+	v.VisitTable(t)
+}
+
+// Accept calls v.VisitTableRow with the TableRowNode.
+func (t *TableRowNode) Accept(v Visitor) {
+	v.VisitTableRow(t)
+}
+
+// Accept calls v.VisitTableEntry with the TableEntryNode.
+func (t *TableEntryNode) Accept(v Visitor) {
+	v.VisitTableEntry(t)
+}
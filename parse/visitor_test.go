@@ -0,0 +1,38 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+// titleCollector collects the titles of the visited sections.
+type titleCollector struct {
+	BaseVisitor
+	titles []string
+}
+
+func (c *titleCollector) VisitSection(s *SectionNode) {
+	c.titles = append(c.titles, s.Title.Text)
+}
+
+func TestVisitorSectionTitles(t *testing.T) {
+	input := "Title\n=====\n\nParagraph.\n\nSubtitle\n--------\n\n" +
+		"Paragraph 2.\n\nSecond\n======\n\nParagraph 3."
+	tr, _ := Parse("TestVisitorSectionTitles", input)
+	c := new(titleCollector)
+	Walk(tr.Nodes, func(n Node) bool {
+		n.Accept(c)
+		return true
+	})
+	expect := []string{"Title", "Subtitle", "Second"}
+	if len(c.titles) != len(expect) {
+		t.Fatalf("Got: titles = %q, Expect: %q", c.titles, expect)
+	}
+	for num := range expect {
+		if c.titles[num] != expect[num] {
+			t.Errorf("Got: titles = %q, Expect: %q", c.titles, expect)
+			break
+		}
+	}
+}
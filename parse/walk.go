@@ -0,0 +1,96 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+// Walk traverses the nodes in nl depth first, calling fn for each node. The
+// children of a node are visited after the node itself. If fn returns false,
+// the children of that node are not visited.
+func Walk(nl NodeList, fn func(n Node) bool) {
+	for _, n := range nl {
+		if n == nil {
+			continue
+		}
+		if fn(n) {
+			Walk(children(n), fn)
+		}
+	}
+}
+
+// children returns the child nodes of n. For SectionNodes, the title and
+// adornment nodes are returned before the nodes of the section body. For
+// DefinitionListItemNodes, the term and its classifiers are returned before the
+// definition.
+func children(n Node) (nl NodeList) {
+	switch n := n.(type) {
+	case *SectionNode:
+		if n.Title != nil {
+			nl = append(nl, n.Title)
+		}
+		if n.OverLine != nil {
+			nl = append(nl, n.OverLine)
+		}
+		if n.UnderLine != nil {
+			nl = append(nl, n.UnderLine)
+		}
+		nl = append(nl, n.NodeList...)
+	case *BlockQuoteNode:
+		nl = n.NodeList
+	case *SystemMessageNode:
+		nl = n.NodeList
+	case *BulletListNode:
+		nl = n.NodeList
+	case *BulletListItemNode:
+		nl = n.NodeList
+	case *EnumListNode:
+		nl = n.NodeList
+	case *DefinitionListNode:
+		nl = n.NodeList
+	case *DefinitionListItemNode:
+		if n.Term != nil {
+			nl = append(nl, n.Term)
+		}
+		nl = append(nl, n.Classifiers...)
+		if n.Definition != nil {
+			nl = append(nl, n.Definition)
+		}
+	case *DefinitionNode:
+		nl = n.NodeList
+	case *FootnoteNode:
+		nl = n.NodeList
+	case *CitationNode:
+		nl = n.NodeList
+	case *ParagraphNode:
+		nl = n.NodeList
+	case *TopicNode:
+		nl = n.NodeList
+	case *SidebarNode:
+		nl = n.NodeList
+	case *RubricNode:
+		nl = n.NodeList
+	case *ParsedLiteralNode:
+		nl = n.NodeList
+	case *CompoundNode:
+		nl = n.NodeList
+	case *ContainerNode:
+		nl = n.NodeList
+	case *SubstitutionDefinitionNode:
+		nl = n.NodeList
+	case *FieldListNode:
+		nl = n.NodeList
+	case *FieldNode:
+		nl = n.NodeList
+	case *DocInfoNode:
+		nl = n.NodeList
+	case *LineBlockNode:
+		nl = n.NodeList
+	case *TableNode:
+		nl = n.NodeList
+	case *TableRowNode:
+		nl = n.NodeList
+	case *TableEntryNode:
+		nl = n.NodeList
+	}
+	return
+}
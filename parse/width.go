@@ -0,0 +1,241 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import (
+	"sort"
+	"unicode"
+)
+
+// runeRange is an inclusive range of runes.
+type runeRange struct{ lo, hi rune }
+
+// wideRunes are the ranges of runes with the East Asian Width property Wide (W)
+// or Fullwidth (F), as defined by Unicode Standard Annex #11. The ranges are
+// sorted and do not overlap.
+var wideRunes = []runeRange{
+	{0x1100, 0x115F}, // Hangul Jamo initial consonants
+	{0x231A, 0x231B},
+	{0x2329, 0x232A},
+	{0x23E9, 0x23EC},
+	{0x23F0, 0x23F0},
+	{0x23F3, 0x23F3},
+	{0x25FD, 0x25FE},
+	{0x2614, 0x2615},
+	{0x2648, 0x2653},
+	{0x267F, 0x267F},
+	{0x2693, 0x2693},
+	{0x26A1, 0x26A1},
+	{0x26AA, 0x26AB},
+	{0x26BD, 0x26BE},
+	{0x26C4, 0x26C5},
+	{0x26CE, 0x26CE},
+	{0x26D4, 0x26D4},
+	{0x26EA, 0x26EA},
+	{0x26F2, 0x26F3},
+	{0x26F5, 0x26F5},
+	{0x26FA, 0x26FA},
+	{0x26FD, 0x26FD},
+	{0x2705, 0x2705},
+	{0x270A, 0x270B},
+	{0x2728, 0x2728},
+	{0x274C, 0x274C},
+	{0x274E, 0x274E},
+	{0x2753, 0x2755},
+	{0x2757, 0x2757},
+	{0x2795, 0x2797},
+	{0x27B0, 0x27B0},
+	{0x27BF, 0x27BF},
+	{0x2B1B, 0x2B1C},
+	{0x2B50, 0x2B50},
+	{0x2B55, 0x2B55},
+	{0x2E80, 0x303E},   // CJK radicals, Kangxi radicals, CJK symbols
+	{0x3041, 0x33FF},   // Hiragana, Katakana, Bopomofo, CJK compatibility
+	{0x3400, 0x4DBF},   // CJK unified ideographs extension A
+	{0x4E00, 0x9FFF},   // CJK unified ideographs
+	{0xA000, 0xA4CF},   // Yi
+	{0xA960, 0xA97F},   // Hangul Jamo extended A
+	{0xAC00, 0xD7A3},   // Hangul syllables
+	{0xF900, 0xFAFF},   // CJK compatibility ideographs
+	{0xFE10, 0xFE19},   // Vertical forms
+	{0xFE30, 0xFE6F},   // CJK compatibility forms, small form variants
+	{0xFF00, 0xFF60},   // Fullwidth forms
+	{0xFFE0, 0xFFE6},   // Fullwidth signs
+	{0x16FE0, 0x16FE4}, // Ideographic symbols
+	{0x17000, 0x18AFF}, // Tangut
+	{0x1B000, 0x1B2FF}, // Kana supplement, Nushu
+	{0x1F004, 0x1F004},
+	{0x1F0CF, 0x1F0CF},
+	{0x1F18E, 0x1F18E},
+	{0x1F191, 0x1F19A},
+	{0x1F200, 0x1F202},
+	{0x1F210, 0x1F23B},
+	{0x1F240, 0x1F248},
+	{0x1F250, 0x1F251},
+	{0x1F260, 0x1F265},
+	{0x1F300, 0x1F64F}, // Emoji
+	{0x1F680, 0x1F6FF}, // Transport and map symbols
+	{0x1F900, 0x1F9FF}, // Supplemental symbols and pictographs
+	{0x20000, 0x2FFFD}, // CJK unified ideographs extension B and later
+	{0x30000, 0x3FFFD},
+}
+
+// ambiguousRunes are the ranges of commonly used runes with the East Asian
+// Width property Ambiguous (A). They are one column wide in western contexts
+// and two columns wide in East Asian contexts. The ranges are sorted and do not
+// overlap.
+var ambiguousRunes = []runeRange{
+	{0x00A1, 0x00A1},
+	{0x00A4, 0x00A4},
+	{0x00A7, 0x00A8},
+	{0x00AA, 0x00AA},
+	{0x00AD, 0x00AE},
+	{0x00B0, 0x00B4},
+	{0x00B6, 0x00BA},
+	{0x00BC, 0x00BF},
+	{0x00C6, 0x00C6},
+	{0x00D0, 0x00D0},
+	{0x00D7, 0x00D8},
+	{0x00DE, 0x00E1},
+	{0x00E6, 0x00E6},
+	{0x00E8, 0x00EA},
+	{0x00EC, 0x00ED},
+	{0x00F0, 0x00F0},
+	{0x00F2, 0x00F3},
+	{0x00F7, 0x00FA},
+	{0x00FC, 0x00FC},
+	{0x00FE, 0x00FE},
+	{0x0391, 0x03A9}, // Greek capital letters
+	{0x03B1, 0x03C9}, // Greek small letters
+	{0x0401, 0x0401},
+	{0x0410, 0x044F}, // Cyrillic letters
+	{0x0451, 0x0451},
+	{0x2010, 0x2010},
+	{0x2013, 0x2016},
+	{0x2018, 0x2019},
+	{0x201C, 0x201D},
+	{0x2020, 0x2022},
+	{0x2024, 0x2027},
+	{0x2030, 0x2030},
+	{0x2032, 0x2033},
+	{0x2035, 0x2035},
+	{0x203B, 0x203B},
+	{0x203E, 0x203E},
+	{0x2103, 0x2103},
+	{0x2105, 0x2105},
+	{0x2109, 0x2109},
+	{0x2113, 0x2113},
+	{0x2116, 0x2116},
+	{0x2121, 0x2122},
+	{0x2126, 0x2126},
+	{0x212B, 0x212B},
+	{0x2153, 0x2154},
+	{0x215B, 0x215E},
+	{0x2160, 0x216B}, // Roman numerals
+	{0x2170, 0x2179},
+	{0x2190, 0x2199}, // Arrows
+	{0x21D2, 0x21D2},
+	{0x21D4, 0x21D4},
+	{0x2200, 0x2200},
+	{0x2202, 0x2203},
+	{0x2207, 0x2208},
+	{0x220B, 0x220B},
+	{0x220F, 0x220F},
+	{0x2211, 0x2211},
+	{0x2215, 0x2215},
+	{0x221A, 0x221A},
+	{0x221D, 0x2220},
+	{0x2223, 0x2223},
+	{0x2225, 0x2225},
+	{0x2227, 0x222C},
+	{0x222E, 0x222E},
+	{0x2234, 0x2237},
+	{0x223C, 0x223D},
+	{0x2248, 0x2248},
+	{0x224C, 0x224C},
+	{0x2252, 0x2252},
+	{0x2260, 0x2261},
+	{0x2264, 0x2267},
+	{0x226A, 0x226B},
+	{0x226E, 0x226F},
+	{0x2282, 0x2283},
+	{0x2286, 0x2287},
+	{0x2295, 0x2295},
+	{0x2299, 0x2299},
+	{0x22A5, 0x22A5},
+	{0x22BF, 0x22BF},
+	{0x2312, 0x2312},
+	{0x2460, 0x24E9}, // Enclosed alphanumerics
+	{0x24EB, 0x254B}, // Box drawing
+	{0x2550, 0x2573},
+	{0x2580, 0x258F}, // Block elements
+	{0x2592, 0x2595},
+	{0x25A0, 0x25A1}, // Geometric shapes
+	{0x25A3, 0x25A9},
+	{0x25B2, 0x25B3},
+	{0x25B6, 0x25B7},
+	{0x25BC, 0x25BD},
+	{0x25C0, 0x25C1},
+	{0x25C6, 0x25C8},
+	{0x25CB, 0x25CB},
+	{0x25CE, 0x25D1},
+	{0x25E2, 0x25E5},
+	{0x25EF, 0x25EF},
+	{0x2605, 0x2606},
+	{0x2609, 0x2609},
+	{0x260E, 0x260F},
+	{0x2640, 0x2640},
+	{0x2642, 0x2642},
+	{0x2660, 0x2661},
+	{0x2663, 0x2665},
+	{0x2667, 0x266A},
+	{0x266C, 0x266D},
+	{0x266F, 0x266F},
+	{0x2776, 0x277F},
+	{0xE000, 0xF8FF}, // Private use area
+	{0xFFFD, 0xFFFD},
+}
+
+// inRanges returns true if r is in one of the sorted ranges.
+func inRanges(ranges []runeRange, r rune) bool {
+	i := sort.Search(len(ranges), func(i int) bool {
+		return ranges[i].hi >= r
+	})
+	return i < len(ranges) && ranges[i].lo <= r
+}
+
+// runeWidth returns the number of columns used to display r. Wide and
+// fullwidth runes use two columns and combining marks use none, like the
+// column_width function of docutils. Runes of ambiguous width use two columns
+// if wideAmbiguous is true, and one column otherwise.
+func runeWidth(r rune, wideAmbiguous bool) int {
+	switch {
+	case r < 0xA1:
+		return 1
+	case unicode.In(r, unicode.Mn, unicode.Me):
+		return 0
+	case inRanges(wideRunes, r):
+		return 2
+	case wideAmbiguous && inRanges(ambiguousRunes, r):
+		return 2
+	}
+	return 1
+}
+
+// textWidth returns the number of columns used to display text, the sum of the
+// widths of its runes as returned by runeWidth.
+func textWidth(text string, wideAmbiguous bool) (width int) {
+	for _, r := range text {
+		width += runeWidth(r, wideAmbiguous)
+	}
+	return
+}
+
+// titleWidth returns the display width of the text of the title item i. The
+// runes of ambiguous width are counted as wide if Options.EastAsianWidth is
+// set.
+func (t *Tree) titleWidth(i *item) int {
+	return textWidth(i.Text, t.Options.EastAsianWidth)
+}
@@ -0,0 +1,83 @@
+// go-rst - A reStructuredText parser for Go
+// 2014 (c) The go-rst Authors
+// MIT Licensed. See LICENSE for details.
+
+package parse
+
+import "testing"
+
+var textWidthTests = []struct {
+	name          string
+	text          string
+	wideAmbiguous bool
+	width         int
+}{
+	{name: "ASCII", text: "Title", width: 5},
+	{name: "Latin accents", text: "Café", width: 4},
+	{name: "Combining mark", text: "Cafe\u0301", width: 4},
+	{name: "CJK ideographs", text: "世界", width: 4},
+	{name: "Fullwidth letters", text: "ＡＢＣ", width: 6},
+	{name: "Hangul", text: "한국어 문서", width: 11},
+	{name: "Ambiguous as narrow", text: "αβγ", width: 3},
+	{name: "Ambiguous as wide", text: "αβγ", wideAmbiguous: true, width: 6},
+	{name: "Mixed", text: "Go 语言", width: 7},
+}
+
+func TestTextWidth(t *testing.T) {
+	for _, tt := range textWidthTests {
+		if w := textWidth(tt.text, tt.wideAmbiguous); w != tt.width {
+			t.Errorf("Test: %q\n\t    Got: textWidth(%q) = %d, "+
+				"Expect: %d\n\n", tt.name, tt.text, w, tt.width)
+		}
+	}
+}
+
+var eastAsianTitleTests = []struct {
+	name           string
+	input          string
+	eastAsianWidth bool
+	message        parserMessage // Expected message, if any
+}{
+	{
+		name:  "Underline sized for the display width",
+		input: "文書の題名\n==========\n\nText.",
+	},
+	{
+		name:    "Underline sized for the rune count",
+		input:   "文書の題名\n=====\n\nText.",
+		message: warningShortUnderline,
+	},
+	{
+		name:  "Overline sized for the display width",
+		input: "============\n 文書の題名\n============\n\nText.",
+	},
+	{
+		name:    "Overline sized for the rune count",
+		input:   "=======\n 文書の題名\n=======\n\nText.",
+		message: warningShortOverline,
+	},
+	{
+		name:  "Ambiguous width as narrow",
+		input: "αβγδε\n=====\n\nText.",
+	},
+	{
+		name:           "Ambiguous width as wide",
+		input:          "αβγδε\n==========\n\nText.",
+		eastAsianWidth: true,
+	},
+}
+
+func TestParseSectionEastAsianWidth(t *testing.T) {
+	for _, tt := range eastAsianTitleTests {
+		tr, _ := ParseWithOptions(tt.name, tt.input,
+			&ParseOptions{EastAsianWidth: tt.eastAsianWidth})
+		var msg parserMessage
+		if len(tr.Messages) > 0 {
+			msg = tr.Messages[0].(*SystemMessageNode).MessageType
+		}
+		if msg != tt.message {
+			t.Errorf("Test: %q\n\t    Got: message = %s, Expect: %s\n\n",
+				tt.name, msg, tt.message)
+		}
+	}
+}